@@ -32,9 +32,15 @@ func clientFlags() []cli.Flag {
 		&cli.StringFlag{Name: consts.IDLPath, Usage: "Specify the IDL file path. (.thrift or .proto)", Destination: &globalArgs.ClientArgument.IdlPath},
 		&cli.StringFlag{Name: consts.Template, Usage: "Specify the template path. Currently cwgo supports git templates, such as `--template https://github.com/***/cwgo_template.git`", Destination: &globalArgs.ClientArgument.Template},
 		&cli.StringFlag{Name: consts.Branch, Usage: "Specify the git template's branch, default is main branch.", Destination: &globalArgs.ClientArgument.Branch},
-		&cli.StringFlag{Name: consts.Registry, Usage: "Specify the registry, default is None"},
+		&cli.StringFlag{Name: consts.TemplateOverlay, Usage: "Specify a directory of templates replacing or adding single files of the embedded ones, such as kitex/server/standard/main_tpl.yaml. Default is the .cwgo/templates found from the current path up to the project root (the dir of go.mod or .git).", Destination: &globalArgs.ClientArgument.TemplateOverlay},
+		&cli.StringFlag{Name: consts.Registry, Usage: "Specify the registry (ZK, NACOS, ETCD, POLARIS, CONSUL, EUREKA or KUBERNETES), default is None."},
+		&cli.StringSliceFlag{Name: consts.Observability, Usage: "Specify the observability integrations (OTEL) wired into the generated client, can be repeated."},
+		&cli.StringSliceFlag{Name: consts.TplVar, Usage: "Set a `key=value` variable read by the templates with TplVar, can be repeated. Defaults come from template_vars of .cwgo.yaml."},
 		&cli.StringSliceFlag{Name: consts.ProtoSearchPath, Aliases: []string{"I"}, Usage: "Add an IDL search path for includes. (Valid only if idl is protobuf)"},
 		&cli.StringSliceFlag{Name: consts.Pass, Usage: "pass param to hz or kitex"},
 		&cli.BoolFlag{Name: consts.Verbose, Usage: "Turn on verbose mode."},
+		dryRunFlag(),
+		lockedFlag(),
+		watchFlag(),
 	}
 }
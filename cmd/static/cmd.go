@@ -21,10 +21,15 @@ import (
 	"github.com/cloudwego/cwgo/meta"
 	"github.com/cloudwego/cwgo/pkg/api_list"
 	"github.com/cloudwego/cwgo/pkg/client"
+	"github.com/cloudwego/cwgo/pkg/common/errs"
 	"github.com/cloudwego/cwgo/pkg/consts"
 	"github.com/cloudwego/cwgo/pkg/curd/doc"
+	"github.com/cloudwego/cwgo/pkg/deploy"
+	"github.com/cloudwego/cwgo/pkg/doctor"
 	"github.com/cloudwego/cwgo/pkg/fallback"
+	"github.com/cloudwego/cwgo/pkg/gen"
 	"github.com/cloudwego/cwgo/pkg/job"
+	"github.com/cloudwego/cwgo/pkg/middleware"
 	"github.com/cloudwego/cwgo/pkg/model"
 	"github.com/cloudwego/cwgo/pkg/server"
 	"github.com/urfave/cli/v2"
@@ -39,13 +44,19 @@ func Init() *cli.App {
 	app.Name = meta.Name
 	app.Usage = AppUsage
 	app.Version = meta.Version
+	app.Description = ExitCodeUsage
+	// errors are mapped to exit codes by ExitCode, instead of exiting in the middle of a command
+	app.ExitErrHandler = func(*cli.Context, error) {}
+	app.OnUsageError = usageError
 	// The default separator for multiple parameters is modified to ";"
 	app.SliceFlagSeparator = consts.Comma
 
 	// global flags
 	app.Flags = []cli.Flag{
 		&verboseFlag,
+		&cli.BoolFlag{Name: consts.Interactive, Aliases: []string{"i"}, Usage: "walk through the generation of a server, client, model, doc or job"},
 	}
+	app.Action = rootAction
 
 	// Commands
 	app.Commands = []*cli.Command{
@@ -56,10 +67,12 @@ func Init() *cli.App {
 			Action: func(c *cli.Context) error {
 				err := globalArgs.ServerArgument.ParseCli(c)
 				if err != nil {
-					return err
+					return errs.Wrap(errs.InvalidArgs, err)
 				}
 
-				return server.Server(globalArgs.ServerArgument)
+				return generate(c, globalArgs.ServerArgument, func() error {
+					return server.Server(globalArgs.ServerArgument.Clone())
+				})
 			},
 		},
 		{
@@ -69,9 +82,11 @@ func Init() *cli.App {
 			Action: func(c *cli.Context) error {
 				err := globalArgs.ClientArgument.ParseCli(c)
 				if err != nil {
-					return err
+					return errs.Wrap(errs.InvalidArgs, err)
 				}
-				return client.Client(globalArgs.ClientArgument)
+				return generate(c, globalArgs.ClientArgument, func() error {
+					return client.Client(globalArgs.ClientArgument.Clone())
+				})
 			},
 		},
 		{
@@ -80,9 +95,11 @@ func Init() *cli.App {
 			Flags: modelFlags(),
 			Action: func(c *cli.Context) error {
 				if err := globalArgs.ModelArgument.ParseCli(c); err != nil {
-					return err
+					return errs.Wrap(errs.InvalidArgs, err)
 				}
-				return model.Model(globalArgs.ModelArgument)
+				return generate(c, globalArgs.ModelArgument, func() error {
+					return model.Model(globalArgs.ModelArgument.Clone())
+				})
 			},
 		},
 		{
@@ -91,9 +108,11 @@ func Init() *cli.App {
 			Flags: docFlags(),
 			Action: func(c *cli.Context) error {
 				if err := globalArgs.DocArgument.ParseCli(c); err != nil {
-					return err
+					return errs.Wrap(errs.InvalidArgs, err)
 				}
-				return doc.Doc(globalArgs.DocArgument)
+				return generate(c, globalArgs.DocArgument, func() error {
+					return doc.Doc(globalArgs.DocArgument.Clone())
+				})
 			},
 		},
 		{
@@ -102,9 +121,91 @@ func Init() *cli.App {
 			Flags: jobFlags(),
 			Action: func(c *cli.Context) error {
 				if err := globalArgs.JobArgument.ParseCli(c); err != nil {
-					return err
+					return errs.Wrap(errs.InvalidArgs, err)
 				}
-				return job.Job(globalArgs.JobArgument)
+				return generate(c, globalArgs.JobArgument, func() error {
+					return job.Job(globalArgs.JobArgument.Clone())
+				})
+			},
+		},
+		{
+			Name:  GenName,
+			Usage: GenUsage,
+			Flags: genFlags(),
+			Action: func(c *cli.Context) error {
+				if err := globalArgs.GenArgument.ParseCli(c); err != nil {
+					return errs.Wrap(errs.InvalidArgs, err)
+				}
+				// every entry of the manifest is checked against and recorded in the lock file of its output dir
+				globalArgs.GenArgument.WrapTask = func(args interface{}, fn func() error) (func() error, error) {
+					return withLock(c, args, fn)
+				}
+				return generate(c, globalArgs.GenArgument, func() error {
+					return gen.Gen(globalArgs.GenArgument)
+				})
+			},
+		},
+		{
+			Name:  DoctorName,
+			Usage: DoctorUsage,
+			Flags: doctorFlags(),
+			Action: func(c *cli.Context) error {
+				if err := globalArgs.DoctorArgument.ParseCli(c); err != nil {
+					return errs.Wrap(errs.InvalidArgs, err)
+				}
+				return doctor.Doctor(globalArgs.DoctorArgument, c.App.Writer)
+			},
+		},
+		{
+			Name:  DeployName,
+			Usage: DeployUsage,
+			Flags: deployFlags(),
+			Action: func(c *cli.Context) error {
+				if err := globalArgs.DeployArgument.ParseCli(c); err != nil {
+					return errs.Wrap(errs.InvalidArgs, err)
+				}
+				return deploy.Deploy(globalArgs.DeployArgument)
+			},
+		},
+		{
+			Name:  MiddlewareName,
+			Usage: MiddlewareUsage,
+			Flags: middlewareFlags(),
+			Action: func(c *cli.Context) error {
+				if err := globalArgs.MiddlewareArgument.ParseCli(c); err != nil {
+					return errs.Wrap(errs.InvalidArgs, err)
+				}
+				return middleware.Middleware(globalArgs.MiddlewareArgument)
+			},
+		},
+		{
+			Name:  ConfigName,
+			Usage: ConfigUsage,
+			Subcommands: []*cli.Command{
+				{
+					Name:   ConfigShowName,
+					Usage:  ConfigShowUsage,
+					Action: showConfig,
+				},
+			},
+		},
+		{
+			Name:  TemplateName,
+			Usage: TemplateUsage,
+			Subcommands: []*cli.Command{
+				{
+					Name:   TemplateExportName,
+					Usage:  TemplateExportUsage,
+					Flags:  templateExportFlags(),
+					Action: exportTemplates,
+				},
+				{
+					Name:      TemplateValidateName,
+					Usage:     TemplateValidateUsage,
+					ArgsUsage: "<dir|git-url>",
+					Flags:     templateValidateFlags(),
+					Action:    validateTemplates,
+				},
 			},
 		},
 		{
@@ -113,7 +214,7 @@ func Init() *cli.App {
 			Flags: apiFlags(),
 			Action: func(c *cli.Context) error {
 				if err := globalArgs.ApiArgument.ParseCli(c); err != nil {
-					return err
+					return errs.Wrap(errs.InvalidArgs, err)
 				}
 				return api_list.Api(globalArgs.ApiArgument)
 			},
@@ -123,7 +224,7 @@ func Init() *cli.App {
 			Usage: FallbackUsage,
 			Action: func(c *cli.Context) error {
 				if err := globalArgs.FallbackArgument.ParseCli(c); err != nil {
-					return err
+					return errs.Wrap(errs.InvalidArgs, err)
 				}
 				return fallback.Fallback(globalArgs.FallbackArgument)
 			},
@@ -159,6 +260,12 @@ func Init() *cli.App {
 			},
 		},
 	}
+	for _, cmd := range app.Commands {
+		cmd.OnUsageError = usageError
+		if len(cmd.Flags) > 0 {
+			cmd.Before = applyLayers
+		}
+	}
 	return app
 }
 
@@ -203,6 +310,116 @@ Examples:
   cwgo doc --name mongodb --idl {{path/to/IDL_file.thrift}}
 `
 
+	DoctorName  = "doctor"
+	DoctorUsage = `check the environment the generators rely on
+
+Checks go, GOPATH, go.mod, thriftgo, protoc, protoc-gen-go and the template dirs, and prints fix hints.
+
+Examples:
+  cwgo doctor
+
+  # Check the module name that will be given to the generators
+  cwgo doctor --module github.com/cloudwego/biz-demo
+
+  # Print the report as JSON
+  cwgo doctor --json
+`
+
+	DeployName  = "deploy"
+	DeployUsage = `generate the deployment artifacts of a generated project
+
+The service name, ports, GO_ENV and registry settings are read from conf/<env>/conf.yaml, which is
+shipped in a ConfigMap. A kitex server with a hex trans handler exposes its port for both RPC and HTTP.
+The liveness and readiness probes request /healthz and /readyz served by the generated services.
+
+Examples:
+  # Generate a multi-stage Dockerfile
+  cwgo deploy
+
+  # Generate the Dockerfile, Kubernetes manifests and a Helm chart deploying conf/online/conf.yaml
+  cwgo deploy --type DOCKER --type K8S --type HELM --image registry.example.com/demo:v1
+`
+
+	MiddlewareName  = "middleware"
+	MiddlewareUsage = `generate a middleware in biz/middleware and register it
+
+RPC middlewares are registered to the options of kitexInit in main.go (server scope) or to
+defaultClientOpts of the clients generated under rpc/ (client scope). HTTP middlewares are used
+by registerMiddleware in main.go (server scope) or returned by a route group middleware function
+generated by hz in biz/router (route-group scope). A registered middleware is not registered again.
+
+Examples:
+  # Log the calls served by a kitex server
+  cwgo middleware --name access_log
+
+  # Add auth to the clients of the user service
+  cwgo middleware --name auth --scope client --service user
+
+  # Check the token of the routes of the /user group
+  cwgo middleware --name token --type HTTP --scope route-group --group _user
+`
+
+	ConfigName  = "config"
+	ConfigUsage = `inspect the default flag values
+
+Flags not given on the command line are read from CWGO_* environment variables, then from the
+.cwgo.yaml file found in the current directory or its parents, then from the built-in defaults.
+Environment variables are CWGO_<FLAG> and CWGO_<COMMAND>_<FLAG>, e.g. CWGO_DSN or CWGO_SERVER_IDL.
+
+.cwgo.yaml example:
+  module: github.com/cloudwego/biz-demo
+  registry: NACOS
+  server:
+    idl: idl/user.thrift
+  model:
+    db_type: mysql
+`
+	ConfigShowName  = "show"
+	ConfigShowUsage = `print the effective flag values and where each came from
+
+Examples:
+  cwgo config show
+
+  # Only the flags of the server and model commands
+  cwgo config show server model
+`
+
+	TemplateName  = "template"
+	TemplateUsage = `manage the templates of the generated code
+
+The embedded templates can be customized file by file in an overlay directory mirroring them, such as
+.cwgo/templates/kitex/server/standard/main_tpl.yaml. An overlay file replaces the embedded one of the
+same path and any other file is added to the set. The files listed in hertz layout.yaml and package.yaml
+are merged by path, only the listed ones are replaced or added. The .cwgo/templates found from the
+current path up to the project root is applied by cwgo server and cwgo client, --template_overlay
+points to another one. The overlay only applies to the generation it is given to.
+`
+	TemplateExportName  = "export"
+	TemplateExportUsage = `write the embedded templates as a starting point of an overlay or a template
+
+Existing files are kept. The paths given as arguments limit the export to the templates under them.
+
+Examples:
+  # Export all the templates into the current path
+  cwgo template export
+
+  # Start an overlay replacing the main.go of kitex servers
+  cwgo template export --out_dir .cwgo/templates kitex/server/standard/main_tpl.yaml
+`
+	TemplateValidateName  = "validate"
+	TemplateValidateUsage = `check the kitex and hertz templates of a directory or a git repository
+
+Each yaml template is parsed, its unknown fields and unsupported update behaviors are reported.
+The path, body and update templates are compiled with the functions of kitex, hz and cwgo, and the
+fields they reference are checked against the data they are rendered with. The [[ ]] directives of
+the hertz templates are checked against the registry data. The problems are printed as file:line.
+
+Examples:
+  cwgo template validate ./my_template
+
+  cwgo template validate https://github.com/***/cwgo_template.git --branch dev
+`
+
 	ApiListName = "api-list"
 	ApiUsage    = `analyze router codes by golang ast
 
@@ -215,6 +432,36 @@ Examples:
 Examples:
 	cwgo job --job_name jobOne --job_name jobTwo --module my_job
 `
+	GenName  = "gen"
+	GenUsage = `regenerate every component described in the manifest file
+
+Components are generated in dependency order: models, docs, servers, clients and jobs.
+
+Examples:
+  cwgo gen --file cwgo.yaml
+
+  # Print what would be regenerated without touching the repo
+  cwgo gen --dry-run
+
+Manifest example:
+  module: github.com/cloudwego/biz-demo
+  models:
+    - db_type: mysql
+      dsn: "gorm:gorm@tcp(localhost:9910)/gorm?charset=utf8mb4&parseTime=True&loc=Local"
+  services:
+    - dir: app/user
+      server_name: user
+      type: RPC
+      idl: idl/user.thrift
+  clients:
+    - dir: app/gateway
+      server_name: user
+      idl: idl/user.thrift
+  jobs:
+    - dir: app/job
+      job_name: [clean]
+`
+
 	FallbackName  = "fallback"
 	FallbackUsage = "fallback to hz or kitex"
 
@@ -0,0 +1,30 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package static
+
+import (
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/urfave/cli/v2"
+)
+
+func deployFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringSliceFlag{Name: consts.ServiceType, Usage: "Specify the artifacts to generate (DOCKER, K8S or HELM), can be repeated.", Value: cli.NewStringSlice(consts.Docker)},
+		&cli.StringFlag{Name: consts.Env, Usage: "Specify the GO_ENV the service runs with, its conf/<env>/conf.yaml is deployed.", Value: "online"},
+		&cli.StringFlag{Name: consts.Image, Usage: "Specify the image of the service, it is the service name when empty."},
+		&cli.StringFlag{Name: consts.OutDir, Usage: "Specify the project path, it is the current path when empty."},
+	}
+}
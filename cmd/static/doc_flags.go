@@ -34,5 +34,9 @@ func docFlags() []cli.Flag {
 		&cli.StringSliceFlag{Name: consts.Protoc, Aliases: []string{"p"}, Usage: "Specify arguments for the protoc. ({flag}={value})"},
 		&cli.BoolFlag{Name: consts.Verbose, Usage: "Turn on verbose mode, default is false."},
 		&cli.BoolFlag{Name: consts.GenBase, Usage: "Generate base mongo code, default is false."},
+		&cli.StringSliceFlag{Name: consts.TplVar, Usage: "Set a `key=value` variable read by the templates with TplVar, can be repeated. Defaults come from template_vars of .cwgo.yaml."},
+		dryRunFlag(),
+		lockedFlag(),
+		watchFlag(),
 	}
 }
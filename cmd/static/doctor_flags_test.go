@@ -0,0 +1,51 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package static
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/cloudwego/cwgo/pkg/doctor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDoctorCommand(t *testing.T) {
+	dir := t.TempDir()
+	cwd, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.NoError(t, os.Chdir(dir))
+	defer os.Chdir(cwd)
+	// no tool is found, the report is printed all the same
+	t.Setenv("PATH", dir)
+	t.Setenv("GOPATH", dir)
+
+	var out bytes.Buffer
+	app := Init()
+	app.Writer, app.ErrWriter = &out, io.Discard
+	err = app.Run([]string{"cwgo", "doctor", "--json", "--module", "example.com/demo"})
+	assert.Error(t, err)
+
+	r := new(doctor.Report)
+	assert.NoError(t, json.Unmarshal(out.Bytes(), r), out.String())
+	assert.Equal(t, "go", r.Checks[0].Name)
+	assert.Equal(t, doctor.Fail, r.Checks[0].Status)
+	assert.Positive(t, r.Failed())
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package static
+
+import (
+	"github.com/cloudwego/cwgo/pkg/common/dryrun"
+	"github.com/cloudwego/cwgo/pkg/common/errs"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/urfave/cli/v2"
+)
+
+func dryRunFlag() cli.Flag {
+	return &cli.BoolFlag{Name: consts.DryRun, Aliases: []string{"dry-run"}, Usage: "Print the unified diff of the files that would be generated without writing them."}
+}
+
+type pathResolver interface {
+	ResolvePaths() error
+}
+
+// sandboxer is implemented by the arguments with output paths, which have to stay in the dry run sandbox.
+type sandboxer interface {
+	Sandbox() error
+}
+
+// generate runs the generator, in dry run mode it only prints what the generator would change.
+func generate(c *cli.Context, args interface{}, fn func() error) error {
+	if c.Bool(consts.Watch) {
+		return watchGenerate(c, args, fn)
+	}
+	return generateOnce(c, args, fn)
+}
+
+func generateOnce(c *cli.Context, args interface{}, fn func() error) error {
+	dryRun := c.Bool(consts.DryRun)
+	if r, ok := args.(pathResolver); ok && dryRun {
+		if err := r.ResolvePaths(); err != nil {
+			return errs.Wrap(errs.InvalidArgs, err)
+		}
+	}
+	if s, ok := args.(sandboxer); ok && dryRun {
+		if err := s.Sandbox(); err != nil {
+			return errs.Wrap(errs.InvalidArgs, err)
+		}
+	}
+	fn, err := withLock(c, args, fn)
+	if err != nil {
+		return err
+	}
+	if !dryRun {
+		return fn()
+	}
+	report, err := dryrun.Run(fn)
+	if err != nil {
+		return err
+	}
+	return report.Print(c.App.Writer)
+}
@@ -0,0 +1,73 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package static
+
+import (
+	"fmt"
+
+	"github.com/cloudwego/cwgo/pkg/common/errs"
+	"github.com/urfave/cli/v2"
+)
+
+// Exit codes of cwgo, they are stable across releases.
+const (
+	ExitOK          = 0
+	ExitFailure     = 1
+	ExitInvalidArgs = 2
+	ExitMissingTool = 3
+	ExitIDLParse    = 4
+	ExitConflict    = 5
+	ExitPostProcess = 6
+	ExitInterrupted = 130
+)
+
+const ExitCodeUsage = `Exit codes:
+  0    success
+  1    unclassified failure
+  2    invalid flags, arguments or manifest
+  3    missing tool, e.g. go, git, thriftgo or protoc
+  4    the IDL could not be parsed or compiled
+  5    the generation conflicts with the existing project, e.g. another module name in go.mod
+  6    the code was generated but a following step failed, e.g. persisting the hz manifest
+  130  interrupted, e.g. by Ctrl+C`
+
+// ExitCode maps the error returned by a command to its exit code.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	switch errs.KindOf(err) {
+	case errs.InvalidArgs:
+		return ExitInvalidArgs
+	case errs.MissingTool:
+		return ExitMissingTool
+	case errs.IDLParse:
+		return ExitIDLParse
+	case errs.Conflict:
+		return ExitConflict
+	case errs.PostProcess:
+		return ExitPostProcess
+	default:
+		return ExitFailure
+	}
+}
+
+// usageError marks the flag parsing errors as invalid arguments.
+func usageError(c *cli.Context, err error, _ bool) error {
+	_, _ = fmt.Fprintf(c.App.Writer, "Incorrect Usage: %s\n\n", err)
+	return errs.Wrap(errs.InvalidArgs, err)
+}
@@ -0,0 +1,125 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package static
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudwego/cwgo/pkg/common/errs"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExitCode(t *testing.T) {
+	assert.Equal(t, ExitOK, ExitCode(nil))
+	assert.Equal(t, ExitFailure, ExitCode(errors.New("boom")))
+	for kind, code := range map[errs.Kind]int{
+		errs.InvalidArgs: ExitInvalidArgs,
+		errs.MissingTool: ExitMissingTool,
+		errs.IDLParse:    ExitIDLParse,
+		errs.Conflict:    ExitConflict,
+		errs.PostProcess: ExitPostProcess,
+	} {
+		err := fmt.Errorf("generate failed: %w", errs.New(kind, "boom"))
+		assert.Equal(t, code, ExitCode(err), kind.String())
+	}
+}
+
+func TestCommandExitCode(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/demo\n"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "user.proto"), []byte("syntax = \"proto3\";\n"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, ".hz"), []byte("{{"), 0o644))
+	cwd, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.NoError(t, os.Chdir(dir))
+	defer os.Chdir(cwd)
+	// keep the generators away from the tools of the host
+	t.Setenv("PATH", dir)
+	t.Setenv("GOPATH", dir)
+
+	cases := []struct {
+		args []string
+		code int
+	}{
+		{[]string{"server", "--type", "RPC"}, ExitInvalidArgs},
+		{[]string{"server", "--unknown"}, ExitInvalidArgs},
+		{[]string{"server", "--type", "RPC", "--idl", "user.proto", "--idl", "order.proto", "--service", "user"}, ExitInvalidArgs},
+		{[]string{"server", "--type", "HTTP", "--idl", "user.proto", "--service", "user", "--module", "example.com/demo"}, ExitConflict},
+		{[]string{"client", "--type", "GRPC", "--service", "user"}, ExitInvalidArgs},
+		{[]string{"model", "--db_type", "oracle", "--dsn", "dsn"}, ExitInvalidArgs},
+		{[]string{"doc", "--name", "redis", "--idl", "user.proto"}, ExitInvalidArgs},
+		{[]string{"doc", "--idl", "user.proto", "--module", "example.com/demo"}, ExitMissingTool},
+		{[]string{"job"}, ExitInvalidArgs},
+		{[]string{"job", "--job_name", "email", "--module", "example.com/other"}, ExitConflict},
+		{[]string{"gen", "--file", "missing.yaml"}, ExitInvalidArgs},
+		{[]string{"fallback", "unknown"}, ExitInvalidArgs},
+	}
+	for _, c := range cases {
+		app := Init()
+		app.Writer, app.ErrWriter = io.Discard, io.Discard
+		err := app.Run(append([]string{"cwgo"}, c.args...))
+		assert.Equal(t, c.code, ExitCode(err), "%v: %v", c.args, err)
+	}
+}
+
+func TestCommandExitCodeGeneration(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/demo\n"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "broken.thrift"), []byte("service User {"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "governed.proto"), []byte(`syntax = "proto3";
+package user;
+option go_package = "user";
+message Req {}
+service User {
+	rpc Get(Req) returns (Req) {
+		option (rpc.timeout) = "200ms";
+	}
+}
+`), 0o644))
+	// the lock file points into a missing dir, it reads as empty but can't be written
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "jobs"), 0o755))
+	if err := os.Symlink(filepath.Join(dir, "missing", consts.LockFile), filepath.Join(dir, "jobs", consts.LockFile)); err != nil {
+		t.Skipf("symlink is not supported: %s", err)
+	}
+	cwd, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.NoError(t, os.Chdir(dir))
+	defer os.Chdir(cwd)
+	t.Setenv("PATH", dir)
+	t.Setenv("GOPATH", dir)
+
+	cases := []struct {
+		args []string
+		code int
+	}{
+		{[]string{"client", "--type", "RPC", "--idl", "broken.thrift", "--service", "user", "--module", "example.com/demo"}, ExitIDLParse},
+		{[]string{"client", "--type", "RPC", "--idl", "governed.proto", "--service", "user", "--module", "example.com/demo"}, ExitInvalidArgs},
+		{[]string{"job", "--job_name", "email", "--module", "example.com/demo", "--out_dir", "jobs"}, ExitPostProcess},
+	}
+	for _, c := range cases {
+		app := Init()
+		app.Writer, app.ErrWriter = io.Discard, io.Discard
+		err := app.Run(append([]string{"cwgo"}, c.args...))
+		assert.Equal(t, c.code, ExitCode(err), "%v: %v", c.args, err)
+	}
+}
@@ -0,0 +1,31 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package static
+
+import (
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/urfave/cli/v2"
+)
+
+func genFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{Name: consts.File, Aliases: []string{"f"}, Usage: "Specify the manifest file.", Value: consts.ManifestFile, DefaultText: consts.ManifestFile},
+		&cli.BoolFlag{Name: consts.Verbose, Usage: "Turn on verbose mode."},
+		dryRunFlag(),
+		lockedFlag(),
+	}
+}
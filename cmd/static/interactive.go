@@ -0,0 +1,64 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package static
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/cwgo/pkg/common/errs"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/cloudwego/cwgo/pkg/wizard"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/term"
+)
+
+// rootAction runs the wizard for 'cwgo --interactive', and for 'cwgo' alone in a terminal.
+func rootAction(c *cli.Context) error {
+	if c.NArg() > 0 {
+		return errs.New(errs.InvalidArgs, "unknown command %s", c.Args().First())
+	}
+	if !c.Bool(consts.Interactive) && !isTerminal(os.Stdin) {
+		return cli.ShowAppHelp(c)
+	}
+
+	r, err := wizard.Run()
+	if err != nil {
+		return err
+	}
+	args := append([]string{c.App.Name}, r.Args...)
+	line := wizard.CommandLine(args)
+	if r.DSN != "" {
+		// keep the DSN out of the shell history
+		line = fmt.Sprintf("%s=<dsn> %s", config.EnvName("", consts.DSN), line)
+	}
+	fmt.Fprintf(c.App.Writer, "\nEquivalent command:\n  %s\n\n", line)
+	if !r.Generate {
+		return nil
+	}
+	if r.DSN != "" {
+		if err = os.Setenv(config.EnvName("", consts.DSN), r.DSN); err != nil {
+			return err
+		}
+	}
+	return Init().Run(args)
+}
+
+func isTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
@@ -26,5 +26,8 @@ func jobFlags() []cli.Flag {
 		&cli.StringSliceFlag{Name: consts.JobName, Usage: "Specify the job name."},
 		&cli.StringFlag{Name: consts.Module, Aliases: []string{"mod"}, Usage: "Specify the Go module name to generate go.mod."},
 		&cli.StringFlag{Name: consts.OutDir, Usage: "Specify output directory, default is current dir."},
+		&cli.StringSliceFlag{Name: consts.TplVar, Usage: "Set a `key=value` variable read by the templates with TplVar, can be repeated. Defaults come from template_vars of .cwgo.yaml."},
+		dryRunFlag(),
+		lockedFlag(),
 	}
 }
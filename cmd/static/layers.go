@@ -0,0 +1,131 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package static
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/cwgo/pkg/common/errs"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/cloudwego/hertz/cmd/hz/util/logs"
+	"github.com/urfave/cli/v2"
+)
+
+const masked = "******"
+
+func loadLayers() (*config.Layers, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("get current path failed: %s", err)
+	}
+	return config.LoadLayers(cwd)
+}
+
+// applyLayers fills the flags missing on the command line from the CWGO_* environment variables and .cwgo.yaml.
+func applyLayers(c *cli.Context) error {
+	l, err := loadLayers()
+	if err != nil {
+		return err
+	}
+	for _, f := range c.Command.Flags {
+		name := f.Names()[0]
+		// the template variables are merged by key, the ones on the command line win
+		if name == cli.HelpFlag.Names()[0] || (c.IsSet(name) && name != consts.TplVar) {
+			continue
+		}
+		values, source, ok := l.Lookup(c.Command.Name, name)
+		if !ok {
+			continue
+		}
+		if name == consts.TplVar && c.IsSet(name) {
+			values = config.FilterTemplateVars(values, c.StringSlice(name))
+		}
+		if name == consts.DSN && source == l.File {
+			logs.Warnf("dsn is read from %s, prefer the %s environment variable to keep credentials out of the repo", l.File, config.EnvName("", consts.DSN))
+		}
+		for _, v := range values {
+			if err = c.Set(name, v); err != nil {
+				return errs.New(errs.InvalidArgs, "invalid %s from %s: %s", name, source, err)
+			}
+		}
+	}
+	return nil
+}
+
+// showConfig prints the effective value of every flag of the given commands and where it comes from.
+func showConfig(c *cli.Context) error {
+	l, err := loadLayers()
+	if err != nil {
+		return err
+	}
+
+	var commands []*cli.Command
+	for _, name := range c.Args().Slice() {
+		cmd := c.App.Command(name)
+		if cmd == nil || cmd.Before == nil {
+			return errs.New(errs.InvalidArgs, "command %s has no configurable flags", name)
+		}
+		commands = append(commands, cmd)
+	}
+	if len(commands) == 0 {
+		for _, cmd := range c.App.Commands {
+			if cmd.Before != nil {
+				commands = append(commands, cmd)
+			}
+		}
+	}
+
+	file := l.File
+	if file == "" {
+		file = consts.DefaultsFile + " not found"
+	}
+	w := tabwriter.NewWriter(c.App.Writer, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "config file: %s\n", file)
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "\n%s\n", cmd.Name)
+		for _, f := range cmd.Flags {
+			name := f.Names()[0]
+			if name == cli.HelpFlag.Names()[0] {
+				continue
+			}
+			value, source := defaultValue(f), config.SourceDefault
+			if values, src, ok := l.Lookup(cmd.Name, name); ok {
+				value, source = strings.Join(values, ", "), src
+			}
+			if name == consts.DSN && value != "" {
+				value = masked
+			}
+			fmt.Fprintf(w, "  %s\t%s\t%s\n", name, value, source)
+		}
+	}
+	return w.Flush()
+}
+
+func defaultValue(f cli.Flag) string {
+	switch f := f.(type) {
+	case *cli.BoolFlag:
+		return strconv.FormatBool(f.Value)
+	case cli.DocGenerationFlag:
+		return f.GetValue()
+	}
+	return ""
+}
@@ -0,0 +1,192 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package static
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/cwgo/pkg/common/errs"
+	"github.com/cloudwego/cwgo/pkg/common/utils"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/cloudwego/cwgo/pkg/lock"
+	"github.com/cloudwego/cwgo/pkg/watch"
+	"github.com/cloudwego/cwgo/tpl"
+	"github.com/cloudwego/hertz/cmd/hz/util/logs"
+	"github.com/urfave/cli/v2"
+)
+
+func lockedFlag() cli.Flag {
+	return &cli.BoolFlag{Name: consts.Locked, Usage: "Refuse to generate when the versions of cwgo or of the embedded tools differ from the ones recorded in " + consts.LockFile + "."}
+}
+
+// lockTarget describes how a generation is recorded in the lock file.
+type lockTarget struct {
+	entry *lock.Entry
+	// dir is the absolute directory of the lock file.
+	dir         string
+	idl         string
+	searchPaths []string
+	// template is the git template the generation is rendered from, branch is the branch given for it.
+	template string
+	branch   *string
+	repo     string
+}
+
+// withLock checks the generation against the lock file, and records it in the lock file once fn succeeds.
+// A git template without a given branch is not pinned to the locked commit, it is warned about instead.
+func withLock(c *cli.Context, args interface{}, fn func() error) (func() error, error) {
+	t, err := newLockTarget(args)
+	if t == nil || err != nil {
+		return fn, err
+	}
+	l, err := lock.Load(t.dir)
+	if err != nil {
+		return nil, err
+	}
+	if locked := l.Find(t.entry.Command, t.entry.Name); locked != nil {
+		if diffs := t.entry.Diff(locked); len(diffs) > 0 {
+			if c.Bool(consts.Locked) {
+				return nil, errs.New(errs.Conflict, "%s %q is locked to other versions in %s: %s",
+					t.entry.Command, t.entry.Name, filepath.Join(t.dir, consts.LockFile), strings.Join(diffs, ", "))
+			}
+			logs.Warnf("%s %q was generated with other versions, the output may differ: %s",
+				t.entry.Command, t.entry.Name, strings.Join(diffs, ", "))
+		}
+		if t.template != "" && *t.branch == "" && locked.Template == t.entry.Template && locked.TemplateCommit != "" {
+			logs.Warnf("%s %q was generated from commit %s of template %s, the latest commit is used as --%s is not given: pass --%s %s to regenerate from it",
+				t.entry.Command, t.entry.Name, locked.TemplateCommit, t.template, consts.Branch, consts.Branch, locked.TemplateCommit)
+		}
+	}
+
+	// in dry run mode the generation happens in a scratch copy of the working directory,
+	// the lock file is written there as well when it is inside the working directory
+	saveDir := t.dir
+	if cwd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(cwd, t.dir); err == nil && !strings.HasPrefix(rel, "..") {
+			saveDir = rel
+		}
+	}
+	return func() error {
+		if err := fn(); err != nil {
+			return err
+		}
+		if t.idl != "" {
+			files, err := watch.IDLFiles(t.idl, t.searchPaths)
+			if err != nil {
+				logs.Warnf("the checksums of the IDL are not recorded in %s: %s", consts.LockFile, err)
+			}
+			if t.entry.IDL, err = lock.Checksums(t.dir, files); err != nil {
+				return errs.Wrap(errs.PostProcess, err)
+			}
+		}
+		if t.repo != "" {
+			if t.entry.TemplateCommit, err = utils.GitHead(t.repo); err != nil {
+				return errs.New(errs.PostProcess, "get the commit of template %s failed: %s", t.template, err)
+			}
+		}
+		l.Put(t.entry)
+		if err = l.Save(saveDir); err != nil {
+			return errs.New(errs.PostProcess, "write %s failed: %s", consts.LockFile, err)
+		}
+		return nil
+	}, nil
+}
+
+func newLockTarget(args interface{}) (*lockTarget, error) {
+	var (
+		t   = new(lockTarget)
+		err error
+	)
+	switch a := args.(type) {
+	case *config.ServerArgument:
+		if t.dir, err = lockDir(a.OutDir); err != nil {
+			return nil, err
+		}
+		spec := a.Spec(t.dir)
+		t.entry = lock.NewEntry(ServerName, a.ServerName, idlTools(a.Type)...)
+		t.entry.Template, t.entry.Args = spec.Template, spec
+		t.idl, t.searchPaths = a.IdlPath, a.SliceParam.ProtoSearchPath
+		t.setTemplate(a.Template, &a.Branch, a.Type, consts.Server)
+	case *config.ClientArgument:
+		if t.dir, err = lockDir(a.OutDir); err != nil {
+			return nil, err
+		}
+		spec := a.Spec(t.dir)
+		t.entry = lock.NewEntry(ClientName, a.ServerName, idlTools(a.Type)...)
+		t.entry.Template, t.entry.Args = spec.Template, spec
+		t.idl, t.searchPaths = a.IdlPath, a.SliceParam.ProtoSearchPath
+		t.setTemplate(a.Template, &a.Branch, a.Type, consts.Client)
+	case *config.ModelArgument:
+		if t.dir, err = lockDir(""); err != nil {
+			return nil, err
+		}
+		t.entry = lock.NewEntry(ModelName, a.OutPath, lock.GormGen)
+		t.entry.Args = a.Spec(t.dir)
+	case *config.DocArgument:
+		if t.dir, err = lockDir(a.OutDir); err != nil {
+			return nil, err
+		}
+		spec := a.Spec(t.dir)
+		t.entry = lock.NewEntry(DocName, spec.IdlPath, lock.Thriftgo)
+		t.entry.Args = spec
+		t.idl, t.searchPaths = a.IdlPath, a.ProtoSearchPath
+	case *config.JobArgument:
+		if t.dir, err = lockDir(a.OutDir); err != nil {
+			return nil, err
+		}
+		t.entry = lock.NewEntry(JobName, strings.Join(a.JobName, ","))
+		t.entry.Args = a.Spec()
+	default:
+		return nil, nil
+	}
+	return t, nil
+}
+
+// lockDir returns the absolute directory of the lock file, the output dir of the generation.
+func lockDir(outDir string) (string, error) {
+	if outDir == "" {
+		outDir = "."
+	}
+	return filepath.Abs(outDir)
+}
+
+func idlTools(typ string) []string {
+	if strings.EqualFold(typ, consts.HTTP) {
+		return []string{lock.Hz, lock.Thriftgo}
+	}
+	return []string{lock.Kitex, lock.Thriftgo}
+}
+
+// setTemplate locates the clone of a git template, the generators clone it under the template root.
+func (t *lockTarget) setTemplate(template string, branch *string, typ, command string) {
+	if !strings.HasSuffix(template, consts.SuffixGit) {
+		return
+	}
+	repo, err := utils.GitPath(template)
+	if err != nil {
+		return
+	}
+	root := tpl.KitexDir
+	if strings.EqualFold(typ, consts.HTTP) {
+		root = tpl.HertzDir
+	}
+	t.template, t.branch, t.repo = template, branch, path.Join(root, command, repo)
+}
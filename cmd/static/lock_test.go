@@ -0,0 +1,95 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package static
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/cwgo/meta"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocked(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/demo\n"), 0o644))
+	cwd, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.NoError(t, os.Chdir(dir))
+	defer os.Chdir(cwd)
+
+	run := func(args ...string) error {
+		app := Init()
+		app.Writer, app.ErrWriter = io.Discard, io.Discard
+		return app.Run(append([]string{"cwgo", "job", "--job_name", "email", "--module", "example.com/demo"}, args...))
+	}
+	assert.NoError(t, run())
+	data, err := os.ReadFile(consts.LockFile)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "cwgo: "+meta.Version)
+
+	old := strings.Replace(string(data), "cwgo: "+meta.Version, "cwgo: v0.0.1", 1)
+	assert.NoError(t, os.WriteFile(consts.LockFile, []byte(old), 0o644))
+	err = run("--locked")
+	assert.Equal(t, ExitConflict, ExitCode(err), "%v", err)
+	data, _ = os.ReadFile(consts.LockFile)
+	assert.Equal(t, old, string(data))
+
+	// without --locked the regeneration only warns and updates the lock file
+	assert.NoError(t, run())
+	data, _ = os.ReadFile(consts.LockFile)
+	assert.Contains(t, string(data), "cwgo: "+meta.Version)
+}
+
+func TestGenLocked(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/demo\n"), 0o644))
+	manifest := "jobs:\n  - dir: worker\n    module: example.com/demo\n    job_name: [email]\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, consts.ManifestFile), []byte(manifest), 0o644))
+	cwd, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.NoError(t, os.Chdir(dir))
+	defer os.Chdir(cwd)
+
+	run := func(args ...string) error {
+		app := Init()
+		app.Writer, app.ErrWriter = io.Discard, io.Discard
+		return app.Run(append([]string{"cwgo", "gen"}, args...))
+	}
+	// the entry is recorded in the lock file of its own dir
+	assert.NoError(t, run())
+	lockFile := filepath.Join("worker", consts.LockFile)
+	data, err := os.ReadFile(lockFile)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "cwgo: "+meta.Version)
+	assert.Contains(t, string(data), "name: email")
+
+	old := strings.Replace(string(data), "cwgo: "+meta.Version, "cwgo: v0.0.1", 1)
+	assert.NoError(t, os.WriteFile(lockFile, []byte(old), 0o644))
+	err = run("--locked")
+	assert.Equal(t, ExitConflict, ExitCode(err), "%v", err)
+	data, _ = os.ReadFile(lockFile)
+	assert.Equal(t, old, string(data))
+
+	assert.NoError(t, run())
+	data, _ = os.ReadFile(lockFile)
+	assert.Contains(t, string(data), "cwgo: "+meta.Version)
+}
@@ -0,0 +1,32 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package static
+
+import (
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/urfave/cli/v2"
+)
+
+func middlewareFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{Name: consts.Name, Usage: "Specify the middleware name.", Required: true},
+		&cli.StringFlag{Name: consts.ServiceType, Usage: "Specify the middleware type (RPC or HTTP).", Value: consts.RPC},
+		&cli.StringFlag{Name: consts.Scope, Usage: "Specify where the middleware is registered (server, client or route-group).", Value: consts.ServerScope},
+		&cli.StringFlag{Name: consts.Group, Usage: "Specify the route group middleware function of biz/router, e.g. _user or hello/_userMw. Required by route-group scope."},
+		&cli.StringFlag{Name: consts.Service, Usage: "Specify the client under rpc/ the middleware is registered to, all the clients when empty. Used by client scope."},
+		&cli.StringFlag{Name: consts.OutDir, Usage: "Specify the project path, it is the current path when empty."},
+	}
+}
@@ -29,13 +29,20 @@ func serverFlags() []cli.Flag {
 		&cli.StringFlag{Name: consts.ServerName, Usage: "Specify the server name.", Destination: &globalArgs.ServerArgument.ServerName},
 		&cli.StringFlag{Name: consts.ServiceType, Usage: "Specify the generate type. (RPC or HTTP)", Value: consts.RPC},
 		&cli.StringFlag{Name: consts.Module, Aliases: []string{"mod"}, Usage: "Specify the Go module name to generate go.mod.", Destination: &globalArgs.ServerArgument.GoMod},
-		&cli.StringFlag{Name: consts.IDLPath, Usage: "Specify the IDL file path. (.thrift or .proto)", Destination: &globalArgs.ServerArgument.IdlPath},
+		&cli.StringSliceFlag{Name: consts.IDLPath, Usage: "Specify the IDL file path. (.thrift or .proto) The services of the IDL are served together, it can't be repeated."},
 		&cli.StringFlag{Name: consts.Template, Usage: "Specify the template path. Currently cwgo supports git templates, such as `--template https://github.com/***/cwgo_template.git`", Destination: &globalArgs.ServerArgument.Template},
 		&cli.StringFlag{Name: consts.Branch, Usage: "Specify the git template's branch, default is main branch.", Destination: &globalArgs.ServerArgument.Branch},
-		&cli.StringFlag{Name: consts.Registry, Usage: "Specify the registry, default is None."},
+		&cli.StringFlag{Name: consts.TemplateOverlay, Usage: "Specify a directory of templates replacing or adding single files of the embedded ones, such as kitex/server/standard/main_tpl.yaml. Default is the .cwgo/templates found from the current path up to the project root (the dir of go.mod or .git).", Destination: &globalArgs.ServerArgument.TemplateOverlay},
+		&cli.StringFlag{Name: consts.Registry, Usage: "Specify the registry (ZK, NACOS, ETCD, POLARIS, CONSUL, EUREKA or KUBERNETES), default is None."},
+		&cli.StringFlag{Name: consts.ConfigCenter, Usage: "Specify the config center (NACOS, ETCD, APOLLO or FILE) watched by the generated conf package, default is None."},
+		&cli.StringSliceFlag{Name: consts.Observability, Usage: "Specify the observability integrations (OTEL or PROMETHEUS) wired into the generated server, can be repeated."},
+		&cli.StringSliceFlag{Name: consts.TplVar, Usage: "Set a `key=value` variable read by the templates with TplVar, can be repeated. Defaults come from template_vars of .cwgo.yaml."},
 		&cli.StringSliceFlag{Name: consts.ProtoSearchPath, Aliases: []string{"I"}, Usage: "Add an IDL search path for includes."},
 		&cli.StringSliceFlag{Name: consts.Pass, Usage: "Pass param to hz or Kitex."},
 		&cli.BoolFlag{Name: consts.Verbose, Usage: "Turn on verbose mode."},
-		&cli.BoolFlag{Name: consts.HexTag, Usage: "Add HTTP listen for Kitex.", Destination: &globalArgs.Hex},
+		&cli.BoolFlag{Name: consts.HexTag, Usage: "Serve the api.get/api.post routes of the IDL as a JSON gateway to the Kitex handlers on the same port.", Destination: &globalArgs.Hex},
+		dryRunFlag(),
+		lockedFlag(),
+		watchFlag(),
 	}
 }
@@ -0,0 +1,56 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package static
+
+import (
+	"fmt"
+
+	"github.com/cloudwego/cwgo/pkg/common/errs"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/cloudwego/cwgo/pkg/tpl_validate"
+	"github.com/cloudwego/cwgo/tpl"
+	"github.com/urfave/cli/v2"
+)
+
+// exportTemplates writes the embedded templates under the paths given as arguments, all of them if there is none.
+func exportTemplates(c *cli.Context) error {
+	written, err := tpl.Export(c.String(consts.OutDir), c.Args().Slice()...)
+	for _, f := range written {
+		fmt.Fprintln(c.App.Writer, f)
+	}
+	if err != nil {
+		return errs.Wrap(errs.InvalidArgs, err)
+	}
+	return nil
+}
+
+// validateTemplates prints the problems of the templates given as the argument.
+func validateTemplates(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return errs.New(errs.InvalidArgs, "exactly one template directory or git url is required")
+	}
+	problems, err := tpl_validate.Validate(c.Args().First(), c.String(consts.Branch))
+	if err != nil {
+		return errs.Wrap(errs.InvalidArgs, err)
+	}
+	for _, p := range problems {
+		fmt.Fprintln(c.App.Writer, p)
+	}
+	if len(problems) > 0 {
+		return errs.New(errs.InvalidArgs, "%d problems are found in the templates", len(problems))
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package static
+
+import (
+	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/cwgo/pkg/common/errs"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/cloudwego/cwgo/pkg/watch"
+	"github.com/urfave/cli/v2"
+)
+
+func watchFlag() cli.Flag {
+	return &cli.BoolFlag{Name: consts.Watch, Usage: "Regenerate whenever the input files change, until interrupted."}
+}
+
+// watchGenerate runs the generator each time one of the files it is generated from changes.
+// A failed run is reported and watching goes on.
+func watchGenerate(c *cli.Context, args interface{}, fn func() error) error {
+	// the watched paths have to stay valid whatever directory the generator runs in
+	if r, ok := args.(pathResolver); ok {
+		if err := r.ResolvePaths(); err != nil {
+			return errs.Wrap(errs.InvalidArgs, err)
+		}
+	}
+	files, err := watchFiles(args)
+	if err != nil {
+		return err
+	}
+	return watch.New().Watch(c.Context, &watch.Target{
+		Name:  c.Command.Name,
+		Files: files,
+		Run: func() error {
+			return generateOnce(c, args, fn)
+		},
+	})
+}
+
+func watchFiles(args interface{}) (func() ([]string, error), error) {
+	var (
+		idl         string
+		searchPaths []string
+	)
+	switch a := args.(type) {
+	case *config.ServerArgument:
+		idl, searchPaths = a.IdlPath, a.SliceParam.ProtoSearchPath
+	case *config.ClientArgument:
+		idl, searchPaths = a.IdlPath, a.SliceParam.ProtoSearchPath
+	case *config.DocArgument:
+		idl, searchPaths = a.IdlPath, a.ProtoSearchPath
+	case *config.ModelArgument:
+		if a.SQLDir == "" {
+			return nil, errs.New(errs.InvalidArgs, "--%s needs --%s, a database can not be watched", consts.Watch, consts.SQLDir)
+		}
+		return func() ([]string, error) {
+			return watch.SQLFiles(a.SQLDir)
+		}, nil
+	default:
+		return nil, errs.New(errs.InvalidArgs, "--%s is not supported by this command", consts.Watch)
+	}
+	if idl == "" {
+		return nil, errs.New(errs.InvalidArgs, "--%s needs --%s to watch", consts.Watch, consts.IDLPath)
+	}
+	return func() ([]string, error) {
+		return watch.IDLFiles(idl, searchPaths)
+	}, nil
+}
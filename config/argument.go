@@ -17,6 +17,11 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cloudwego/cwgo/pkg/common/errs"
 	"github.com/cloudwego/cwgo/pkg/consts"
 	"gorm.io/driver/mysql"
 	"gorm.io/driver/postgres"
@@ -41,17 +46,25 @@ type Argument struct {
 	*JobArgument
 	*ApiArgument
 	*FallbackArgument
+	*GenArgument
+	*DoctorArgument
+	*DeployArgument
+	*MiddlewareArgument
 }
 
 func NewArgument() *Argument {
 	return &Argument{
-		ServerArgument:   NewServerArgument(),
-		ClientArgument:   NewClientArgument(),
-		ModelArgument:    NewModelArgument(),
-		DocArgument:      NewDocArgument(),
-		JobArgument:      NewJobArgument(),
-		ApiArgument:      NewApiArgument(),
-		FallbackArgument: NewFallbackArgument(),
+		ServerArgument:     NewServerArgument(),
+		ClientArgument:     NewClientArgument(),
+		ModelArgument:      NewModelArgument(),
+		DocArgument:        NewDocArgument(),
+		JobArgument:        NewJobArgument(),
+		ApiArgument:        NewApiArgument(),
+		FallbackArgument:   NewFallbackArgument(),
+		GenArgument:        NewGenArgument(),
+		DoctorArgument:     NewDoctorArgument(),
+		DeployArgument:     NewDeployArgument(),
+		MiddlewareArgument: NewMiddlewareArgument(),
 	}
 }
 
@@ -63,3 +76,62 @@ var OpenTypeFuncMap = map[consts.DataBaseType]DialectorFunc{
 	consts.Sqlite:    sqlite.Open,
 	consts.Postgres:  postgres.Open,
 }
+
+// absPath makes p absolute, empty path is kept as is.
+func absPath(p string) (string, error) {
+	if p == "" || filepath.IsAbs(p) {
+		return p, nil
+	}
+	return filepath.Abs(p)
+}
+
+func absPaths(ps []string) ([]string, error) {
+	for i, p := range ps {
+		ap, err := absPath(p)
+		if err != nil {
+			return nil, err
+		}
+		ps[i] = ap
+	}
+	return ps, nil
+}
+
+// sandboxPath makes the output path p relative to base, the dir p is relative to, for the generations
+// run in a sandbox copy of root (dry run and package cwgo). The paths out of root are refused,
+// they would be written into the real tree instead of the sandbox.
+func sandboxPath(root, base, p string) (string, error) {
+	if p == "" {
+		return p, nil
+	}
+	ap := p
+	if !filepath.IsAbs(ap) {
+		ap = filepath.Join(base, ap)
+	}
+	rel, err := filepath.Rel(root, ap)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errs.New(errs.InvalidArgs, "output path %s is out of %s, it can't be generated in the sandbox", p, root)
+	}
+	return filepath.Rel(base, ap)
+}
+
+// sandboxPaths applies sandboxPath to the output paths of a generation run in a sandbox copy of the working directory.
+func sandboxPaths(ps ...*string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	for _, p := range ps {
+		if *p, err = sandboxPath(cwd, cwd, *p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// absTemplate makes a local template path absolute, git templates and built-in template names are kept.
+func absTemplate(t string) (string, error) {
+	if strings.HasSuffix(t, consts.SuffixGit) || t == consts.Standard || t == consts.StandardV2 {
+		return t, nil
+	}
+	return absPath(t)
+}
@@ -29,13 +29,14 @@ type ClientArgument struct {
 
 	SliceParam *SliceParam
 
-	Verbose  bool
-	Template string
-	Branch   string
-	Cwd      string
-	GoSrc    string
-	GoPkg    string
-	GoPath   string
+	Verbose         bool
+	Template        string
+	TemplateOverlay string
+	Branch          string
+	Cwd             string
+	GoSrc           string
+	GoPkg           string
+	GoPath          string
 }
 
 func NewClientArgument() *ClientArgument {
@@ -48,8 +49,42 @@ func NewClientArgument() *ClientArgument {
 func (c *ClientArgument) ParseCli(ctx *cli.Context) error {
 	c.Type = strings.ToUpper(ctx.String(consts.ServiceType))
 	c.Registry = strings.ToUpper(ctx.String(consts.Registry))
+	c.Observability = upperAll(ctx.StringSlice(consts.Observability))
 	c.Verbose = ctx.Bool(consts.Verbose)
 	c.SliceParam.ProtoSearchPath = ctx.StringSlice(consts.ProtoSearchPath)
 	c.SliceParam.Pass = ctx.StringSlice(consts.Pass)
+	vars, err := ParseTemplateVars(ctx.StringSlice(consts.TplVar))
+	if err != nil {
+		return err
+	}
+	c.TemplateVars = vars
 	return nil
 }
+
+// ResolvePaths makes the input paths absolute, so they keep pointing to
+// the same files when the generation runs in another working directory.
+func (c *ClientArgument) ResolvePaths() (err error) {
+	if c.IdlPath, err = absPath(c.IdlPath); err != nil {
+		return err
+	}
+	if c.Template, err = absTemplate(c.Template); err != nil {
+		return err
+	}
+	if c.TemplateOverlay, err = absPath(c.TemplateOverlay); err != nil {
+		return err
+	}
+	c.SliceParam.ProtoSearchPath, err = absPaths(c.SliceParam.ProtoSearchPath)
+	return err
+}
+
+// Sandbox makes the output dir relative to the working directory for the dry run, see sandboxPath.
+func (c *ClientArgument) Sandbox() error {
+	return sandboxPaths(&c.OutDir)
+}
+
+// Clone returns a copy of the arguments, generating from it leaves the original untouched.
+func (c *ClientArgument) Clone() *ClientArgument {
+	cp := *c
+	cp.CommonParam, cp.SliceParam = c.CommonParam.clone(), c.SliceParam.clone()
+	return &cp
+}
@@ -0,0 +1,42 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package config
+
+import (
+	"strings"
+
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/urfave/cli/v2"
+)
+
+type DeployArgument struct {
+	Types  []string
+	Env    string
+	Image  string
+	OutDir string
+}
+
+func NewDeployArgument() *DeployArgument {
+	return &DeployArgument{}
+}
+
+func (d *DeployArgument) ParseCli(ctx *cli.Context) error {
+	d.Types = upperAll(ctx.StringSlice(consts.ServiceType))
+	d.Env = strings.ToLower(ctx.String(consts.Env))
+	d.Image = ctx.String(consts.Image)
+	d.OutDir = ctx.String(consts.OutDir)
+	return nil
+}
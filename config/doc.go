@@ -18,6 +18,8 @@ package config
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/cloudwego/cwgo/pkg/consts"
@@ -39,6 +41,7 @@ type DocArgument struct {
 	ProtocOptions   []string // options to pass through to protoc
 	ThriftOptions   []string // options to pass through to thriftgo for go flag
 	GenBase         bool
+	TemplateVars    map[string]string
 }
 
 func NewDocArgument() *DocArgument {
@@ -57,9 +60,53 @@ func (d *DocArgument) ParseCli(ctx *cli.Context) error {
 	d.ProtocOptions = ctx.StringSlice(consts.Protoc)
 	d.ThriftOptions = ctx.StringSlice(consts.ThriftGo)
 	d.GenBase = ctx.Bool(consts.GenBase)
+	vars, err := ParseTemplateVars(ctx.StringSlice(consts.TplVar))
+	if err != nil {
+		return err
+	}
+	d.TemplateVars = vars
+	return nil
+}
+
+// ResolvePaths makes the input paths absolute, so they keep pointing to
+// the same files when the generation runs in another working directory.
+func (d *DocArgument) ResolvePaths() (err error) {
+	if d.IdlPath, err = absPath(d.IdlPath); err != nil {
+		return err
+	}
+	d.ProtoSearchPath, err = absPaths(d.ProtoSearchPath)
+	return err
+}
+
+// Sandbox makes the output dir relative to the working directory for the dry run, see sandboxPath.
+// The model and dao dirs are always based on the output dir, they only have to stay in the working directory.
+func (d *DocArgument) Sandbox() error {
+	if err := sandboxPaths(&d.OutDir); err != nil {
+		return err
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	out := filepath.Join(cwd, d.OutDir)
+	for _, p := range []string{d.ModelDir, d.DaoDir} {
+		if _, err = sandboxPath(cwd, out, filepath.Join(out, p)); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// Clone returns a copy of the arguments, generating from it leaves the original untouched.
+func (d *DocArgument) Clone() *DocArgument {
+	cp := *d
+	cp.ProtoSearchPath = append([]string(nil), d.ProtoSearchPath...)
+	cp.ProtocOptions = append([]string(nil), d.ProtocOptions...)
+	cp.ThriftOptions = append([]string(nil), d.ThriftOptions...)
+	cp.TemplateVars = mergeTemplateVars(nil, d.TemplateVars)
+	return &cp
+}
+
 func (d *DocArgument) Unpack(data []string) error {
 	err := util.UnpackArgs(data, d)
 	if err != nil {
@@ -69,7 +116,11 @@ func (d *DocArgument) Unpack(data []string) error {
 }
 
 func (d *DocArgument) Pack() ([]string, error) {
-	data, err := util.PackArgs(d)
+	// the template variables reach the plugin through the environment, see tpl.CommandEnv,
+	// as their values may hold the separators of the packed arguments
+	cp := *d
+	cp.TemplateVars = nil
+	data, err := util.PackArgs(&cp)
 	if err != nil {
 		return nil, fmt.Errorf("pack argument failed: %s", err)
 	}
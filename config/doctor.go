@@ -0,0 +1,37 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/urfave/cli/v2"
+)
+
+type DoctorArgument struct {
+	GoMod string
+	JSON  bool
+}
+
+func NewDoctorArgument() *DoctorArgument {
+	return &DoctorArgument{}
+}
+
+func (d *DoctorArgument) ParseCli(ctx *cli.Context) error {
+	d.GoMod = ctx.String(consts.Module)
+	d.JSON = ctx.Bool(consts.JSON)
+	return nil
+}
@@ -17,8 +17,7 @@
 package config
 
 import (
-	"fmt"
-
+	"github.com/cloudwego/cwgo/pkg/common/errs"
 	"github.com/cloudwego/cwgo/pkg/consts"
 	"github.com/urfave/cli/v2"
 )
@@ -35,7 +34,7 @@ func NewFallbackArgument() *FallbackArgument {
 func (c *FallbackArgument) ParseCli(ctx *cli.Context) error {
 	args := ctx.Args().Slice()
 	if len(args) < 1 {
-		return fmt.Errorf("please input tool type")
+		return errs.New(errs.InvalidArgs, "please input tool type")
 	}
 
 	c.ToolType = consts.ToolType(args[0])
@@ -45,7 +44,7 @@ func (c *FallbackArgument) ParseCli(ctx *cli.Context) error {
 	case consts.KitexTool:
 		c.ToolType = consts.KitexTool
 	default:
-		return fmt.Errorf("tool type is not supported")
+		return errs.New(errs.InvalidArgs, "tool type is not supported")
 	}
 
 	c.Args = args
@@ -0,0 +1,80 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cloudwego/cwgo/pkg/common/errs"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/urfave/cli/v2"
+)
+
+type GenArgument struct {
+	File    string
+	Verbose bool
+	// SandboxDir is set by Sandbox to the manifest dir in the real tree,
+	// the entries of the manifest must then write into it only.
+	SandboxDir string
+	// WrapTask wraps the generation of every entry of the manifest in the dir of the entry,
+	// args is the argument of the entry, e.g. *ServerArgument. The cwgo command locks the entries with it.
+	WrapTask func(args interface{}, fn func() error) (func() error, error)
+}
+
+func NewGenArgument() *GenArgument {
+	return &GenArgument{}
+}
+
+func (g *GenArgument) ParseCli(ctx *cli.Context) error {
+	g.File = ctx.String(consts.File)
+	g.Verbose = ctx.Bool(consts.Verbose)
+	return nil
+}
+
+// ResolvePaths keeps the manifest relative to the working directory,
+// so entries resolved against it point into the directory the generation runs in.
+func (g *GenArgument) ResolvePaths() error {
+	if g.File == "" || !filepath.IsAbs(g.File) {
+		return nil
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	rel, err := filepath.Rel(cwd, g.File)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return errs.New(errs.InvalidArgs, "manifest %s must be under the current directory in dry run mode", g.File)
+	}
+	g.File = rel
+	return nil
+}
+
+// Sandbox marks the generation as run in a sandbox, see Manifest.Sandbox.
+func (g *GenArgument) Sandbox() error {
+	file := g.File
+	if file == "" {
+		file = consts.ManifestFile
+	}
+	abPath, err := filepath.Abs(file)
+	if err != nil {
+		return err
+	}
+	g.SandboxDir = filepath.Dir(abPath)
+	return nil
+}
@@ -26,6 +26,7 @@ type JobArgument struct {
 	PackagePrefix string
 	JobName       []string
 	OutDir        string
+	TemplateVars  map[string]string
 }
 
 func NewJobArgument() *JobArgument {
@@ -36,5 +37,23 @@ func (j *JobArgument) ParseCli(ctx *cli.Context) error {
 	j.JobName = ctx.StringSlice(consts.JobName)
 	j.GoMod = ctx.String(consts.Module)
 	j.OutDir = ctx.String(consts.OutDir)
+	vars, err := ParseTemplateVars(ctx.StringSlice(consts.TplVar))
+	if err != nil {
+		return err
+	}
+	j.TemplateVars = vars
 	return nil
 }
+
+// Clone returns a copy of the arguments, generating from it leaves the original untouched.
+func (j *JobArgument) Clone() *JobArgument {
+	cp := *j
+	cp.JobName = append([]string(nil), j.JobName...)
+	cp.TemplateVars = mergeTemplateVars(nil, j.TemplateVars)
+	return &cp
+}
+
+// Sandbox makes the output dir relative to the working directory for the dry run, see sandboxPath.
+func (j *JobArgument) Sandbox() error {
+	return sandboxPaths(&j.OutDir)
+}
@@ -0,0 +1,173 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cloudwego/cwgo/pkg/common/errs"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"gopkg.in/yaml.v3"
+)
+
+// Layers holds the flag values of the repo-level .cwgo.yaml and of the CWGO_* environment variables.
+// The precedence is: command line flags, environment variables, .cwgo.yaml, built-in defaults.
+//
+// Keys of .cwgo.yaml are flag names, the top-level ones apply to every command
+// and the ones in a command section only to that command:
+//
+//	module: github.com/cloudwego/biz-demo
+//	registry: NACOS
+//	server:
+//	  idl: idl/user.thrift
+//	model:
+//	  db_type: mysql
+//
+// The template variables of --tpl_var are the template_vars map, the ones of a command section
+// override the top-level ones by key:
+//
+//	template_vars:
+//	  owner: team-a
+//	  psm: biz.demo.user
+//
+// Environment variables are CWGO_<FLAG> and CWGO_<COMMAND>_<FLAG>, e.g. CWGO_DSN or CWGO_SERVER_IDL.
+type Layers struct {
+	// File is the path of .cwgo.yaml, empty if there is none.
+	File string
+
+	global   map[string]interface{}
+	commands map[string]map[string]interface{}
+}
+
+const (
+	SourceDefault = "default"
+	SourceFlag    = "flag"
+)
+
+// LoadLayers looks for .cwgo.yaml from dir up to the root.
+func LoadLayers(dir string) (*Layers, error) {
+	l := &Layers{global: map[string]interface{}{}, commands: map[string]map[string]interface{}{}}
+	path, ok := findUp(dir, consts.DefaultsFile)
+	if !ok {
+		return l, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errs.New(errs.InvalidArgs, "read %s failed: %s", path, err)
+	}
+	var raw map[string]interface{}
+	if err = yaml.Unmarshal(data, &raw); err != nil {
+		return nil, errs.New(errs.InvalidArgs, "parse %s failed: %s", path, err)
+	}
+	l.File = path
+	for k, v := range raw {
+		if section, ok := v.(map[string]interface{}); ok && k != consts.TemplateVars {
+			l.commands[k] = section
+		} else {
+			l.global[k] = v
+		}
+	}
+	return l, nil
+}
+
+func findUp(dir, name string) (string, bool) {
+	for {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// Lookup returns the values of a flag of a command and where they come from, ok is false if no layer sets it.
+func (l *Layers) Lookup(command, flag string) (values []string, source string, ok bool) {
+	for _, env := range []string{EnvName(command, flag), EnvName("", flag)} {
+		if v, ok := os.LookupEnv(env); ok {
+			return []string{v}, "env " + env, true
+		}
+	}
+	if flag == consts.TplVar {
+		return l.templateVars(command)
+	}
+	if v, ok := l.commands[command][flag]; ok {
+		return l.values(flag, v), l.File, true
+	}
+	if v, ok := l.global[flag]; ok {
+		return l.values(flag, v), l.File, true
+	}
+	return nil, "", false
+}
+
+func (l *Layers) templateVars(command string) (values []string, source string, ok bool) {
+	global, gok := l.global[consts.TemplateVars]
+	section, sok := l.commands[command][consts.TemplateVars]
+	if !gok && !sok {
+		return nil, "", false
+	}
+	overrides := l.values(consts.TplVar, section)
+	return append(FilterTemplateVars(l.values(consts.TplVar, global), overrides), overrides...), l.File, true
+}
+
+// EnvName returns the environment variable of a flag, command is empty for the one shared by all commands.
+func EnvName(command, flag string) string {
+	name := flag
+	if command != "" {
+		name = command + "_" + flag
+	}
+	return consts.EnvPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// values converts a yaml value, relative paths are based on the dir of .cwgo.yaml like in the manifest.
+func (l *Layers) values(flag string, v interface{}) []string {
+	var values []string
+	if list, ok := v.([]interface{}); ok {
+		for _, item := range list {
+			values = append(values, fmt.Sprint(item))
+		}
+	} else if m, ok := v.(map[string]interface{}); ok {
+		vars := make(map[string]string, len(m))
+		for k, item := range m {
+			if item != nil {
+				vars[k] = fmt.Sprint(item)
+			} else {
+				vars[k] = ""
+			}
+		}
+		values = TemplateVarList(vars)
+	} else if v != nil {
+		values = []string{fmt.Sprint(v)}
+	}
+
+	m := &Manifest{Dir: filepath.Dir(l.File)}
+	for i, value := range values {
+		switch flag {
+		case consts.IDLPath, consts.ProtoSearchPath, consts.SQLDir, consts.TemplateOverlay:
+			values[i] = m.resolve(value)
+		case consts.Template:
+			values[i] = m.resolveTemplate(value)
+		}
+	}
+	return values
+}
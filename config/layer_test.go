@@ -0,0 +1,80 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLayers(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, ".cwgo.yaml"), []byte(`
+module: github.com/cloudwego/biz-demo
+registry: NACOS
+pass: [-use, kitex_gen]
+template_vars:
+  owner: team-a
+  port: 8888
+server:
+  idl: idl/user.thrift
+  registry: ETCD
+  template_vars:
+    owner: team-b
+    psm: biz.demo.user
+`), 0o644))
+	sub := filepath.Join(dir, "app", "user")
+	assert.NoError(t, os.MkdirAll(sub, 0o755))
+
+	l, err := LoadLayers(sub)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, ".cwgo.yaml"), l.File)
+
+	values, source, ok := l.Lookup("server", "idl")
+	assert.True(t, ok)
+	assert.Equal(t, []string{filepath.Join(dir, "idl", "user.thrift")}, values)
+	assert.Equal(t, l.File, source)
+
+	values, _, _ = l.Lookup("server", "registry")
+	assert.Equal(t, []string{"ETCD"}, values)
+	values, _, _ = l.Lookup("client", "registry")
+	assert.Equal(t, []string{"NACOS"}, values)
+	values, _, _ = l.Lookup("client", "pass")
+	assert.Equal(t, []string{"-use", "kitex_gen"}, values)
+
+	t.Setenv("CWGO_REGISTRY", "ZK")
+	values, source, _ = l.Lookup("server", "registry")
+	assert.Equal(t, []string{"ZK"}, values)
+	assert.Equal(t, "env CWGO_REGISTRY", source)
+	t.Setenv("CWGO_SERVER_REGISTRY", "POLARIS")
+	values, _, _ = l.Lookup("server", "registry")
+	assert.Equal(t, []string{"POLARIS"}, values)
+
+	values, _, _ = l.Lookup("server", "tpl_var")
+	assert.Equal(t, []string{"port=8888", "owner=team-b", "psm=biz.demo.user"}, values)
+	values, _, _ = l.Lookup("client", "tpl_var")
+	assert.Equal(t, []string{"owner=team-a", "port=8888"}, values)
+	_, _, ok = l.Lookup("template_vars", "owner")
+	assert.False(t, ok)
+
+	_, _, ok = l.Lookup("server", "dsn")
+	assert.False(t, ok)
+	assert.Equal(t, "CWGO_API_LIST_PROJECT_PATH", EnvName("api-list", "project_path"))
+}
@@ -0,0 +1,504 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cloudwego/cwgo/pkg/common/errs"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest describes every component of a repo generated by cwgo,
+// it is usually stored in the cwgo.yaml file at the root of the repo.
+type Manifest struct {
+	// Module is the default go module used by the entries that don't specify one.
+	Module   string       `yaml:"module,omitempty"`
+	Models   []ModelSpec  `yaml:"models,omitempty"`
+	Docs     []DocSpec    `yaml:"docs,omitempty"`
+	Services []ServerSpec `yaml:"services,omitempty"`
+	Clients  []ClientSpec `yaml:"clients,omitempty"`
+	Jobs     []JobSpec    `yaml:"jobs,omitempty"`
+
+	// TemplateVars are the default template variables of the entries, the ones of an entry override them by key.
+	TemplateVars map[string]string `yaml:"template_vars,omitempty"`
+
+	// Dir is the directory where the manifest file is located,
+	// relative paths in the manifest are resolved against it.
+	Dir string `yaml:"-"`
+}
+
+type ServerSpec struct {
+	Dir             string   `yaml:"dir,omitempty"`
+	ServerName      string   `yaml:"server_name"`
+	Type            string   `yaml:"type,omitempty"`
+	Module          string   `yaml:"module,omitempty"`
+	IdlPath         string   `yaml:"idl"`
+	Template        string   `yaml:"template,omitempty"`
+	Branch          string   `yaml:"branch,omitempty"`
+	Registry        string   `yaml:"registry,omitempty"`
+	ConfigCenter    string   `yaml:"config_center,omitempty"`
+	Observability   []string `yaml:"observability,omitempty"`
+	ProtoSearchPath []string `yaml:"proto_search_path,omitempty"`
+	Pass            []string `yaml:"pass,omitempty"`
+	Hex             bool     `yaml:"hex,omitempty"`
+	Verbose         bool     `yaml:"verbose,omitempty"`
+
+	TemplateVars map[string]string `yaml:"template_vars,omitempty"`
+}
+
+type ClientSpec struct {
+	Dir             string   `yaml:"dir,omitempty"`
+	ServerName      string   `yaml:"server_name"`
+	Type            string   `yaml:"type,omitempty"`
+	Module          string   `yaml:"module,omitempty"`
+	IdlPath         string   `yaml:"idl"`
+	Template        string   `yaml:"template,omitempty"`
+	Branch          string   `yaml:"branch,omitempty"`
+	Registry        string   `yaml:"registry,omitempty"`
+	Observability   []string `yaml:"observability,omitempty"`
+	ProtoSearchPath []string `yaml:"proto_search_path,omitempty"`
+	Pass            []string `yaml:"pass,omitempty"`
+	Verbose         bool     `yaml:"verbose,omitempty"`
+
+	TemplateVars map[string]string `yaml:"template_vars,omitempty"`
+}
+
+type ModelSpec struct {
+	Dir           string   `yaml:"dir,omitempty"`
+	DSN           string   `yaml:"dsn,omitempty"`
+	DBType        string   `yaml:"db_type,omitempty"`
+	SQLDir        string   `yaml:"sql_dir,omitempty"`
+	OutDir        string   `yaml:"out_dir,omitempty"`
+	OutFile       string   `yaml:"out_file,omitempty"`
+	Tables        []string `yaml:"tables,omitempty"`
+	ExcludeTables []string `yaml:"exclude_tables,omitempty"`
+	OnlyModel     bool     `yaml:"only_model,omitempty"`
+	UnitTest      bool     `yaml:"unittest,omitempty"`
+	ModelPkgName  string   `yaml:"model_pkg,omitempty"`
+	Nullable      bool     `yaml:"nullable,omitempty"`
+	Signable      bool     `yaml:"signable,omitempty"`
+	IndexTag      bool     `yaml:"index_tag,omitempty"`
+	TypeTag       bool     `yaml:"type_tag,omitempty"`
+}
+
+type DocSpec struct {
+	Dir             string   `yaml:"dir,omitempty"`
+	Name            string   `yaml:"name,omitempty"`
+	Module          string   `yaml:"module,omitempty"`
+	IdlPath         string   `yaml:"idl"`
+	OutDir          string   `yaml:"out_dir,omitempty"`
+	ModelDir        string   `yaml:"model_dir,omitempty"`
+	DaoDir          string   `yaml:"dao_dir,omitempty"`
+	ProtoSearchPath []string `yaml:"proto_search_path,omitempty"`
+	ThriftGo        []string `yaml:"thriftgo,omitempty"`
+	Protoc          []string `yaml:"protoc,omitempty"`
+	GenBase         bool     `yaml:"gen_base,omitempty"`
+	Verbose         bool     `yaml:"verbose,omitempty"`
+
+	TemplateVars map[string]string `yaml:"template_vars,omitempty"`
+}
+
+type JobSpec struct {
+	Dir     string   `yaml:"dir,omitempty"`
+	Module  string   `yaml:"module,omitempty"`
+	JobName []string `yaml:"job_name"`
+	OutDir  string   `yaml:"out_dir,omitempty"`
+
+	TemplateVars map[string]string `yaml:"template_vars,omitempty"`
+}
+
+// LoadManifest reads and validates the manifest file.
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errs.New(errs.InvalidArgs, "read manifest %s failed: %s", path, err)
+	}
+	m := new(Manifest)
+	if err = yaml.Unmarshal(data, m); err != nil {
+		return nil, errs.New(errs.InvalidArgs, "parse manifest %s failed: %s", path, err)
+	}
+	abPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	m.Dir = filepath.Dir(abPath)
+	if err = m.Validate(); err != nil {
+		return nil, errs.New(errs.InvalidArgs, "invalid manifest %s: %s", path, err)
+	}
+	return m, nil
+}
+
+// Validate checks the required fields of every entry.
+func (m *Manifest) Validate() error {
+	for i, s := range m.Services {
+		if s.ServerName == "" {
+			return errs.New(errs.InvalidArgs, "services[%d]: server_name is required", i)
+		}
+		if s.IdlPath == "" && !strings.EqualFold(s.Type, consts.HTTP) {
+			return errs.New(errs.InvalidArgs, "services[%d]: idl is required", i)
+		}
+	}
+	for i, c := range m.Clients {
+		if c.ServerName == "" {
+			return errs.New(errs.InvalidArgs, "clients[%d]: server_name is required", i)
+		}
+		if c.IdlPath == "" {
+			return errs.New(errs.InvalidArgs, "clients[%d]: idl is required", i)
+		}
+	}
+	for i, md := range m.Models {
+		if md.DSN == "" && md.SQLDir == "" {
+			return errs.New(errs.InvalidArgs, "models[%d]: dsn or sql_dir is required", i)
+		}
+	}
+	for i, d := range m.Docs {
+		if d.IdlPath == "" {
+			return errs.New(errs.InvalidArgs, "docs[%d]: idl is required", i)
+		}
+	}
+	for i, j := range m.Jobs {
+		if len(j.JobName) == 0 {
+			return errs.New(errs.InvalidArgs, "jobs[%d]: job_name is required", i)
+		}
+	}
+	if len(m.Services)+len(m.Clients)+len(m.Models)+len(m.Docs)+len(m.Jobs) == 0 {
+		return errs.New(errs.InvalidArgs, "nothing to generate")
+	}
+	return nil
+}
+
+// EntryDir returns the absolute working directory of an entry.
+func (m *Manifest) EntryDir(dir string) string {
+	if dir == "" {
+		return m.Dir
+	}
+	return m.resolve(dir)
+}
+
+// Sandbox makes the dirs of the entries relative to the manifest dir for the generations run in a sandbox
+// copy of it, the entries writing out of the manifest dir are refused, see sandboxPath.
+// dir is the manifest dir in the real tree, the absolute paths of the entries refer to it.
+func (m *Manifest) Sandbox(dir string) error {
+	sandbox := m.Dir
+	m.Dir = dir
+	defer func() { m.Dir = sandbox }()
+	entry := func(dir *string) (base string, err error) {
+		if *dir, err = sandboxPath(m.Dir, m.Dir, *dir); err != nil {
+			return "", err
+		}
+		return m.EntryDir(*dir), nil
+	}
+	output := func(dir *string, out ...*string) error {
+		base, err := entry(dir)
+		if err != nil {
+			return err
+		}
+		for _, p := range out {
+			if *p, err = sandboxPath(m.Dir, base, *p); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for i := range m.Services {
+		if err := output(&m.Services[i].Dir); err != nil {
+			return err
+		}
+	}
+	for i := range m.Clients {
+		if err := output(&m.Clients[i].Dir); err != nil {
+			return err
+		}
+	}
+	for i := range m.Models {
+		if err := output(&m.Models[i].Dir, &m.Models[i].OutDir); err != nil {
+			return err
+		}
+	}
+	for i := range m.Docs {
+		d := &m.Docs[i]
+		if err := output(&d.Dir, &d.OutDir); err != nil {
+			return err
+		}
+		// the model and dao dirs are always based on the output dir
+		out := filepath.Join(m.EntryDir(d.Dir), d.OutDir)
+		for _, p := range []string{d.ModelDir, d.DaoDir} {
+			if _, err := sandboxPath(m.Dir, out, filepath.Join(out, p)); err != nil {
+				return err
+			}
+		}
+	}
+	for i := range m.Jobs {
+		if err := output(&m.Jobs[i].Dir, &m.Jobs[i].OutDir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolve makes a path in the manifest absolute, relative paths are based on the manifest dir.
+func (m *Manifest) resolve(p string) string {
+	if p == "" || filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(m.Dir, p)
+}
+
+func (m *Manifest) resolveAll(ps []string) []string {
+	if len(ps) == 0 {
+		return nil
+	}
+	ret := make([]string, 0, len(ps))
+	for _, p := range ps {
+		ret = append(ret, m.resolve(p))
+	}
+	return ret
+}
+
+func (m *Manifest) module(mod string) string {
+	if mod != "" {
+		return mod
+	}
+	return m.Module
+}
+
+// resolveTemplate keeps git templates and the built-in template names untouched.
+func (m *Manifest) resolveTemplate(t string) string {
+	if t == "" || strings.HasSuffix(t, consts.SuffixGit) || t == consts.Standard || t == consts.StandardV2 {
+		return t
+	}
+	return m.resolve(t)
+}
+
+func (m *Manifest) ServerArgument(s ServerSpec) *ServerArgument {
+	sa := NewServerArgument()
+	sa.ServerName = s.ServerName
+	sa.Type = strings.ToUpper(s.Type)
+	if sa.Type == "" {
+		sa.Type = consts.RPC
+	}
+	sa.GoMod = m.module(s.Module)
+	sa.IdlPath = m.resolve(s.IdlPath)
+	sa.Template = m.resolveTemplate(s.Template)
+	sa.Branch = s.Branch
+	sa.Registry = strings.ToUpper(s.Registry)
+	sa.ConfigCenter = strings.ToUpper(s.ConfigCenter)
+	sa.Observability = upperAll(s.Observability)
+	sa.SliceParam.ProtoSearchPath = m.resolveAll(s.ProtoSearchPath)
+	sa.SliceParam.Pass = s.Pass
+	sa.Hex = s.Hex
+	sa.Verbose = s.Verbose
+	sa.TemplateVars = mergeTemplateVars(m.TemplateVars, s.TemplateVars)
+	return sa
+}
+
+func (m *Manifest) ClientArgument(c ClientSpec) *ClientArgument {
+	ca := NewClientArgument()
+	ca.ServerName = c.ServerName
+	ca.Type = strings.ToUpper(c.Type)
+	if ca.Type == "" {
+		ca.Type = consts.RPC
+	}
+	ca.GoMod = m.module(c.Module)
+	ca.IdlPath = m.resolve(c.IdlPath)
+	ca.Template = m.resolveTemplate(c.Template)
+	ca.Branch = c.Branch
+	ca.Registry = strings.ToUpper(c.Registry)
+	ca.Observability = upperAll(c.Observability)
+	ca.SliceParam.ProtoSearchPath = m.resolveAll(c.ProtoSearchPath)
+	ca.SliceParam.Pass = c.Pass
+	ca.Verbose = c.Verbose
+	ca.TemplateVars = mergeTemplateVars(m.TemplateVars, c.TemplateVars)
+	return ca
+}
+
+func (m *Manifest) ModelArgument(md ModelSpec) *ModelArgument {
+	ma := NewModelArgument()
+	ma.DSN = md.DSN
+	ma.Type = strings.ToLower(md.DBType)
+	if ma.Type == "" {
+		ma.Type = string(consts.MySQL)
+	}
+	ma.SQLDir = m.resolve(md.SQLDir)
+	if md.OutDir != "" {
+		ma.OutPath = md.OutDir
+	}
+	if md.OutFile != "" {
+		ma.OutFile = md.OutFile
+	}
+	ma.Tables = md.Tables
+	ma.ExcludeTables = md.ExcludeTables
+	ma.OnlyModel = md.OnlyModel
+	ma.WithUnitTest = md.UnitTest
+	ma.ModelPkgName = md.ModelPkgName
+	ma.FieldNullable = md.Nullable
+	ma.FieldSignable = md.Signable
+	ma.FieldWithIndexTag = md.IndexTag
+	ma.FieldWithTypeTag = md.TypeTag
+	return ma
+}
+
+func (m *Manifest) DocArgument(d DocSpec) *DocArgument {
+	da := NewDocArgument()
+	da.Name = d.Name
+	da.GoMod = m.module(d.Module)
+	da.IdlPath = m.resolve(d.IdlPath)
+	da.OutDir = d.OutDir
+	da.ModelDir = d.ModelDir
+	da.DaoDir = d.DaoDir
+	da.ProtoSearchPath = m.resolveAll(d.ProtoSearchPath)
+	da.ThriftOptions = d.ThriftGo
+	da.ProtocOptions = d.Protoc
+	da.GenBase = d.GenBase
+	da.Verbose = d.Verbose
+	da.TemplateVars = mergeTemplateVars(m.TemplateVars, d.TemplateVars)
+	return da
+}
+
+func (m *Manifest) JobArgument(j JobSpec) *JobArgument {
+	ja := NewJobArgument()
+	ja.GoMod = m.module(j.Module)
+	ja.JobName = j.JobName
+	ja.OutDir = j.OutDir
+	ja.TemplateVars = mergeTemplateVars(m.TemplateVars, j.TemplateVars)
+	return ja
+}
+
+// relative makes p relative to dir when it is inside dir, so specs stay valid when the repo moves.
+// Relative paths are based on the working directory, like the paths given on the command line.
+func relative(dir, p string) string {
+	if p == "" {
+		return p
+	}
+	ap, err := filepath.Abs(p)
+	if err != nil {
+		return filepath.ToSlash(p)
+	}
+	rel, err := filepath.Rel(dir, ap)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(ap)
+	}
+	return filepath.ToSlash(rel)
+}
+
+func relativeAll(dir string, ps []string) []string {
+	if len(ps) == 0 {
+		return nil
+	}
+	ret := make([]string, 0, len(ps))
+	for _, p := range ps {
+		ret = append(ret, relative(dir, p))
+	}
+	return ret
+}
+
+func relativeTemplate(dir, t string) string {
+	if t == "" || strings.HasSuffix(t, consts.SuffixGit) || t == consts.Standard || t == consts.StandardV2 {
+		return t
+	}
+	return relative(dir, t)
+}
+
+// Spec is the reverse of Manifest.ServerArgument, absolute paths inside dir are made relative to it.
+func (s *ServerArgument) Spec(dir string) ServerSpec {
+	return ServerSpec{
+		ServerName:      s.ServerName,
+		Type:            s.Type,
+		Module:          s.GoMod,
+		IdlPath:         relative(dir, s.IdlPath),
+		Template:        relativeTemplate(dir, s.Template),
+		Branch:          s.Branch,
+		Registry:        s.Registry,
+		ConfigCenter:    s.ConfigCenter,
+		Observability:   s.Observability,
+		ProtoSearchPath: relativeAll(dir, s.SliceParam.ProtoSearchPath),
+		Pass:            s.SliceParam.Pass,
+		Hex:             s.Hex,
+		Verbose:         s.Verbose,
+		TemplateVars:    s.TemplateVars,
+	}
+}
+
+// Spec is the reverse of Manifest.ClientArgument, absolute paths inside dir are made relative to it.
+func (c *ClientArgument) Spec(dir string) ClientSpec {
+	return ClientSpec{
+		ServerName:      c.ServerName,
+		Type:            c.Type,
+		Module:          c.GoMod,
+		IdlPath:         relative(dir, c.IdlPath),
+		Template:        relativeTemplate(dir, c.Template),
+		Branch:          c.Branch,
+		Registry:        c.Registry,
+		Observability:   c.Observability,
+		ProtoSearchPath: relativeAll(dir, c.SliceParam.ProtoSearchPath),
+		Pass:            c.SliceParam.Pass,
+		Verbose:         c.Verbose,
+		TemplateVars:    c.TemplateVars,
+	}
+}
+
+// Spec is the reverse of Manifest.ModelArgument, absolute paths inside dir are made relative to it.
+// The dsn is left out, it usually carries credentials.
+func (c *ModelArgument) Spec(dir string) ModelSpec {
+	return ModelSpec{
+		DBType:        c.Type,
+		SQLDir:        relative(dir, c.SQLDir),
+		OutDir:        c.OutPath,
+		OutFile:       c.OutFile,
+		Tables:        c.Tables,
+		ExcludeTables: c.ExcludeTables,
+		OnlyModel:     c.OnlyModel,
+		UnitTest:      c.WithUnitTest,
+		ModelPkgName:  c.ModelPkgName,
+		Nullable:      c.FieldNullable,
+		Signable:      c.FieldSignable,
+		IndexTag:      c.FieldWithIndexTag,
+		TypeTag:       c.FieldWithTypeTag,
+	}
+}
+
+// Spec is the reverse of Manifest.DocArgument, absolute paths inside dir are made relative to it.
+func (d *DocArgument) Spec(dir string) DocSpec {
+	return DocSpec{
+		Name:            d.Name,
+		Module:          d.GoMod,
+		IdlPath:         relative(dir, d.IdlPath),
+		OutDir:          d.OutDir,
+		ModelDir:        d.ModelDir,
+		DaoDir:          d.DaoDir,
+		ProtoSearchPath: relativeAll(dir, d.ProtoSearchPath),
+		ThriftGo:        d.ThriftOptions,
+		Protoc:          d.ProtocOptions,
+		GenBase:         d.GenBase,
+		Verbose:         d.Verbose,
+		TemplateVars:    d.TemplateVars,
+	}
+}
+
+// Spec is the reverse of Manifest.JobArgument.
+func (j *JobArgument) Spec() JobSpec {
+	return JobSpec{
+		Module:       j.GoMod,
+		JobName:      j.JobName,
+		OutDir:       j.OutDir,
+		TemplateVars: j.TemplateVars,
+	}
+}
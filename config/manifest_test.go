@@ -0,0 +1,143 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/stretchr/testify/assert"
+)
+
+const manifestContent = `
+module: github.com/cloudwego/demo
+template_vars:
+  owner: team-a
+models:
+  - sql_dir: sql
+    out_dir: biz/dal/query
+services:
+  - dir: app/user
+    server_name: user
+    idl: idl/user.thrift
+    registry: etcd
+    config_center: file
+    observability: [otel, prometheus]
+    proto_search_path: [idl]
+    template_vars:
+      owner: team-b
+      psm: biz.demo.user
+  - dir: app/api
+    server_name: api
+    type: http
+    module: github.com/cloudwego/api
+    idl: /abs/api.thrift
+    template: standard_v2
+clients:
+  - server_name: user
+    idl: idl/user.thrift
+jobs:
+  - job_name: [clean]
+`
+
+func TestLoadManifest(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, consts.ManifestFile)
+	assert.NoError(t, os.WriteFile(path, []byte(manifestContent), 0o644))
+
+	m, err := LoadManifest(path)
+	assert.NoError(t, err)
+	assert.Equal(t, dir, m.Dir)
+
+	user := m.ServerArgument(m.Services[0])
+	assert.Equal(t, "user", user.ServerName)
+	assert.Equal(t, consts.RPC, user.Type)
+	assert.Equal(t, consts.Etcd, user.Registry)
+	assert.Equal(t, consts.LocalFile, user.ConfigCenter)
+	assert.Equal(t, []string{consts.Otel, consts.Prometheus}, user.Observability)
+	assert.Equal(t, "github.com/cloudwego/demo", user.GoMod)
+	assert.Equal(t, filepath.Join(dir, "idl/user.thrift"), user.IdlPath)
+	assert.Equal(t, []string{filepath.Join(dir, "idl")}, user.SliceParam.ProtoSearchPath)
+	assert.Equal(t, filepath.Join(dir, "app/user"), m.EntryDir(m.Services[0].Dir))
+	assert.Equal(t, map[string]string{"owner": "team-b", "psm": "biz.demo.user"}, user.TemplateVars)
+	assert.Equal(t, user.TemplateVars, user.Spec(dir).TemplateVars)
+
+	api := m.ServerArgument(m.Services[1])
+	assert.Equal(t, consts.HTTP, api.Type)
+	assert.Equal(t, "github.com/cloudwego/api", api.GoMod)
+	assert.Equal(t, "/abs/api.thrift", api.IdlPath)
+	assert.Equal(t, consts.StandardV2, api.Template)
+	assert.Equal(t, map[string]string{"owner": "team-a"}, api.TemplateVars)
+
+	model := m.ModelArgument(m.Models[0])
+	assert.Equal(t, string(consts.MySQL), model.Type)
+	assert.Equal(t, filepath.Join(dir, "sql"), model.SQLDir)
+	assert.Equal(t, consts.DefaultDbOutFile, model.OutFile)
+
+	assert.Equal(t, dir, m.EntryDir(m.Clients[0].Dir))
+	assert.Equal(t, []string{"clean"}, m.JobArgument(m.Jobs[0]).JobName)
+}
+
+func TestLoadManifestInvalid(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, consts.ManifestFile)
+
+	assert.NoError(t, os.WriteFile(path, []byte("services:\n  - idl: a.thrift\n"), 0o644))
+	_, err := LoadManifest(path)
+	assert.ErrorContains(t, err, "server_name is required")
+
+	assert.NoError(t, os.WriteFile(path, []byte("module: demo\n"), 0o644))
+	_, err = LoadManifest(path)
+	assert.ErrorContains(t, err, "nothing to generate")
+
+	_, err = LoadManifest(filepath.Join(dir, "not_exist.yaml"))
+	assert.Error(t, err)
+}
+
+func TestSandboxPath(t *testing.T) {
+	root := filepath.Join(string(filepath.Separator), "work", "demo")
+
+	p, err := sandboxPath(root, root, filepath.Join(root, "biz", "dal"))
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join("biz", "dal"), p)
+
+	p, err = sandboxPath(root, filepath.Join(root, "app"), filepath.Join("..", "biz"))
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join("..", "biz"), p)
+
+	_, err = sandboxPath(root, root, filepath.Join(string(filepath.Separator), "tmp", "out"))
+	assert.ErrorContains(t, err, "can't be generated in the sandbox")
+	_, err = sandboxPath(root, root, filepath.Join("..", "out"))
+	assert.Error(t, err)
+}
+
+func TestManifestSandbox(t *testing.T) {
+	dir := filepath.Join(string(filepath.Separator), "work", "demo")
+	m := &Manifest{
+		Dir:    filepath.Join(string(filepath.Separator), "tmp", "sandbox"),
+		Models: []ModelSpec{{Dir: filepath.Join(dir, "app"), OutDir: filepath.Join(dir, "app", "biz", "query")}},
+	}
+	assert.NoError(t, m.Sandbox(dir))
+	assert.Equal(t, "app", m.Models[0].Dir)
+	assert.Equal(t, filepath.Join("biz", "query"), m.Models[0].OutDir)
+	assert.Equal(t, filepath.Join(string(filepath.Separator), "tmp", "sandbox"), m.Dir)
+
+	m.Jobs = []JobSpec{{JobName: []string{"clean"}, OutDir: filepath.Join("..", "jobs")}}
+	assert.ErrorContains(t, m.Sandbox(dir), "can't be generated in the sandbox")
+}
@@ -0,0 +1,46 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package config
+
+import (
+	"strings"
+
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/urfave/cli/v2"
+)
+
+type MiddlewareArgument struct {
+	Name    string
+	Type    string
+	Scope   string
+	Group   string
+	Service string
+	OutDir  string
+}
+
+func NewMiddlewareArgument() *MiddlewareArgument {
+	return &MiddlewareArgument{}
+}
+
+func (m *MiddlewareArgument) ParseCli(ctx *cli.Context) error {
+	m.Name = ctx.String(consts.Name)
+	m.Type = strings.ToUpper(ctx.String(consts.ServiceType))
+	m.Scope = strings.ToLower(ctx.String(consts.Scope))
+	m.Group = ctx.String(consts.Group)
+	m.Service = ctx.String(consts.Service)
+	m.OutDir = ctx.String(consts.OutDir)
+	return nil
+}
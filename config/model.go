@@ -64,3 +64,23 @@ func (c *ModelArgument) ParseCli(ctx *cli.Context) error {
 	c.SQLDir = ctx.String(consts.SQLDir)
 	return nil
 }
+
+// ResolvePaths makes the input paths absolute, so they keep pointing to
+// the same files when the generation runs in another working directory.
+func (c *ModelArgument) ResolvePaths() (err error) {
+	c.SQLDir, err = absPath(c.SQLDir)
+	return err
+}
+
+// Sandbox makes the output dir relative to the working directory for the dry run, see sandboxPath.
+func (c *ModelArgument) Sandbox() error {
+	return sandboxPaths(&c.OutPath)
+}
+
+// Clone returns a copy of the arguments, generating from it leaves the original untouched.
+func (c *ModelArgument) Clone() *ModelArgument {
+	cp := *c
+	cp.Tables = append([]string(nil), c.Tables...)
+	cp.ExcludeTables = append([]string(nil), c.ExcludeTables...)
+	return &cp
+}
@@ -17,6 +17,7 @@
 package config
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/cloudwego/cwgo/pkg/consts"
@@ -27,11 +28,14 @@ type ServerArgument struct {
 	// Common Param
 	*CommonParam
 
-	Template   string
-	Branch     string
-	SliceParam *SliceParam
-	Verbose    bool
-	Hex        bool // add http listen for kitex
+	Template        string
+	TemplateOverlay string
+	Branch          string
+	SliceParam      *SliceParam
+	Verbose         bool
+	Hex             bool // add http listen for kitex
+
+	ConfigCenter string
 
 	Cwd    string
 	GoSrc  string
@@ -46,6 +50,11 @@ type CommonParam struct {
 	IdlPath    string
 	OutDir     string // output path
 	Registry   string
+
+	Observability []string
+
+	// TemplateVars are the user defined variables read by the templates with TplVar.
+	TemplateVars map[string]string
 }
 
 func NewServerArgument() *ServerArgument {
@@ -58,18 +67,82 @@ func NewServerArgument() *ServerArgument {
 func (s *ServerArgument) ParseCli(ctx *cli.Context) error {
 	s.Type = strings.ToUpper(ctx.String(consts.ServiceType))
 	s.Registry = strings.ToUpper(ctx.String(consts.Registry))
+	s.ConfigCenter = strings.ToUpper(ctx.String(consts.ConfigCenter))
+	s.Observability = upperAll(ctx.StringSlice(consts.Observability))
 	s.Verbose = ctx.Bool(consts.Verbose)
+	// kitex generates a server from one IDL, the services served together are defined in it
+	if idls := ctx.StringSlice(consts.IDLPath); len(idls) > 1 {
+		return fmt.Errorf("--%s is given %d times, a server is generated from one IDL, define the services to serve together in it", consts.IDLPath, len(idls))
+	} else if len(idls) == 1 {
+		s.IdlPath = idls[0]
+	}
 	s.SliceParam.ProtoSearchPath = ctx.StringSlice(consts.ProtoSearchPath)
 	s.SliceParam.Pass = ctx.StringSlice(consts.Pass)
+	vars, err := ParseTemplateVars(ctx.StringSlice(consts.TplVar))
+	if err != nil {
+		return err
+	}
+	s.TemplateVars = vars
 	return nil
 }
 
+// ResolvePaths makes the input paths absolute, so they keep pointing to
+// the same files when the generation runs in another working directory.
+func (s *ServerArgument) ResolvePaths() (err error) {
+	if s.IdlPath, err = absPath(s.IdlPath); err != nil {
+		return err
+	}
+	if s.Template, err = absTemplate(s.Template); err != nil {
+		return err
+	}
+	if s.TemplateOverlay, err = absPath(s.TemplateOverlay); err != nil {
+		return err
+	}
+	s.SliceParam.ProtoSearchPath, err = absPaths(s.SliceParam.ProtoSearchPath)
+	return err
+}
+
+// Sandbox makes the output dir relative to the working directory for the dry run, see sandboxPath.
+func (s *ServerArgument) Sandbox() error {
+	return sandboxPaths(&s.OutDir)
+}
+
+// Clone returns a copy of the arguments, generating from it leaves the original untouched.
+func (s *ServerArgument) Clone() *ServerArgument {
+	cp := *s
+	cp.CommonParam, cp.SliceParam = s.CommonParam.clone(), s.SliceParam.clone()
+	return &cp
+}
+
+func (c *CommonParam) clone() *CommonParam {
+	cp := *c
+	cp.Observability = append([]string(nil), c.Observability...)
+	cp.TemplateVars = mergeTemplateVars(nil, c.TemplateVars)
+	return &cp
+}
+
+func upperAll(values []string) []string {
+	var upper []string
+	for _, v := range values {
+		upper = append(upper, strings.ToUpper(v))
+	}
+	return upper
+}
+
+func (s *SliceParam) clone() *SliceParam {
+	return &SliceParam{
+		Pass:            append([]string(nil), s.Pass...),
+		ProtoSearchPath: append([]string(nil), s.ProtoSearchPath...),
+	}
+}
+
+// WriteAnswer implements the Settable interface of survey, the answers are space separated.
 func (s *SliceParam) WriteAnswer(name string, value interface{}) error {
 	if name == consts.Pass {
-		s.Pass = strings.Split(value.(string), consts.BlackSpace)
+		s.Pass = strings.Fields(value.(string))
 	}
 	if name == consts.ProtoSearchPath {
-		s.ProtoSearchPath = strings.Split(value.(string), consts.BlackSpace)
+		s.ProtoSearchPath = strings.Fields(value.(string))
 	}
 	return nil
 }
@@ -0,0 +1,92 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package config
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/cloudwego/cwgo/pkg/common/errs"
+	"github.com/cloudwego/cwgo/pkg/consts"
+)
+
+// ParseTemplateVars parses the key=value template variables given by --tpl_var,
+// a later key overrides an earlier one. It returns nil when there is no variable.
+func ParseTemplateVars(values []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	vars := make(map[string]string, len(values))
+	last := ""
+	for _, v := range values {
+		key, value, ok := strings.Cut(v, "=")
+		key = strings.TrimSpace(key)
+		if !ok && last != "" {
+			// the values of slice flags are split on commas, a part without = continues the previous value
+			vars[last] += "," + v
+			continue
+		}
+		if !ok || key == "" {
+			return nil, errs.New(errs.InvalidArgs, "invalid %s %q, expected key=value", consts.TplVar, v)
+		}
+		vars[key] = value
+		last = key
+	}
+	return vars, nil
+}
+
+// TemplateVarList is the reverse of ParseTemplateVars, the variables are sorted by key.
+func TemplateVarList(vars map[string]string) []string {
+	list := make([]string, 0, len(vars))
+	for k, v := range vars {
+		list = append(list, k+"="+v)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// FilterTemplateVars returns the key=value variables of vars whose keys are not set by overrides,
+// appending overrides to them merges the two lists by key.
+func FilterTemplateVars(vars, overrides []string) []string {
+	set := make(map[string]bool, len(overrides))
+	for _, o := range overrides {
+		key, _, _ := strings.Cut(o, "=")
+		set[strings.TrimSpace(key)] = true
+	}
+	var res []string
+	for _, v := range vars {
+		key, _, _ := strings.Cut(v, "=")
+		if !set[strings.TrimSpace(key)] {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// mergeTemplateVars returns a copy of vars overridden by overrides, nil when both are empty.
+func mergeTemplateVars(vars, overrides map[string]string) map[string]string {
+	if len(vars)+len(overrides) == 0 {
+		return nil
+	}
+	merged := make(map[string]string, len(vars)+len(overrides))
+	for k, v := range vars {
+		merged[k] = v
+	}
+	for k, v := range overrides {
+		merged[k] = v
+	}
+	return merged
+}
@@ -0,0 +1,47 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTemplateVars(t *testing.T) {
+	vars, err := ParseTemplateVars(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, vars)
+
+	// a later key wins, the parts split on commas are joined back
+	vars, err = ParseTemplateVars([]string{"owner=team-a", "port=8888", "owner=team-b", "tags=a", "b", "empty="})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"owner": "team-b", "port": "8888", "tags": "a,b", "empty": ""}, vars)
+	assert.Equal(t, []string{"empty=", "owner=team-b", "port=8888", "tags=a,b"}, TemplateVarList(vars))
+
+	for _, v := range []string{"owner", "=team-a", " =x"} {
+		_, err = ParseTemplateVars([]string{v})
+		assert.ErrorContains(t, err, "expected key=value", v)
+	}
+}
+
+func TestFilterTemplateVars(t *testing.T) {
+	assert.Equal(t, []string{"psm=biz.demo"}, FilterTemplateVars([]string{"owner=team-a", "psm=biz.demo"}, []string{"owner=team-b"}))
+	assert.Nil(t, FilterTemplateVars([]string{"owner=team-a"}, []string{"owner=team-b", "port=1"}))
+	assert.Equal(t, map[string]string{"owner": "team-b", "psm": "biz.demo"},
+		mergeTemplateVars(map[string]string{"owner": "team-a", "psm": "biz.demo"}, map[string]string{"owner": "team-b"}))
+	assert.Nil(t, mergeTemplateVars(nil, map[string]string{}))
+}
@@ -17,46 +17,47 @@ package main
 
 import (
 	"os"
-
-	"github.com/cloudwego/cwgo/pkg/curd/doc/mongo/plugin"
+	"os/signal"
+	"syscall"
 
 	"github.com/cloudwego/cwgo/cmd/static"
+	"github.com/cloudwego/cwgo/pkg/common/utils"
+	"github.com/cloudwego/cwgo/pkg/cwgo"
 	"github.com/cloudwego/cwgo/tpl"
-	"github.com/cloudwego/hertz/cmd/hz/app"
 	"github.com/cloudwego/hertz/cmd/hz/util/logs"
-	kargs "github.com/cloudwego/kitex/tool/cmd/kitex/args"
-	"github.com/cloudwego/kitex/tool/internal_pkg/pluginmode/protoc"
-	"github.com/cloudwego/kitex/tool/internal_pkg/pluginmode/thriftgo"
 )
 
 func main() {
-	tpl.RegisterTemplateFunc()
+	// run cwgo as hz, kitex or mongo plugin mode
+	cwgo.PluginMode()
 
-	// run cwgo as hz plugin mode
-	app.PluginMode()
-	// run cwgo as kitex plugin mode
-	kitexPluginMode()
-	// run cwgo as mongo plugin mode
-	plugin.MongoPluginMode()
+	os.Exit(run())
+}
 
-	tpl.Init()
+func run() int {
+	utils.SetLogger()
+	if err := tpl.Init(); err != nil {
+		logs.Errorf("prepare the templates failed: %v\n", err)
+		return static.ExitCode(err)
+	}
+	defer tpl.Cleanup()
+	cleanupOnSignal()
 	cli := static.Init()
 
 	err := cli.Run(os.Args)
 	if err != nil {
 		logs.Errorf("%v\n", err)
 	}
+	return static.ExitCode(err)
 }
 
-func kitexPluginMode() {
-	mode := os.Getenv(kargs.EnvPluginMode)
-	if len(os.Args) <= 1 && mode != "" {
-		// run as a plugin
-		switch mode {
-		case thriftgo.PluginName:
-			os.Exit(thriftgo.Run())
-		case protoc.PluginName:
-			os.Exit(protoc.Run())
-		}
-	}
+// cleanupOnSignal removes the private template root when cwgo is interrupted, cwgo exits with ExitInterrupted.
+func cleanupOnSignal() {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ch
+		tpl.Cleanup()
+		os.Exit(static.ExitInterrupted)
+	}()
 }
@@ -0,0 +1,92 @@
+//go:build !windows
+
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/cwgo/cmd/static"
+	"github.com/stretchr/testify/assert"
+)
+
+const envTestMain = "CWGO_TEST_MAIN"
+
+// TestMain runs the test binary as cwgo when a test executes it.
+func TestMain(m *testing.M) {
+	if os.Getenv(envTestMain) != "" {
+		main()
+	}
+	os.Exit(m.Run())
+}
+
+func TestInterrupted(t *testing.T) {
+	dir := t.TempDir()
+	tmp := filepath.Join(dir, "tmp")
+	assert.NoError(t, os.Mkdir(tmp, 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/demo\n"), 0o644))
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "sql"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "sql", "user.sql"),
+		[]byte("CREATE TABLE user (id bigint PRIMARY KEY, name varchar(20));\n"), 0o644))
+
+	cmd := exec.Command(os.Args[0], "model", "--sql_dir", "sql", "--watch")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), envTestMain+"=1", "TMPDIR="+tmp)
+	r, w, err := os.Pipe()
+	assert.NoError(t, err)
+	cmd.Stdout, cmd.Stderr = w, w
+	assert.NoError(t, cmd.Start())
+	w.Close()
+
+	watching := make(chan struct{})
+	go func() {
+		s := bufio.NewScanner(r)
+		for s.Scan() {
+			if strings.Contains(s.Text(), "watching for changes") {
+				close(watching)
+				break
+			}
+		}
+		for s.Scan() {
+		}
+	}()
+	select {
+	case <-watching:
+	case <-time.After(time.Minute):
+		cmd.Process.Kill()
+		t.Fatal("cwgo is not watching")
+	}
+
+	assert.NoError(t, cmd.Process.Signal(syscall.SIGINT))
+	err = cmd.Wait()
+	var exitErr *exec.ExitError
+	assert.True(t, errors.As(err, &exitErr), err)
+	assert.Equal(t, static.ExitInterrupted, exitErr.ExitCode())
+	// the template root is removed
+	left, err := filepath.Glob(filepath.Join(tmp, "cwgo-tpl-*"))
+	assert.NoError(t, err)
+	assert.Empty(t, left)
+}
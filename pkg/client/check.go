@@ -17,32 +17,34 @@
 package client
 
 import (
-	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/cwgo/pkg/common/errs"
+	"github.com/cloudwego/cwgo/pkg/common/kx_registry"
+	"github.com/cloudwego/cwgo/pkg/common/observability"
 	"github.com/cloudwego/cwgo/pkg/common/utils"
 	"github.com/cloudwego/cwgo/pkg/consts"
 )
 
 func check(ca *config.ClientArgument) error {
 	if ca.Type != consts.RPC && ca.Type != consts.HTTP {
-		return errors.New("generate type not supported")
+		return errs.New(errs.InvalidArgs, "generate type not supported")
 	}
 
-	if ca.Registry != "" &&
-		ca.Registry != consts.Zk &&
-		ca.Registry != consts.Nacos &&
-		ca.Registry != consts.Etcd &&
-		ca.Registry != consts.Polaris {
-		return errors.New("unsupported registry")
+	if !kx_registry.IsSupported(ca.Registry) {
+		return errs.New(errs.InvalidArgs, "unsupported registry %s (support %s)", ca.Registry, strings.Join(kx_registry.Registries, ", "))
+	}
+
+	if b := observability.Unsupported(ca.Observability, observability.ClientBackends); b != "" {
+		return errs.New(errs.InvalidArgs, "unsupported observability %s (support %s)", b, strings.Join(observability.ClientBackends, ", "))
 	}
 
 	if ca.ServerName == "" {
-		return errors.New("must specify server name")
+		return errs.New(errs.InvalidArgs, "must specify server name")
 	}
 
 	// handle cwd and output dir
@@ -94,14 +96,19 @@ func check(ca *config.ClientArgument) error {
 			if utils.IsWindows() {
 				goPkgSlash := strings.ReplaceAll(ca.GoPkg, consts.BackSlash, consts.Slash)
 				if goPkgSlash != ca.GoMod {
-					return fmt.Errorf("module name: %s is not the same with GoPkg under GoPath: %s", ca.GoMod, goPkgSlash)
+					return errs.New(errs.Conflict, "module name: %s is not the same with GoPkg under GoPath: %s", ca.GoMod, goPkgSlash)
 				}
 			} else {
 				if ca.GoMod != ca.GoPkg {
-					return fmt.Errorf("module name: %s is not the same with GoPkg under GoPath: %s", ca.GoMod, ca.GoPkg)
+					return errs.New(errs.Conflict, "module name: %s is not the same with GoPkg under GoPath: %s", ca.GoMod, ca.GoPkg)
 				}
 			}
 		}
 	}
 	return nil
 }
+
+// Check validates the arguments the same way the generator does, the derived fields are filled.
+func Check(ca *config.ClientArgument) error {
+	return check(ca)
+}
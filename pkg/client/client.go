@@ -18,11 +18,16 @@ package client
 
 import (
 	"bytes"
-	"os"
+	"path/filepath"
 	"strings"
 
+	"github.com/cloudwego/cwgo/pkg/common/errs"
+	"github.com/cloudwego/cwgo/pkg/common/governance"
+	"github.com/cloudwego/cwgo/pkg/common/hz_registry"
 	"github.com/cloudwego/cwgo/pkg/common/kx_registry"
+	"github.com/cloudwego/cwgo/pkg/common/observability"
 	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/cloudwego/cwgo/tpl"
 
 	"github.com/cloudwego/cwgo/pkg/common/utils"
 	kargs "github.com/cloudwego/kitex/tool/cmd/kitex/args"
@@ -36,7 +41,6 @@ import (
 	hzConfig "github.com/cloudwego/hertz/cmd/hz/config"
 	"github.com/cloudwego/hertz/cmd/hz/meta"
 	"github.com/cloudwego/hertz/cmd/hz/util/logs"
-	"github.com/urfave/cli/v2"
 )
 
 func Client(c *config.ClientArgument) error {
@@ -45,6 +49,11 @@ func Client(c *config.ClientArgument) error {
 	if err != nil {
 		return err
 	}
+	restore, err := tpl.ApplyOverlay(c.TemplateOverlay)
+	if err != nil {
+		return errs.Wrap(errs.InvalidArgs, err)
+	}
+	defer restore()
 	switch c.Type {
 	case consts.RPC:
 		var args kargs.Arguments
@@ -53,21 +62,54 @@ func Client(c *config.ClientArgument) error {
 		if err != nil {
 			return err
 		}
-
-		kx_registry.HandleRegistry(c.CommonParam, args.TemplateDir)
-		defer kx_registry.RemoveExtension()
+		var methods []*governance.Method
+		if args.IDLType == consts.Thrift {
+			methods, err = governance.Parse(args.IDL, args.Includes)
+			if err != nil {
+				return errs.New(errs.IDLParse, "parse the governance annotations of %s failed: %s", args.IDL, err)
+			}
+		} else {
+			annotated, err := governance.ProtoAnnotated(args.IDL)
+			if err != nil {
+				return errs.Wrap(errs.InvalidArgs, err)
+			}
+			if annotated {
+				return errs.New(errs.InvalidArgs, "the governance annotations are supported in thrift IDLs only, remove the rpc.* method options from %s", args.IDL)
+			}
+		}
+		// the registry extension carries code snippets, it is written last to keep them as is
+		extension, err := observability.HandleKitex(c.Observability, &args)
+		if err != nil {
+			return err
+		}
+		defer observability.RemoveExtension(extension)
+		extension, err = governance.HandleKitex(methods, &args)
+		if err != nil {
+			return err
+		}
+		defer governance.RemoveExtension(extension)
+		extension, err = kx_registry.HandleRegistry(c.CommonParam, &args)
+		if err != nil {
+			return err
+		}
+		defer kx_registry.RemoveExtension(extension)
 
 		out := new(bytes.Buffer)
 		cmd := args.BuildCmd(out)
+		// the template variables reach the kitex plugin process through its environment only
+		if cmd.Env, err = tpl.CommandEnv(cmd.Env, c.TemplateVars); err != nil {
+			return err
+		}
 		err = cmd.Run()
+		// kitex_gen is not generated because of the -use option, it is not a failure
+		// and the generated code is post processed all the same
+		if err != nil && (args.Use == "" || !strings.HasSuffix(strings.TrimSpace(out.String()), thriftgo.TheUseOptionMessage)) {
+			return errs.New(errs.IDLParse, "kitex generation failed: %s\n%s", err, strings.TrimSpace(out.String()))
+		}
+		pkg := strings.NewReplacer(".", "_", "/", "_").Replace(args.ServiceName)
+		err = governance.Generate(filepath.Join(args.OutputPath, consts.DefaultKitexClientDir, pkg), pkg, c.GoMod, methods)
 		if err != nil {
-			if args.Use != "" {
-				out := strings.TrimSpace(out.String())
-				if strings.HasSuffix(out, thriftgo.TheUseOptionMessage) {
-					utils.ReplaceThriftVersion()
-				}
-			}
-			os.Exit(1)
+			return errs.New(errs.PostProcess, "generate the governance of the client failed: %s", err)
 		}
 		utils.ReplaceThriftVersion()
 		utils.UpgradeGolangProtobuf()
@@ -79,11 +121,22 @@ func Client(c *config.ClientArgument) error {
 		if err != nil {
 			return err
 		}
+		data, err := hz_registry.NewData(c.CommonParam)
+		if err != nil {
+			return err
+		}
+		data.Vars = c.TemplateVars
+		pkg, err := hz_registry.Render(args.CustomizePackage, data)
+		if err != nil {
+			return err
+		}
+		defer hz_registry.RemoveTemplate(pkg)
+		args.CustomizePackage = pkg
 		args.CmdType = meta.CmdClient
 		logs.Debugf("Args: %#v\n", args)
 		err = app.TriggerPlugin(args)
 		if err != nil {
-			return cli.Exit(err, meta.PluginError)
+			return errs.Wrap(errs.IDLParse, err)
 		}
 	}
 	return nil
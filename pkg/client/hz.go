@@ -18,12 +18,12 @@ package client
 
 import (
 	"flag"
-	"fmt"
 	"path"
 	"path/filepath"
 	"strings"
 
 	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/cwgo/pkg/common/errs"
 	"github.com/cloudwego/cwgo/pkg/common/utils"
 	"github.com/cloudwego/cwgo/pkg/consts"
 	"github.com/cloudwego/cwgo/tpl"
@@ -34,7 +34,7 @@ func convertHzArgument(ca *config.ClientArgument, hzArgument *hzConfig.Argument)
 	// Common commands
 	abPath, err := filepath.Abs(ca.IdlPath)
 	if err != nil {
-		return fmt.Errorf("idl path %s is not absolute", ca.IdlPath)
+		return errs.New(errs.InvalidArgs, "idl path %s is not absolute", ca.IdlPath)
 	}
 
 	if strings.HasSuffix(ca.Template, consts.SuffixGit) {
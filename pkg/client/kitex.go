@@ -25,13 +25,13 @@ import (
 	"strings"
 
 	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/cwgo/pkg/common/errs"
 	"github.com/cloudwego/cwgo/pkg/common/utils"
 	"github.com/cloudwego/cwgo/pkg/consts"
 	"github.com/cloudwego/cwgo/tpl"
 	"github.com/cloudwego/kitex"
 	kargs "github.com/cloudwego/kitex/tool/cmd/kitex/args"
 	"github.com/cloudwego/kitex/tool/internal_pkg/generator"
-	"github.com/cloudwego/kitex/tool/internal_pkg/log"
 )
 
 func convertKitexArgs(sa *config.ClientArgument, kitexArgument *kargs.Arguments) (err error) {
@@ -84,7 +84,6 @@ Usage: %s [flags] IDL
 Flags:
 `, kitexArgument.Version, os.Args[0])
 		f.PrintDefaults()
-		os.Exit(1)
 	}
 
 	err = f.Parse(utils.StringSliceSpilt(sa.SliceParam.Pass))
@@ -130,8 +129,7 @@ func checkKitexArgs(a *kargs.Arguments) (err error) {
 	// check service name
 	if a.ServiceName == "" {
 		if a.Use != "" {
-			log.Warn("-use must be used with -service")
-			os.Exit(2)
+			return errs.New(errs.InvalidArgs, "-use must be used with -service")
 		}
 	}
 
@@ -146,25 +144,21 @@ func checkKitexArgs(a *kargs.Arguments) (err error) {
 	gosrc := filepath.Join(gopath, "src")
 	gosrc, err = filepath.Abs(gosrc)
 	if err != nil {
-		log.Warn("Get GOPATH/src path failed:", err.Error())
-		os.Exit(1)
+		return fmt.Errorf("get GOPATH/src path failed: %s", err)
 	}
 	curpath, err := filepath.Abs(".")
 	if err != nil {
-		log.Warn("Get current path failed:", err.Error())
-		os.Exit(1)
+		return fmt.Errorf("get current path failed: %s", err)
 	}
 
 	if strings.HasPrefix(curpath, gosrc) {
 		if a.PackagePrefix, err = filepath.Rel(gosrc, curpath); err != nil {
-			log.Warn("Get GOPATH/src relpath failed:", err.Error())
-			os.Exit(1)
+			return fmt.Errorf("get GOPATH/src relpath failed: %s", err)
 		}
 		a.PackagePrefix = filepath.Join(a.PackagePrefix, generator.KitexGenPath)
 	} else {
 		if a.ModuleName == "" {
-			log.Warn("Outside of $GOPATH. Please specify a module name with the '-module' flag.")
-			os.Exit(1)
+			return errs.New(errs.InvalidArgs, "outside of $GOPATH. Please specify a module name with the '-module' flag")
 		}
 	}
 
@@ -173,19 +167,16 @@ func checkKitexArgs(a *kargs.Arguments) (err error) {
 		if ok {
 			// go.mod exists
 			if module != a.ModuleName {
-				log.Warnf("The module name given by the '-module' option ('%s') is not consist with the name defined in go.mod ('%s' from %s)\n",
+				return errs.New(errs.Conflict, "the module name given by the '-module' option ('%s') is not consist with the name defined in go.mod ('%s' from %s)",
 					a.ModuleName, module, path)
-				os.Exit(1)
 			}
 			if a.PackagePrefix, err = filepath.Rel(path, curpath); err != nil {
-				log.Warn("Get package prefix failed:", err.Error())
-				os.Exit(1)
+				return fmt.Errorf("get package prefix failed: %s", err)
 			}
 			a.PackagePrefix = filepath.Join(a.ModuleName, a.PackagePrefix, generator.KitexGenPath)
 		} else {
 			if err = utils.InitGoMod(a.ModuleName); err != nil {
-				log.Warn("Init go mod failed:", err.Error())
-				os.Exit(1)
+				return fmt.Errorf("init go mod failed: %w", err)
 			}
 			a.PackagePrefix = filepath.Join(a.ModuleName, generator.KitexGenPath)
 		}
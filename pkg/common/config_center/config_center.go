@@ -0,0 +1,70 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_center
+
+import (
+	"os"
+	"strings"
+
+	"github.com/cloudwego/cwgo/pkg/common/utils"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	kargs "github.com/cloudwego/kitex/tool/cmd/kitex/args"
+)
+
+// Centers lists the supported config centers.
+var Centers = []string{consts.Nacos, consts.Etcd, consts.Apollo, consts.LocalFile}
+
+// IsSupported reports whether the config center is supported, an empty config center means none.
+func IsSupported(center string) bool {
+	if center == "" {
+		return true
+	}
+	for _, c := range Centers {
+		if c == center {
+			return true
+		}
+	}
+	return false
+}
+
+// Name is the config center in lower case, hz templates query it with [[if eq .ConfigCenter "etcd"]].
+func Name(center string) string {
+	return strings.ToLower(center)
+}
+
+// Feature is the name of the kitex template feature enabled for the config center,
+// templates query it with {{if HasFeature .Features "config_center_etcd"}}.
+func Feature(center string) string {
+	return "config_center_" + Name(center)
+}
+
+// HandleKitex enables the feature of the config center in a copy of the template extension of args,
+// see utils.EnableKitexFeatures. The returned path is empty when no config center is used.
+func HandleKitex(center string, args *kargs.Arguments) (string, error) {
+	if center == "" {
+		return "", nil
+	}
+	return utils.EnableKitexFeatures(args, Feature(center))
+}
+
+// RemoveExtension removes the extension file written by HandleKitex.
+func RemoveExtension(path string) {
+	if path == "" {
+		return
+	}
+	os.Remove(path)
+}
@@ -0,0 +1,205 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_center
+
+import (
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/cwgo/pkg/common/kx_registry"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/cloudwego/cwgo/tpl"
+	"github.com/cloudwego/kitex"
+	kargs "github.com/cloudwego/kitex/tool/cmd/kitex/args"
+	"github.com/cloudwego/kitex/tool/internal_pkg/generator"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsSupported(t *testing.T) {
+	assert.True(t, IsSupported(""))
+	assert.True(t, IsSupported(consts.LocalFile))
+	assert.False(t, IsSupported(consts.Zk))
+	assert.Equal(t, "config_center_apollo", Feature(consts.Apollo))
+}
+
+func TestHandleKitex(t *testing.T) {
+	assert.NoError(t, tpl.Init())
+	defer tpl.Cleanup()
+
+	args := &kargs.Arguments{}
+	path, err := HandleKitex("", args)
+	assert.NoError(t, err)
+	assert.Empty(t, path)
+	assert.Empty(t, args.ExtensionFile)
+
+	path, err = HandleKitex(consts.Etcd, args)
+	assert.NoError(t, err)
+	assert.Equal(t, path, args.ExtensionFile)
+	defer func() {
+		RemoveExtension(path)
+		_, err = os.Stat(path)
+		assert.True(t, os.IsNotExist(err))
+	}()
+
+	// the registry merges the extension of the config center
+	registry, err := kx_registry.HandleRegistry(&config.CommonParam{ServerName: "demo", GoMod: "demo", Registry: consts.Etcd}, args)
+	assert.NoError(t, err)
+	defer kx_registry.RemoveExtension(registry)
+
+	te := new(generator.TemplateExtension)
+	assert.NoError(t, te.FromYAMLFile(args.ExtensionFile))
+	assert.ElementsMatch(t, []string{kx_registry.Feature(consts.Etcd), Feature(consts.Etcd)}, te.EnableFeatures)
+	assert.NotNil(t, te.ExtendServer)
+}
+
+// fileCenterTest drives the conf package generated with the file config center,
+// the config of the center is center.yaml and the local conf.yaml bootstraps it.
+const fileCenterTest = `package conf
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	// the conf package reads conf/<env>/conf.yaml from the root of the project
+	if err := os.Chdir(".."); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+func write(t *testing.T, content string, modTime time.Time) {
+	if err := os.WriteFile("center.yaml", []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes("center.yaml", modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFallback(t *testing.T) {
+	// center.yaml is missing, the local conf.yaml is kept
+	if s := GetConf().Kitex.Service; s != "demo" {
+		t.Fatalf("service %q, want the local demo", s)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	now := time.Now()
+	write(t, "kitex:\n  service: v1\nconfig_center:\n  path: other.yaml\n", now)
+	if s := GetConf().Kitex.Service; s != "v1" {
+		t.Fatalf("service %q, want v1 loaded from the config center", s)
+	}
+	if p := GetConf().ConfigCenter.Path; p != "center.yaml" {
+		t.Fatalf("config center path %q, want the local center.yaml", p)
+	}
+
+	changes := make(chan [2]string, 10)
+	OnChange(func(old, new *Config) {
+		changes <- [2]string{old.Kitex.Service, new.Kitex.Service}
+	})
+	write(t, "kitex:\n  service: v2\n", now.Add(2*time.Second))
+	select {
+	case c := <-changes:
+		if c != [2]string{"v1", "v2"} {
+			t.Fatalf("change %v, want v1 -> v2", c)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the change of center.yaml is not seen")
+	}
+	if s := GetConf().Kitex.Service; s != "v2" {
+		t.Fatalf("service %q, want v2", s)
+	}
+
+	// an invalid config is ignored, the callbacks are not called
+	write(t, "kitex: [", now.Add(4*time.Second))
+	select {
+	case c := <-changes:
+		t.Fatalf("unexpected change %v", c)
+	case <-time.After(3 * time.Second):
+	}
+	if s := GetConf().Kitex.Service; s != "v2" {
+		t.Fatalf("service %q, want v2 kept", s)
+	}
+}
+`
+
+func TestFileConfigCenter(t *testing.T) {
+	assert.NoError(t, tpl.Init())
+	defer tpl.Cleanup()
+
+	args := &kargs.Arguments{}
+	extension, err := HandleKitex(consts.LocalFile, args)
+	assert.NoError(t, err)
+	defer RemoveExtension(extension)
+	c := &generator.Config{
+		TemplateDir: path.Join(tpl.KitexDir, consts.Server, consts.Standard), OutputPath: t.TempDir(),
+		ModuleName: "example.com/demo", ServiceName: "demo", ExtensionFile: args.ExtensionFile,
+	}
+	assert.NoError(t, c.ApplyExtension())
+	demo := generator.PkgInfo{PkgName: "demo", PkgRefName: "demo", ImportPath: "example.com/demo/kitex_gen/demo"}
+	fs, err := generator.NewGenerator(c, nil).GenerateCustomPackage(&generator.PackageInfo{
+		ServiceInfo: &generator.ServiceInfo{PkgInfo: demo, ServiceName: "Demo"},
+	})
+	assert.NoError(t, err)
+
+	project := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/demo\n\ngo 1.18\n\nrequire (\n\tgithub.com/cloudwego/kitex " + kitex.Version +
+			"\n\tgithub.com/kr/pretty v0.3.1\n\tgopkg.in/validator.v2 v2.0.1\n\tgopkg.in/yaml.v3 v3.0.1\n)\n",
+		filepath.Join("conf", "conf_test.go"): fileCenterTest,
+	}
+	for _, f := range fs {
+		rel, err := filepath.Rel(c.OutputPath, f.Name)
+		assert.NoError(t, err)
+		switch filepath.ToSlash(rel) {
+		case "conf/conf.go":
+			files[rel] = f.Content
+		case "conf/test/conf.yaml":
+			assert.Contains(t, f.Content, `path: ""`)
+			files[rel] = strings.Replace(f.Content, `path: ""`, "path: center.yaml", 1)
+		}
+	}
+	assert.Len(t, files, 4)
+	for name, content := range files {
+		assert.NoError(t, os.MkdirAll(filepath.Join(project, filepath.Dir(name)), 0o755))
+		assert.NoError(t, os.WriteFile(filepath.Join(project, name), []byte(content), 0o644))
+	}
+
+	goCmd := func(args ...string) *exec.Cmd {
+		cmd := exec.Command("go", args...)
+		cmd.Dir = project
+		// go.sum is written from the module cache
+		cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOWORK=off", "GOSUMDB=off")
+		return cmd
+	}
+	if out, err := goCmd("mod", "download", "github.com/cloudwego/kitex", "github.com/kr/pretty", "gopkg.in/validator.v2", "gopkg.in/yaml.v3").CombinedOutput(); err != nil {
+		t.Skipf("the modules of the generated conf are not available: %s\n%s", err, out)
+	}
+	// center.yaml is written by TestWatch, TestFallback runs first in a process of its own
+	for _, test := range []string{"TestFallback", "TestWatch"} {
+		out, err := goCmd("test", "-count=1", "-run", "^"+test+"$", "./conf").CombinedOutput()
+		assert.NoError(t, err, "%s\n%s", test, out)
+	}
+}
@@ -0,0 +1,451 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dryrun
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/cwgo/pkg/common/utils"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/cloudwego/hertz/cmd/hz/util/logs"
+)
+
+type Status string
+
+const (
+	Created  Status = "created"
+	Modified Status = "modified"
+	Deleted  Status = "deleted"
+	// Unchanged means the generator rewrote the file with exactly the same content.
+	Unchanged Status = "unchanged"
+	// Skipped means the generator would create the file, but left the existing one as is.
+	Skipped Status = "skipped"
+)
+
+// inputs are the files a generator reads to find out the project it generates, they are kept
+// in the copy finding the skipped files, as well as the IDL and SQL files.
+var inputs = map[string]bool{
+	consts.GoMod:        true,
+	"go.sum":            true,
+	consts.HzFile:       true,
+	consts.LockFile:     true,
+	consts.ManifestFile: true,
+	".thrift":           true,
+	".proto":            true,
+	".sql":              true,
+}
+
+// FileChange is a file which would be written by the generator.
+type FileChange struct {
+	Path   string // slash separated path relative to the working directory
+	Status Status
+	Mode   fs.FileMode
+	Old    []byte
+	New    []byte
+}
+
+type Report struct {
+	Changes []FileChange
+}
+
+// Run runs fn in a scratch copy of the current working directory and reports
+// what fn would change in the working tree, the working tree itself is never touched.
+//
+// Generators write files in many ways (kitex templates, hz and thriftgo plugins,
+// gorm gen, cwgo's own renderers), so instead of hooking each of them with an in-memory
+// file system, the whole generation happens in the scratch directory and the result is
+// compared afterward. Paths given to fn must be absolute if they are read from the working
+// tree, and relative if they are written, absolute output paths would escape the scratch
+// directory and are refused by the callers beforehand.
+func Run(fn func() error) (*Report, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("get current path failed: %s", err)
+	}
+	return RunIn(cwd, fn)
+}
+
+// RunIn is like Run, but the scratch directory is a copy of dir, an empty dir is allowed.
+//
+// The module context of dir is kept, so fn sees the same module and package paths as in dir:
+// the go.mod and go.sum of a parent module are copied to the same place relative to the copy,
+// and a dir under GOPATH/src is copied under the src of a scratch GOPATH, which is the GOPATH
+// of fn. The vendor and .git dirs are not copied as the generators never read them.
+// The working directory and GOPATH are restored when RunIn returns.
+//
+// The generators skip the files which exist already, they never tell which ones, so fn runs a second
+// time in a copy without the files it left untouched: those it creates there are reported as skipped.
+// fn must not depend on what its first run changed in its arguments.
+func RunIn(dir string, fn func() error) (*Report, error) {
+	report, untouched, err := runIn(dir, fn, nil)
+	if err != nil || len(untouched) == 0 {
+		return report, err
+	}
+	skipped, err := skips(dir, fn, untouched)
+	if err != nil {
+		logs.Warnf("the files skipped by the generator are not reported: %s", err)
+		return report, nil
+	}
+	report.Changes = append(report.Changes, skipped...)
+	sort.Slice(report.Changes, func(i, j int) bool {
+		return report.Changes[i].Path < report.Changes[j].Path
+	})
+	return report, nil
+}
+
+// skips returns the untouched files fn creates when they are missing, the inputs are kept.
+func skips(dir string, fn func() error, untouched []string) ([]FileChange, error) {
+	omit := make(map[string]bool)
+	for _, p := range untouched {
+		if !inputs[path.Base(p)] && !inputs[path.Ext(p)] {
+			omit[p] = true
+		}
+	}
+	report, _, err := runIn(dir, fn, omit)
+	if err != nil {
+		return nil, err
+	}
+	var skipped []FileChange
+	for _, c := range report.Changes {
+		if c.Status == Created && omit[c.Path] {
+			skipped = append(skipped, FileChange{Path: c.Path, Status: Skipped, Mode: c.Mode})
+		}
+	}
+	return skipped, nil
+}
+
+// runIn runs fn in a copy of dir without the omitted files, it also returns the copied files left untouched.
+func runIn(dir string, fn func() error, omit map[string]bool) (*Report, []string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, nil, fmt.Errorf("get current path failed: %s", err)
+	}
+	sandbox, err := os.MkdirTemp("", "cwgo-dryrun-")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer os.RemoveAll(sandbox)
+
+	work, extras := sandbox, map[string]string{}
+	before := make(map[string]time.Time)
+	if dir != "" {
+		var restore func()
+		if work, extras, restore, err = prepare(dir, sandbox); err != nil {
+			return nil, nil, fmt.Errorf("prepare dry run directory failed: %s", err)
+		}
+		defer restore()
+		if before, err = copyTree(dir, work, omit); err != nil {
+			return nil, nil, fmt.Errorf("prepare dry run directory failed: %s", err)
+		}
+	}
+	// the files out of dir are keyed by their path relative to the copy, e.g. ../go.mod
+	for origin, cp := range extras {
+		info, err := os.Stat(origin)
+		if err != nil {
+			delete(extras, origin)
+			continue
+		}
+		if err = os.MkdirAll(filepath.Dir(cp), 0o755); err != nil {
+			return nil, nil, err
+		}
+		if err = copyFile(origin, cp, info); err != nil {
+			return nil, nil, fmt.Errorf("prepare dry run directory failed: %s", err)
+		}
+	}
+
+	if err = os.Chdir(work); err != nil {
+		return nil, nil, err
+	}
+	defer os.Chdir(cwd)
+	if err = fn(); err != nil {
+		return nil, nil, err
+	}
+
+	report, untouched, err := compare(dir, work, before)
+	if err != nil {
+		return nil, nil, err
+	}
+	for origin, cp := range extras {
+		rel, err := filepath.Rel(work, cp)
+		if err != nil {
+			return nil, nil, err
+		}
+		info, err := os.Stat(cp)
+		if err != nil {
+			continue
+		}
+		old, err := os.Stat(origin)
+		if err != nil {
+			return nil, nil, err
+		}
+		if old.ModTime().Equal(info.ModTime()) {
+			continue
+		}
+		c, err := change(origin, cp, filepath.ToSlash(rel), info, true)
+		if err != nil {
+			return nil, nil, err
+		}
+		report.Changes = append(report.Changes, c)
+	}
+	sort.Slice(report.Changes, func(i, j int) bool {
+		return report.Changes[i].Path < report.Changes[j].Path
+	})
+	return report, untouched, nil
+}
+
+// prepare lays out the sandbox for dir and returns the copy of dir in it, the files of the module
+// context out of dir mapped to their copies, and the function restoring GOPATH.
+func prepare(dir, sandbox string) (work string, extras map[string]string, restore func(), err error) {
+	restore, extras = func() {}, map[string]string{}
+	if dir, err = filepath.Abs(dir); err != nil {
+		return "", nil, restore, err
+	}
+	// top is the dir the sandbox stands for
+	top := dir
+	if gopath, err := utils.GetGOPATH(); err == nil && gopath != "" {
+		if gopath, err = filepath.Abs(gopath); err == nil && within(filepath.Join(gopath, consts.Src), dir) {
+			top = gopath
+			restore = setGOPATH(sandbox, gopath)
+		}
+	}
+	if _, root, ok := utils.SearchGoMod(filepath.Dir(dir), true); ok && root != dir {
+		if _, err := os.Stat(filepath.Join(dir, consts.GoMod)); os.IsNotExist(err) {
+			if top == dir {
+				top = root
+			}
+			if within(top, root) {
+				for _, name := range []string{consts.GoMod, "go.sum"} {
+					rel, _ := filepath.Rel(top, filepath.Join(root, name))
+					extras[filepath.Join(root, name)] = filepath.Join(sandbox, rel)
+				}
+			}
+		}
+	}
+	rel, err := filepath.Rel(top, dir)
+	if err != nil {
+		restore()
+		return "", nil, func() {}, err
+	}
+	work = filepath.Join(sandbox, rel)
+	if err = os.MkdirAll(work, 0o755); err != nil {
+		restore()
+		return "", nil, func() {}, err
+	}
+	return work, extras, restore, nil
+}
+
+// setGOPATH points GOPATH to the sandbox, the module cache is kept in the original GOPATH.
+func setGOPATH(sandbox, gopath string) func() {
+	oldPath, pathSet := os.LookupEnv(consts.GOPATH)
+	oldCache, cacheSet := os.LookupEnv(goModCache)
+	os.Setenv(consts.GOPATH, sandbox)
+	if !cacheSet {
+		os.Setenv(goModCache, filepath.Join(gopath, "pkg", "mod"))
+	}
+	return func() {
+		restoreEnv(consts.GOPATH, oldPath, pathSet)
+		restoreEnv(goModCache, oldCache, cacheSet)
+	}
+}
+
+const goModCache = "GOMODCACHE"
+
+func restoreEnv(key, value string, set bool) {
+	if set {
+		os.Setenv(key, value)
+		return
+	}
+	os.Unsetenv(key)
+}
+
+// within reports whether p is dir or inside it.
+func within(dir, p string) bool {
+	rel, err := filepath.Rel(dir, p)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// copyTree copies src but the omitted files into dst and returns the modification time of every copied file.
+func copyTree(src, dst string, omit map[string]bool) (map[string]time.Time, error) {
+	files := make(map[string]time.Time)
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			if d.Name() == ".git" || d.Name() == "vendor" {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(target, 0o755)
+		}
+		if !d.Type().IsRegular() || omit[filepath.ToSlash(rel)] {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if err = copyFile(path, target, info); err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = info.ModTime()
+		return nil
+	})
+	return files, err
+}
+
+func copyFile(src, dst string, info fs.FileInfo) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err = out.Close(); err != nil {
+		return err
+	}
+	return os.Chtimes(dst, info.ModTime(), info.ModTime())
+}
+
+// compare reports the files written or deleted in the sandbox, and returns the copied files left untouched.
+func compare(origin, sandbox string, before map[string]time.Time) (*Report, []string, error) {
+	report := &Report{}
+	var untouched []string
+	seen := make(map[string]bool, len(before))
+	err := filepath.WalkDir(sandbox, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(sandbox, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		modTime, existed := before[rel]
+		seen[rel] = true
+		if existed && modTime.Equal(info.ModTime()) {
+			untouched = append(untouched, rel)
+			return nil
+		}
+		c, err := change(filepath.Join(origin, filepath.FromSlash(rel)), path, rel, info, existed)
+		if err != nil {
+			return err
+		}
+		report.Changes = append(report.Changes, c)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	for rel := range before {
+		if seen[rel] {
+			continue
+		}
+		old, err := os.ReadFile(filepath.Join(origin, filepath.FromSlash(rel)))
+		if err != nil {
+			return nil, nil, err
+		}
+		report.Changes = append(report.Changes, FileChange{Path: rel, Status: Deleted, Old: old})
+	}
+	sort.Slice(report.Changes, func(i, j int) bool {
+		return report.Changes[i].Path < report.Changes[j].Path
+	})
+	return report, untouched, nil
+}
+
+// change returns the change of the file origin written as cp in the sandbox.
+func change(origin, cp, rel string, info fs.FileInfo, existed bool) (FileChange, error) {
+	newContent, err := os.ReadFile(cp)
+	if err != nil {
+		return FileChange{}, err
+	}
+	if !existed {
+		return FileChange{Path: rel, Status: Created, Mode: info.Mode().Perm(), New: newContent}, nil
+	}
+	oldContent, err := os.ReadFile(origin)
+	if err != nil {
+		return FileChange{}, err
+	}
+	status := Modified
+	if bytes.Equal(oldContent, newContent) {
+		status = Unchanged
+	}
+	return FileChange{Path: rel, Status: status, Mode: info.Mode().Perm(), Old: oldContent, New: newContent}, nil
+}
+
+// Print writes a summary of the report followed by the unified diff of every created or modified file.
+func (r *Report) Print(w io.Writer) error {
+	if len(r.Changes) == 0 {
+		_, err := fmt.Fprintln(w, "dry run: no files would be changed")
+		return err
+	}
+	counts := make(map[Status]int)
+	for _, c := range r.Changes {
+		counts[c.Status]++
+		if _, err := fmt.Fprintf(w, "%-8s %s\n", c.Status, c.Path); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(w, "dry run: %d created, %d modified, %d deleted, %d unchanged, %d skipped\n\n",
+		counts[Created], counts[Modified], counts[Deleted], counts[Unchanged], counts[Skipped]); err != nil {
+		return err
+	}
+	for _, c := range r.Changes {
+		var diff string
+		switch c.Status {
+		case Created:
+			diff = Unified("/dev/null", "b/"+c.Path, "", string(c.New))
+		case Modified:
+			diff = Unified("a/"+c.Path, "b/"+c.Path, string(c.Old), string(c.New))
+		case Deleted:
+			diff = Unified("a/"+c.Path, "/dev/null", string(c.Old), "")
+		default:
+			continue
+		}
+		if _, err := io.WriteString(w, diff); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dryrun
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnified(t *testing.T) {
+	oldContent := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n"
+	newContent := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\n"
+	expected := `--- a/f.go
++++ b/f.go
+@@ -1,5 +1,5 @@
+ a
+-b
++B
+ c
+ d
+ e
+@@ -8,3 +8,4 @@
+ h
+ i
+ j
++k
+`
+	assert.Equal(t, expected, Unified("a/f.go", "b/f.go", oldContent, newContent))
+	assert.Equal(t, "", Unified("a/f.go", "b/f.go", oldContent, oldContent))
+
+	expected = `--- /dev/null
++++ b/f.go
+@@ -0,0 +1,2 @@
++x
++y
+\ No newline at end of file
+`
+	assert.Equal(t, expected, Unified("/dev/null", "b/f.go", "", "x\ny"))
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"modified.go", "unchanged.go", "skipped.go", "deleted.go", "untouched.go", "idl/demo.thrift"} {
+		assert.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0o755))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("package main\n"), 0o644))
+	}
+
+	cwd, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.NoError(t, os.Chdir(dir))
+	defer os.Chdir(cwd)
+
+	runs := 0
+	report, err := Run(func() error {
+		runs++
+		// the IDL is read, it is kept when the skipped files are looked for
+		if _, err := os.Stat("idl/demo.thrift"); err != nil {
+			return err
+		}
+		if err := os.WriteFile("modified.go", []byte("package biz\n"), 0o644); err != nil {
+			return err
+		}
+		if err := os.WriteFile("unchanged.go", []byte("package main\n"), 0o644); err != nil {
+			return err
+		}
+		// a file of update behavior skip
+		if _, err := os.Stat("skipped.go"); os.IsNotExist(err) {
+			if err := os.WriteFile("skipped.go", []byte("package biz\n"), 0o644); err != nil {
+				return err
+			}
+		}
+		if err := os.Remove("deleted.go"); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if err := os.MkdirAll("biz", 0o755); err != nil {
+			return err
+		}
+		return os.WriteFile("biz/created.go", []byte("package biz\n"), 0o644)
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, runs)
+
+	var statuses []string
+	for _, c := range report.Changes {
+		statuses = append(statuses, string(c.Status)+" "+c.Path)
+	}
+	assert.Equal(t, []string{
+		"created biz/created.go", "deleted deleted.go", "modified modified.go",
+		"skipped skipped.go", "unchanged unchanged.go",
+	}, statuses)
+
+	// the working tree is left untouched
+	content, err := os.ReadFile(filepath.Join(dir, "modified.go"))
+	assert.NoError(t, err)
+	assert.Equal(t, "package main\n", string(content))
+	_, err = os.Stat(filepath.Join(dir, "deleted.go"))
+	assert.NoError(t, err)
+	_, err = os.Stat(filepath.Join(dir, "biz"))
+	assert.True(t, os.IsNotExist(err))
+
+	var out bytes.Buffer
+	assert.NoError(t, report.Print(&out))
+	assert.Contains(t, out.String(), "dry run: 1 created, 1 modified, 1 deleted, 1 unchanged, 1 skipped")
+	assert.Contains(t, out.String(), "-package main\n+package biz\n")
+	assert.Contains(t, out.String(), "--- a/deleted.go\n+++ /dev/null\n")
+}
+
+func TestRunSkipsNotFound(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0o644))
+
+	// the generator fails without its untouched files, the other changes are reported all the same
+	report, err := RunIn(dir, func() error {
+		if _, err := os.Stat("main.go"); err != nil {
+			return err
+		}
+		return os.WriteFile("created.go", []byte("package main\n"), 0o644)
+	})
+	assert.NoError(t, err)
+	assert.Len(t, report.Changes, 1)
+	assert.Equal(t, Created, report.Changes[0].Status)
+}
+
+func TestRunInModuleContext(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "app", "user")
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "vendor", "x"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/demo\n"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "vendor", "x", "x.go"), []byte("package x\n"), 0o644))
+
+	cwd, err := os.Getwd()
+	assert.NoError(t, err)
+	report, err := RunIn(dir, func() error {
+		// the parent go.mod is found at the same place relative to the copy
+		content, err := os.ReadFile(filepath.Join("..", "..", "go.mod"))
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, "module example.com/demo\n", string(content))
+		_, err = os.Stat("vendor")
+		assert.True(t, os.IsNotExist(err))
+		return os.WriteFile(filepath.Join("..", "..", "go.mod"), []byte("module example.com/demo\n\ngo 1.21\n"), 0o644)
+	})
+	assert.NoError(t, err)
+
+	now, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.Equal(t, cwd, now)
+	assert.Len(t, report.Changes, 1)
+	assert.Equal(t, "../../go.mod", report.Changes[0].Path)
+	assert.Equal(t, Modified, report.Changes[0].Status)
+
+	content, err := os.ReadFile(filepath.Join(root, "go.mod"))
+	assert.NoError(t, err)
+	assert.Equal(t, "module example.com/demo\n", string(content))
+}
+
+func TestRunInGOPATH(t *testing.T) {
+	gopath := t.TempDir()
+	dir := filepath.Join(gopath, "src", "example.com", "demo")
+	assert.NoError(t, os.MkdirAll(dir, 0o755))
+	t.Setenv("GOPATH", gopath)
+
+	_, err := RunIn(dir, func() error {
+		// the copy has the same package path under the scratch GOPATH
+		cwd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		sandbox := os.Getenv("GOPATH")
+		assert.NotEqual(t, gopath, sandbox)
+		assert.Equal(t, filepath.Join(sandbox, "src", "example.com", "demo"), cwd)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, gopath, os.Getenv("GOPATH"))
+}
@@ -0,0 +1,93 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package errs classifies the failures of cwgo, cmd/static maps every kind to a stable exit code.
+package errs
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Kind int
+
+const (
+	// Unknown is any failure not classified below.
+	Unknown Kind = iota
+	// InvalidArgs means the flags or the manifest are invalid.
+	InvalidArgs
+	// MissingTool means a required executable such as go, git, thriftgo or protoc is not available.
+	MissingTool
+	// IDLParse means the IDL could not be parsed or compiled into code.
+	IDLParse
+	// Conflict means the generation conflicts with the existing project, e.g. another module name in go.mod.
+	Conflict
+	// PostProcess means the code was generated but a following step failed, e.g. persisting the hz manifest.
+	PostProcess
+)
+
+func (k Kind) String() string {
+	switch k {
+	case InvalidArgs:
+		return "invalid arguments"
+	case MissingTool:
+		return "missing tool"
+	case IDLParse:
+		return "idl parse error"
+	case Conflict:
+		return "generation conflict"
+	case PostProcess:
+		return "post-process failure"
+	default:
+		return "unknown error"
+	}
+}
+
+// Error is an error of a known kind.
+type Error struct {
+	Kind Kind
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// New formats an error of the given kind, %w is supported.
+func New(kind Kind, format string, a ...interface{}) error {
+	return &Error{Kind: kind, Err: fmt.Errorf(format, a...)}
+}
+
+// Wrap sets the kind of err, errors already classified keep their kind.
+func Wrap(kind Kind, err error) error {
+	if err == nil || KindOf(err) != Unknown {
+		return err
+	}
+	return &Error{Kind: kind, Err: err}
+}
+
+// KindOf returns the kind of the first classified error in the chain of err.
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	return Unknown
+}
@@ -0,0 +1,40 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKindOf(t *testing.T) {
+	base := errors.New("boom")
+	err := New(IDLParse, "parse idl failed: %w", base)
+	assert.Equal(t, IDLParse, KindOf(err))
+	assert.True(t, errors.Is(err, base))
+
+	wrapped := fmt.Errorf("generate server failed: %w", err)
+	assert.Equal(t, IDLParse, KindOf(wrapped))
+	assert.Equal(t, IDLParse, KindOf(Wrap(InvalidArgs, wrapped)))
+
+	assert.Equal(t, Unknown, KindOf(base))
+	assert.Equal(t, InvalidArgs, KindOf(Wrap(InvalidArgs, base)))
+	assert.Nil(t, Wrap(InvalidArgs, nil))
+}
@@ -0,0 +1,360 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package governance
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/cloudwego/cwgo/pkg/common/utils"
+	kargs "github.com/cloudwego/kitex/tool/cmd/kitex/args"
+	"github.com/cloudwego/thriftgo/parser"
+)
+
+// The method annotations of the IDL read by Parse, for example:
+//
+//	Resp GetUser(1: Req req) (rpc.timeout="200ms", rpc.retry_times="2", rpc.circuit_breaker="0.5", rpc.fallback="true")
+const (
+	// TimeoutAnnotation is the RPC timeout of the method, a duration such as "200ms".
+	TimeoutAnnotation = "rpc.timeout"
+	// RetryTimesAnnotation is the max retry times of the failed calls of the method.
+	RetryTimesAnnotation = "rpc.retry_times"
+	// CircuitBreakerAnnotation is the error rate in (0, 1] opening the circuit breaker of the method.
+	CircuitBreakerAnnotation = "rpc.circuit_breaker"
+	// FallbackAnnotation hands the timed out or broken calls of the method to the fallback of the client.
+	FallbackAnnotation = "rpc.fallback"
+)
+
+// Feature is the name of the kitex template feature enabled when a method is governed,
+// templates query it with {{if HasFeature .Features "client_governance"}}.
+const Feature = "client_governance"
+
+// FileSuffix is appended to the package name to make the name of the generated governance file.
+const FileSuffix = "_governance.go"
+
+// Method is the governance of a method read from its annotations.
+type Method struct {
+	// Name is the method name in the IDL, which is the one kitex sees in the RPC info.
+	Name           string
+	Timeout        time.Duration
+	RetryTimes     int
+	CircuitBreaker float64
+	Fallback       bool
+}
+
+// Parse returns the governance of the annotated methods of the last service defined in the thrift IDL,
+// which is the service kitex generates the client of. The methods of the extended services are included.
+func Parse(idl string, includeDirs []string) ([]*Method, error) {
+	ast, err := parser.ParseFile(idl, includeDirs, true)
+	if err != nil {
+		return nil, err
+	}
+	if len(ast.Services) == 0 {
+		return nil, nil
+	}
+
+	var methods []*Method
+	svc, file := ast.Services[len(ast.Services)-1], ast
+	for svc != nil {
+		for _, f := range svc.Functions {
+			m, err := parseMethod(f)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", svc.Name, f.Name, err)
+			}
+			if m != nil {
+				methods = append(methods, m)
+			}
+		}
+		svc, file = baseService(file, svc.Extends)
+	}
+	return methods, nil
+}
+
+// ProtoAnnotated reports whether the proto IDL sets any of the annotations as method options,
+// the governance is read from thrift IDLs only and such options would be dropped silently.
+func ProtoAnnotated(idl string) (bool, error) {
+	content, err := os.ReadFile(idl)
+	if err != nil {
+		return false, err
+	}
+	for _, a := range []string{TimeoutAnnotation, RetryTimesAnnotation, CircuitBreakerAnnotation, FallbackAnnotation} {
+		if bytes.Contains(content, []byte("("+a+")")) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// baseService looks up the service extended by the name, it is nil when there is none.
+func baseService(file *parser.Thrift, extends string) (*parser.Service, *parser.Thrift) {
+	if extends == "" {
+		return nil, nil
+	}
+	if idx := strings.LastIndex(extends, "."); idx >= 0 {
+		ref, ok := file.GetReference(extends[:idx])
+		if !ok {
+			return nil, nil
+		}
+		file, extends = ref, extends[idx+1:]
+	}
+	svc, ok := file.GetService(extends)
+	if !ok {
+		return nil, nil
+	}
+	return svc, file
+}
+
+// parseMethod returns nil when the function is not annotated.
+func parseMethod(f *parser.Function) (*Method, error) {
+	m := &Method{Name: f.Name}
+	governed := false
+	for _, a := range f.Annotations {
+		if len(a.Values) == 0 {
+			continue
+		}
+		value := a.Values[len(a.Values)-1]
+		var err error
+		switch a.Key {
+		case TimeoutAnnotation:
+			m.Timeout, err = time.ParseDuration(value)
+			if err == nil && m.Timeout <= 0 {
+				err = fmt.Errorf("not positive")
+			}
+		case RetryTimesAnnotation:
+			m.RetryTimes, err = strconv.Atoi(value)
+			if err == nil && m.RetryTimes < 0 {
+				err = fmt.Errorf("negative")
+			}
+		case CircuitBreakerAnnotation:
+			m.CircuitBreaker, err = strconv.ParseFloat(value, 64)
+			if err == nil && (m.CircuitBreaker <= 0 || m.CircuitBreaker > 1) {
+				err = fmt.Errorf("not in (0, 1]")
+			}
+		case FallbackAnnotation:
+			m.Fallback, err = strconv.ParseBool(value)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %s", a.Key, value, err)
+		}
+		governed = true
+	}
+	if !governed {
+		return nil, nil
+	}
+	return m, nil
+}
+
+// HandleKitex enables Feature in a copy of the template extension of args when a method is governed,
+// see utils.EnableKitexFeatures. The returned path is empty when no method is governed.
+func HandleKitex(methods []*Method, args *kargs.Arguments) (string, error) {
+	if len(methods) == 0 {
+		return "", nil
+	}
+	return utils.EnableKitexFeatures(args, Feature)
+}
+
+// RemoveExtension removes the extension file written by HandleKitex.
+func RemoveExtension(path string) {
+	if path == "" {
+		return
+	}
+	os.Remove(path)
+}
+
+// Generate writes the governance file of the methods into the dir of the client package,
+// the governance of the client in conf overrides the annotations.
+func Generate(dir, pkg, module string, methods []*Method) error {
+	if len(methods) == 0 {
+		return nil
+	}
+	tmpl, err := template.New(FileSuffix).Parse(governanceTpl)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	buf := new(bytes.Buffer)
+	err = tmpl.Execute(buf, map[string]interface{}{
+		"Package": pkg,
+		"Module":  module,
+		"Methods": methods,
+	})
+	if err != nil {
+		return err
+	}
+	content, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, pkg+FileSuffix), content, 0o644)
+}
+
+const governanceTpl = `// Code generated by cwgo from the method annotations of the IDL. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"context"
+	"time"
+
+	"{{.Module}}/conf"
+	"github.com/cloudwego/kitex/client"
+	"github.com/cloudwego/kitex/pkg/circuitbreak"
+	"github.com/cloudwego/kitex/pkg/fallback"
+	"github.com/cloudwego/kitex/pkg/klog"
+	"github.com/cloudwego/kitex/pkg/retry"
+	"github.com/cloudwego/kitex/pkg/rpcinfo"
+	"github.com/cloudwego/kitex/pkg/utils"
+)
+
+// Fallback is called with the calls of the methods annotated with rpc.fallback that time out or
+// are rejected by the circuit breaker, the response it returns is handed to the caller in place of the error.
+var Fallback fallback.RealReqRespFunc
+
+type methodGovernance struct {
+	timeout        string
+	retryTimes     int
+	circuitBreaker float64
+	fallback       bool
+}
+
+// methodGovernances are read from the annotations, governance.{{.Package}}.<method> in conf overrides them.
+var methodGovernances = map[string]methodGovernance{
+{{- range .Methods}}
+	"{{.Name}}": { {{- if .Timeout}}timeout: "{{.Timeout}}", {{end}}
+		{{- if .RetryTimes}}retryTimes: {{.RetryTimes}}, {{end}}
+		{{- if .CircuitBreaker}}circuitBreaker: {{.CircuitBreaker}}, {{end}}
+		{{- if .Fallback}}fallback: true{{end}}},
+{{- end}}
+}
+
+// governances merges the governance of the client in conf into the annotations.
+func governances() map[string]methodGovernance {
+	gs := make(map[string]methodGovernance, len(methodGovernances))
+	for method, g := range methodGovernances {
+		gs[method] = g
+	}
+	for method, o := range conf.GetConf().Governance["{{.Package}}"] {
+		g := gs[method]
+		if o.Timeout != "" {
+			g.timeout = o.Timeout
+		}
+		if o.RetryTimes != nil {
+			g.retryTimes = *o.RetryTimes
+		}
+		if o.CircuitBreaker != nil {
+			g.circuitBreaker = *o.CircuitBreaker
+		}
+		if o.Fallback != nil {
+			g.fallback = *o.Fallback
+		}
+		gs[method] = g
+	}
+	return gs
+}
+
+// governanceOptions builds the timeouts, retry policies, circuit breakers and fallback of the methods.
+func governanceOptions() (opts []client.Option) {
+	timeouts := make(methodTimeouts)
+	retries := make(map[string]retry.Policy)
+	breakers := make(map[string]circuitbreak.CBConfig)
+	fallbacks := make(map[string]bool)
+	for method, g := range governances() {
+		if g.timeout != "" {
+			d, err := time.ParseDuration(g.timeout)
+			if err != nil {
+				klog.Warnf("invalid timeout %q of method %s: %s", g.timeout, method, err)
+			} else {
+				timeouts[method] = d
+			}
+		}
+		if g.retryTimes > 0 {
+			fp := retry.NewFailurePolicy()
+			fp.WithMaxRetryTimes(g.retryTimes)
+			retries[method] = retry.BuildFailurePolicy(fp)
+		}
+		if g.circuitBreaker > 0 {
+			breakers[method] = circuitbreak.CBConfig{Enable: true, ErrRate: g.circuitBreaker, MinSample: circuitbreak.GetDefaultCBConfig().MinSample}
+		}
+		if g.fallback {
+			fallbacks[method] = true
+		}
+	}
+
+	if len(timeouts) > 0 {
+		opts = append(opts, client.WithTimeoutProvider(timeouts))
+	}
+	if len(retries) > 0 {
+		opts = append(opts, client.WithRetryMethodPolicies(retries))
+	}
+	if len(breakers) > 0 {
+		// the methods without a breaker share the disabled breaker of the empty key
+		cbs := circuitbreak.NewCBSuite(func(ri rpcinfo.RPCInfo) string {
+			if method := ri.Invocation().MethodName(); breakers[method].Enable {
+				return method
+			}
+			return ""
+		})
+		cbs.UpdateServiceCBConfig("", circuitbreak.CBConfig{})
+		cbs.UpdateInstanceCBConfig(circuitbreak.CBConfig{})
+		for method, cfg := range breakers {
+			cbs.UpdateServiceCBConfig(method, cfg)
+		}
+		opts = append(opts, client.WithCircuitBreaker(cbs))
+	}
+	if len(fallbacks) > 0 {
+		opts = append(opts, client.WithFallback(fallback.TimeoutAndCBFallback(
+			func(ctx context.Context, args utils.KitexArgs, result utils.KitexResult, err error) error {
+				if Fallback == nil || !fallbacks[rpcinfo.GetRPCInfo(ctx).Invocation().MethodName()] {
+					return err
+				}
+				return fallback.UnwrapHelper(Fallback)(ctx, args, result, err)
+			})))
+	}
+	return opts
+}
+
+// methodTimeouts provides the RPC timeouts of the methods, the other timeouts are kept.
+type methodTimeouts map[string]time.Duration
+
+func (t methodTimeouts) Timeouts(ri rpcinfo.RPCInfo) rpcinfo.Timeouts {
+	d, ok := t[ri.Invocation().MethodName()]
+	if !ok {
+		return nil
+	}
+	return rpcTimeout{Timeouts: ri.Config(), timeout: d}
+}
+
+type rpcTimeout struct {
+	rpcinfo.Timeouts
+	timeout time.Duration
+}
+
+func (t rpcTimeout) RPCTimeout() time.Duration {
+	return t.timeout
+}
+`
@@ -0,0 +1,124 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package governance
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeIDL(t *testing.T, dir, name, content string) string {
+	p := filepath.Join(dir, name)
+	assert.NoError(t, os.WriteFile(p, []byte(content), 0o644))
+	return p
+}
+
+func TestParse(t *testing.T) {
+	dir := t.TempDir()
+	writeIDL(t, dir, "base.thrift", `
+service Base {
+	void Ping() (rpc.timeout="1s")
+}
+`)
+	idl := writeIDL(t, dir, "demo.thrift", `
+include "base.thrift"
+
+struct Req {}
+struct Resp {}
+
+service Other {
+	Resp Ignored(1: Req req) (rpc.timeout="5s")
+}
+
+service Demo extends base.Base {
+	Resp Hello(1: Req req) (rpc.timeout="200ms", rpc.retry_times="2", rpc.circuit_breaker="0.5", rpc.fallback="true")
+	Resp Plain(1: Req req) (api.get="/plain")
+}
+`)
+
+	methods, err := Parse(idl, nil)
+	assert.NoError(t, err)
+	// only the last service and the services it extends are read, the methods without governance are left out
+	assert.Equal(t, []*Method{
+		{Name: "Hello", Timeout: 200 * time.Millisecond, RetryTimes: 2, CircuitBreaker: 0.5, Fallback: true},
+		{Name: "Ping", Timeout: time.Second},
+	}, methods)
+}
+
+func TestParseInvalid(t *testing.T) {
+	dir := t.TempDir()
+	for _, annotation := range []string{
+		`rpc.timeout="fast"`,
+		`rpc.timeout="-1s"`,
+		`rpc.retry_times="-1"`,
+		`rpc.circuit_breaker="1.5"`,
+		`rpc.fallback="maybe"`,
+	} {
+		idl := writeIDL(t, dir, "demo.thrift", "service Demo {\n\tvoid Hello() ("+annotation+")\n}\n")
+		_, err := Parse(idl, nil)
+		assert.ErrorContains(t, err, "Demo.Hello: invalid", annotation)
+	}
+}
+
+func TestProtoAnnotated(t *testing.T) {
+	dir := t.TempDir()
+	annotated, err := ProtoAnnotated(writeIDL(t, dir, "plain.proto", `
+syntax = "proto3";
+service Demo {
+	rpc Echo(Req) returns (Resp);
+}
+`))
+	assert.NoError(t, err)
+	assert.False(t, annotated)
+
+	annotated, err = ProtoAnnotated(writeIDL(t, dir, "governed.proto", `
+syntax = "proto3";
+service Demo {
+	rpc Echo(Req) returns (Resp) {
+		option (rpc.timeout) = "200ms";
+	}
+}
+`))
+	assert.NoError(t, err)
+	assert.True(t, annotated)
+
+	_, err = ProtoAnnotated(filepath.Join(dir, "missing.proto"))
+	assert.Error(t, err)
+}
+
+func TestGenerate(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, Generate(dir, "demo", "example.com/demo", nil))
+	_, err := os.Stat(filepath.Join(dir, "demo"+FileSuffix))
+	assert.True(t, os.IsNotExist(err))
+
+	methods := []*Method{
+		{Name: "Hello", Timeout: 200 * time.Millisecond, RetryTimes: 2, CircuitBreaker: 0.5, Fallback: true},
+		{Name: "Ping", Timeout: time.Second},
+	}
+	assert.NoError(t, Generate(dir, "demo", "example.com/demo", methods))
+	content, err := os.ReadFile(filepath.Join(dir, "demo"+FileSuffix))
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), `"example.com/demo/conf"`)
+	assert.Contains(t, string(content), `"Hello": {timeout: "200ms", retryTimes: 2, circuitBreaker: 0.5, fallback: true},`)
+	assert.Contains(t, string(content), `"Ping":  {timeout: "1s"},`)
+	assert.Contains(t, string(content), `conf.GetConf().Governance["demo"]`)
+}
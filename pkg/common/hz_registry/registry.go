@@ -0,0 +1,385 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hz_registry
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/cwgo/pkg/common/errs"
+	"github.com/cloudwego/cwgo/pkg/common/observability"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/cloudwego/cwgo/tpl"
+)
+
+// Delims are the delimiters of the registry directives in hz layout and package templates,
+// they differ from the ones of hz so that the templates are rendered by cwgo first.
+var Delims = [2]string{"[[", "]]"}
+
+// Registries lists the supported registries.
+var Registries = []string{consts.Zk, consts.Nacos, consts.Etcd, consts.Polaris, consts.Consul, consts.Eureka, consts.Kubernetes}
+
+// Data is the data of the registry directives, templates query it with [[if .NewRegistry]].
+type Data struct {
+	// Registry is the registry in lower case, it is empty when no registry is used.
+	Registry string
+	// Imports are the imports needed by NewRegistry in main.go.
+	Imports []string
+	// ClientImports are the imports needed by NewResolver in the client package.
+	ClientImports []string
+	// NewRegistry are the statements of newRegistry in main.go defining r as a registry.Registry,
+	// it is empty when the servers are not registered by the service itself.
+	NewRegistry string
+	// NewResolver is the body of newResolver in the client package returning a discovery.Resolver.
+	NewResolver string
+	// Address is the default registry_address in conf.yaml, it is empty when conf.yaml
+	// does not configure the registry.
+	Address string
+	// Host is the default host of the generated client.
+	Host string
+	// GoModule is the module of the conf package.
+	GoModule string
+	// ConfigCenter is the config center of the conf package in lower case, it is only
+	// set for server layouts, see config_center.Name.
+	ConfigCenter string
+	// Otel and Prometheus report whether the observability integrations are wired.
+	Otel       bool
+	Prometheus bool
+	// Vars are the user defined template variables read with TplVar.
+	Vars map[string]string
+	// HexImpls maps the services of a hex server to the kitex implementation serving their methods,
+	// it is only set for the package template of hex.
+	HexImpls map[string]string
+}
+
+type backend struct {
+	imports []string
+	// clientImports are the imports needed by the resolver besides imports
+	clientImports []string
+	registry      string
+	resolver      string
+	address       string
+}
+
+var backends = map[string]backend{
+	consts.Etcd: {
+		imports:  []string{`"github.com/hertz-contrib/registry/etcd"`},
+		registry: etcdServer,
+		resolver: etcdClient,
+		address:  "127.0.0.1:2379",
+	},
+	consts.Zk: {
+		imports:       []string{`"github.com/hertz-contrib/registry/zookeeper"`},
+		clientImports: []string{`"time"`},
+		registry:      zkServer,
+		resolver:      zkClient,
+		address:       "127.0.0.1:2181",
+	},
+	consts.Nacos: {
+		imports: []string{
+			`"strconv"`,
+			`nacos "github.com/hertz-contrib/registry/nacos/v2"`,
+			`"github.com/nacos-group/nacos-sdk-go/v2/clients"`,
+			`"github.com/nacos-group/nacos-sdk-go/v2/common/constant"`,
+			`"github.com/nacos-group/nacos-sdk-go/v2/vo"`,
+		},
+		clientImports: []string{`"net"`},
+		registry:      nacosServer,
+		resolver:      nacosClient,
+		address:       "127.0.0.1:8848",
+	},
+	consts.Polaris: {
+		imports:       []string{`"github.com/hertz-contrib/registry/polaris"`},
+		clientImports: []string{`"os"`},
+		registry:      polarisServer,
+		resolver:      polarisClient,
+		address:       "127.0.0.1:8091",
+	},
+	consts.Consul: {
+		imports:  []string{`consulapi "github.com/hashicorp/consul/api"`, `"github.com/hertz-contrib/registry/consul"`},
+		registry: consulServer,
+		resolver: consulClient,
+		address:  "127.0.0.1:8500",
+	},
+	consts.Eureka: {
+		imports:  []string{`"github.com/hertz-contrib/registry/eureka"`},
+		registry: eurekaServer,
+		resolver: eurekaClient,
+		address:  "http://127.0.0.1:8761/eureka",
+	},
+	// the pods are registered by kubernetes itself, clients reach
+	// the service of the destination through the cluster DNS
+	consts.Kubernetes: {},
+}
+
+// NewData returns the data of the registry directives for the common params.
+func NewData(ca *config.CommonParam) (*Data, error) {
+	data := &Data{
+		Host:       "{{.BaseDomain}}",
+		GoModule:   ca.GoMod,
+		Otel:       observability.Has(ca.Observability, consts.Otel),
+		Prometheus: observability.Has(ca.Observability, consts.Prometheus),
+	}
+	if ca.Registry == "" {
+		return data, nil
+	}
+	b, ok := backends[ca.Registry]
+	if !ok {
+		return nil, errs.New(errs.InvalidArgs, "unsupported registry %s (support %s)", ca.Registry, strings.Join(Registries, ", "))
+	}
+	data.Registry = strings.ToLower(ca.Registry)
+	data.Imports = b.imports
+	data.ClientImports = append(append([]string{}, b.imports...), b.clientImports...)
+	data.NewRegistry = b.registry
+	data.NewResolver = b.resolver
+	data.Address = b.address
+	// requests are sent to the service name, the host is
+	// resolved by the registry or the cluster DNS
+	data.Host = "http://" + ca.ServerName
+	return data, nil
+}
+
+// HandleRegistry renders the registry directives of the hz layout or package template tplPath
+// into a copy in the private template root and returns the path of the copy.
+func HandleRegistry(ca *config.CommonParam, tplPath string) (string, error) {
+	data, err := NewData(ca)
+	if err != nil {
+		return "", err
+	}
+	return Render(tplPath, data)
+}
+
+// Render renders the directives of the hz template tplPath with data into a copy
+// in the private template root and returns the path of the copy.
+func Render(tplPath string, data *Data) (string, error) {
+	content, err := os.ReadFile(tplPath)
+	if err != nil {
+		return "", err
+	}
+	t, err := template.New(filepath.Base(tplPath)).Delims(Delims[0], Delims[1]).Funcs(FuncMap).Funcs(tpl.VarFuncs(data.Vars)).Parse(string(content))
+	if err != nil {
+		return "", errs.New(errs.InvalidArgs, "parse directives of %s failed: %s", tplPath, err)
+	}
+	buf := new(bytes.Buffer)
+	if err = t.Execute(buf, data); err != nil {
+		return "", errs.New(errs.InvalidArgs, "render directives of %s failed: %s", tplPath, err)
+	}
+
+	f, err := os.CreateTemp(tpl.HertzDir, "*-"+filepath.Base(tplPath))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err = f.Write(buf.Bytes()); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// FuncMap are the functions of the registry directives.
+var FuncMap = template.FuncMap{
+	// indent puts each line of the code on a new line indented by n spaces,
+	// so that the code stays inside the yaml block of the template body.
+	"indent": func(n int, code string) string {
+		var sb strings.Builder
+		for _, line := range strings.Split(strings.Trim(code, "\n"), "\n") {
+			sb.WriteString("\n")
+			if line != "" {
+				sb.WriteString(strings.Repeat(" ", n) + line)
+			}
+		}
+		return sb.String()
+	},
+}
+
+func init() {
+	// TplVar and TplVars read the user defined template variables, Render replaces them
+	// by the ones reading Data.Vars.
+	for k, f := range tpl.VarFuncs(nil) {
+		FuncMap[k] = f
+	}
+}
+
+// RemoveTemplate removes the template copy written by HandleRegistry.
+func RemoveTemplate(path string) {
+	if path == "" {
+		return
+	}
+	os.Remove(path)
+}
+
+const etcdServer = `
+	r, err := etcd.NewEtcdRegistry(conf.GetConf().Registry.RegistryAddress)
+	if err != nil {
+		hlog.Fatal(err)
+	}
+`
+
+const etcdClient = `
+	return etcd.NewEtcdResolver(conf.GetConf().Registry.RegistryAddress)
+`
+
+const zkServer = `
+	r, err := zookeeper.NewZookeeperRegistryWithAuth(conf.GetConf().Registry.RegistryAddress, 30*time.Second, conf.GetConf().Registry.Username, conf.GetConf().Registry.Password)
+	if err != nil {
+		hlog.Fatal(err)
+	}
+`
+
+const zkClient = `
+	return zookeeper.NewZookeeperResolverWithAuth(conf.GetConf().Registry.RegistryAddress, 30*time.Second, conf.GetConf().Registry.Username, conf.GetConf().Registry.Password)
+`
+
+const nacosServer = `
+	var servers []constant.ServerConfig
+	for _, addr := range conf.GetConf().Registry.RegistryAddress {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			hlog.Fatal(err)
+		}
+		p, err := strconv.ParseUint(port, 10, 64)
+		if err != nil {
+			hlog.Fatal(err)
+		}
+		servers = append(servers, *constant.NewServerConfig(host, p))
+	}
+	client, err := clients.NewNamingClient(vo.NacosClientParam{
+		ClientConfig: constant.NewClientConfig(
+			constant.WithUsername(conf.GetConf().Registry.Username),
+			constant.WithPassword(conf.GetConf().Registry.Password),
+			constant.WithNotLoadCacheAtStart(true),
+		),
+		ServerConfigs: servers,
+	})
+	if err != nil {
+		hlog.Fatal(err)
+	}
+	r := nacos.NewNacosRegistry(client)
+`
+
+const nacosClient = `
+	var servers []constant.ServerConfig
+	for _, addr := range conf.GetConf().Registry.RegistryAddress {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		p, err := strconv.ParseUint(port, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		servers = append(servers, *constant.NewServerConfig(host, p))
+	}
+	client, err := clients.NewNamingClient(vo.NacosClientParam{
+		ClientConfig: constant.NewClientConfig(
+			constant.WithUsername(conf.GetConf().Registry.Username),
+			constant.WithPassword(conf.GetConf().Registry.Password),
+			constant.WithNotLoadCacheAtStart(true),
+		),
+		ServerConfigs: servers,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return nacos.NewNacosResolver(client), nil
+`
+
+// polaris reads its servers from a config file, it is written from the addresses in conf.yaml
+const polarisServer = `
+	var polarisConf []string
+	if addrs := conf.GetConf().Registry.RegistryAddress; len(addrs) > 0 {
+		f, err := os.CreateTemp("", "polaris-*.yaml")
+		if err != nil {
+			hlog.Fatal(err)
+		}
+		defer os.Remove(f.Name())
+		content := "global:\n  serverConnector:\n    addresses:\n"
+		for _, addr := range addrs {
+			content += "      - " + addr + "\n"
+		}
+		_, err = f.WriteString(content)
+		f.Close()
+		if err != nil {
+			hlog.Fatal(err)
+		}
+		polarisConf = append(polarisConf, f.Name())
+	}
+	r, err := polaris.NewPolarisRegistry(polarisConf...)
+	if err != nil {
+		hlog.Fatal(err)
+	}
+`
+
+const polarisClient = `
+	var polarisConf []string
+	if addrs := conf.GetConf().Registry.RegistryAddress; len(addrs) > 0 {
+		f, err := os.CreateTemp("", "polaris-*.yaml")
+		if err != nil {
+			return nil, err
+		}
+		defer os.Remove(f.Name())
+		content := "global:\n  serverConnector:\n    addresses:\n"
+		for _, addr := range addrs {
+			content += "      - " + addr + "\n"
+		}
+		_, err = f.WriteString(content)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		polarisConf = append(polarisConf, f.Name())
+	}
+	return polaris.NewPolarisResolver(polarisConf...)
+`
+
+// consul takes a single address, the default agent address is used when conf.yaml has none
+const consulServer = `
+	consulConfig := consulapi.DefaultConfig()
+	if addrs := conf.GetConf().Registry.RegistryAddress; len(addrs) > 0 {
+		consulConfig.Address = addrs[0]
+	}
+	consulClient, err := consulapi.NewClient(consulConfig)
+	if err != nil {
+		hlog.Fatal(err)
+	}
+	r := consul.NewConsulRegister(consulClient)
+`
+
+const consulClient = `
+	consulConfig := consulapi.DefaultConfig()
+	if addrs := conf.GetConf().Registry.RegistryAddress; len(addrs) > 0 {
+		consulConfig.Address = addrs[0]
+	}
+	consulClient, err := consulapi.NewClient(consulConfig)
+	if err != nil {
+		return nil, err
+	}
+	return consul.NewConsulResolver(consulClient), nil
+`
+
+const eurekaServer = `
+	r := eureka.NewEurekaRegistry(conf.GetConf().Registry.RegistryAddress, 15*time.Second)
+`
+
+const eurekaClient = `
+	return eureka.NewEurekaResolver(conf.GetConf().Registry.RegistryAddress), nil
+`
@@ -0,0 +1,174 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hz_registry
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/cloudwego/cwgo/tpl"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+type layouts struct {
+	Layouts []struct {
+		Path string `yaml:"path"`
+		Body string `yaml:"body"`
+	} `yaml:"layouts"`
+}
+
+func render(t *testing.T, registry, tplPath string) map[string]string {
+	p, err := HandleRegistry(&config.CommonParam{ServerName: "demo", GoMod: "demo", Registry: registry}, tplPath)
+	assert.NoError(t, err)
+	defer func() {
+		RemoveTemplate(p)
+		_, err = os.Stat(p)
+		assert.True(t, os.IsNotExist(err))
+	}()
+
+	content, err := os.ReadFile(p)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(content), Delims[0])
+	l := new(layouts)
+	assert.NoError(t, yaml.Unmarshal(content, l))
+	bodies := make(map[string]string, len(l.Layouts))
+	for _, layout := range l.Layouts {
+		bodies[layout.Path] = layout.Body
+	}
+	return bodies
+}
+
+func TestHandleRegistryServer(t *testing.T) {
+	assert.NoError(t, tpl.Init())
+	defer tpl.Cleanup()
+
+	for _, dir := range []string{consts.Standard, consts.StandardV2} {
+		layout := path.Join(tpl.HertzDir, consts.Server, dir, consts.LayoutFile)
+
+		bodies := render(t, "", layout)
+		assert.Contains(t, bodies["main.go"], "server.New(server.WithHostPorts(address), server.WithExitWaitTime(conf.ExitWaitTime()))\n")
+		assert.NotContains(t, bodies["conf/conf.go"], "Registry")
+		assert.NotContains(t, bodies["conf/test/conf.yaml"], "registry")
+
+		bodies = render(t, consts.Etcd, layout)
+		assert.Contains(t, bodies["main.go"], "server.WithRegistry(newRegistry(address))")
+		assert.Contains(t, bodies["main.go"], "\n\tr, err := etcd.NewEtcdRegistry(conf.GetConf().Registry.RegistryAddress)\n")
+		assert.Contains(t, bodies["main.go"], `"github.com/hertz-contrib/registry/etcd"`)
+		assert.Contains(t, bodies["conf/conf.go"], "Registry Registry `yaml:\"registry\"`")
+		assert.Contains(t, bodies["conf/dev/conf.yaml"], `- "127.0.0.1:2379"`)
+
+		bodies = render(t, consts.Consul, layout)
+		assert.Contains(t, bodies["docker-compose.yaml"], "hashicorp/consul")
+		assert.Contains(t, bodies["main.go"], "if addrs := conf.GetConf().Registry.RegistryAddress; len(addrs) > 0 {")
+
+		// nacos and polaris are configured by conf.yaml as the other registries
+		bodies = render(t, consts.Nacos, layout)
+		assert.Contains(t, bodies["main.go"], "range conf.GetConf().Registry.RegistryAddress")
+		assert.Contains(t, bodies["main.go"], "constant.WithUsername(conf.GetConf().Registry.Username)")
+		assert.Contains(t, bodies["main.go"], "r := nacos.NewNacosRegistry(client)")
+		assert.Contains(t, bodies["conf/dev/conf.yaml"], `- "127.0.0.1:8848"`)
+
+		bodies = render(t, consts.Polaris, layout)
+		assert.Contains(t, bodies["main.go"], "polaris.NewPolarisRegistry(polarisConf...)")
+		assert.Contains(t, bodies["conf/dev/conf.yaml"], `- "127.0.0.1:8091"`)
+
+		p, err := Render(layout, &Data{ConfigCenter: "file"})
+		assert.NoError(t, err)
+		content, err := os.ReadFile(p)
+		assert.NoError(t, err)
+		RemoveTemplate(p)
+		assert.Contains(t, string(content), "func OnChange(f func(old, new *Config))")
+		assert.Contains(t, string(content), "watchCenter(confFileRelPath, conf.ConfigCenter)")
+		assert.NotContains(t, bodies["conf/conf.go"], "watchCenter")
+
+		p, err = HandleRegistry(&config.CommonParam{ServerName: "demo", GoMod: "demo", Observability: []string{consts.Otel, consts.Prometheus}}, layout)
+		assert.NoError(t, err)
+		content, err = os.ReadFile(p)
+		assert.NoError(t, err)
+		RemoveTemplate(p)
+		assert.Contains(t, string(content), "server.New(server.WithHostPorts(address), server.WithExitWaitTime(conf.ExitWaitTime()), tracer, newPrometheusTracer())")
+		assert.Contains(t, string(content), "h.Use(hertztracing.ServerMiddleware(cfg))")
+		assert.Contains(t, string(content), "Prometheus Prometheus `yaml:\"prometheus\"`")
+		assert.Contains(t, string(content), "jaegertracing/all-in-one")
+		assert.NotContains(t, bodies["main.go"], "hertztracing")
+
+		// kubernetes registers the pods itself
+		bodies = render(t, consts.Kubernetes, layout)
+		assert.NotContains(t, bodies["main.go"], "newRegistry")
+		assert.NotContains(t, bodies["conf/conf.go"], "Registry")
+	}
+}
+
+func TestHandleRegistryClient(t *testing.T) {
+	assert.NoError(t, tpl.Init())
+	defer tpl.Cleanup()
+
+	pkg := path.Join(tpl.HertzDir, consts.Client, consts.Standard, consts.PackageLayoutFile)
+
+	bodies := render(t, "", pkg)
+	assert.Contains(t, bodies["idl_client.go"], `Client("{{.BaseDomain}}")`)
+	assert.NotContains(t, bodies["hertz_client.go"], "WithDiscovery")
+	assert.NotContains(t, bodies["hertz_client.go"], "hertztracing")
+
+	p, err := HandleRegistry(&config.CommonParam{ServerName: "demo", GoMod: "demo", Observability: []string{consts.Otel}}, pkg)
+	assert.NoError(t, err)
+	content, err := os.ReadFile(p)
+	assert.NoError(t, err)
+	RemoveTemplate(p)
+	assert.Contains(t, string(content), "opts.middlewares = append(opts.middlewares, hertztracing.ClientMiddleware())")
+
+	for _, registry := range []string{consts.Etcd, consts.Zk, consts.Nacos, consts.Polaris, consts.Consul, consts.Eureka} {
+		bodies = render(t, registry, pkg)
+		assert.Contains(t, bodies["idl_client.go"], `Client("http://demo", WithDiscovery())`)
+		assert.Contains(t, bodies["hertz_client.go"], "sd.Discovery(r)")
+		assert.Equal(t, backends[registry].address != "", strings.Contains(bodies["hertz_client.go"], `"demo/conf"`))
+	}
+
+	bodies = render(t, consts.Kubernetes, pkg)
+	assert.Contains(t, bodies["idl_client.go"], `Client("http://demo")`)
+	assert.NotContains(t, bodies["hertz_client.go"], "WithDiscovery")
+}
+
+func TestHandleRegistryUnsupported(t *testing.T) {
+	assert.NoError(t, tpl.Init())
+	defer tpl.Cleanup()
+
+	_, err := HandleRegistry(&config.CommonParam{Registry: "MDNS"}, path.Join(tpl.HertzDir, consts.Client, consts.Standard, consts.PackageLayoutFile))
+	assert.Error(t, err)
+}
+
+func TestRenderTemplateVars(t *testing.T) {
+	assert.NoError(t, tpl.Init())
+	defer tpl.Cleanup()
+
+	src := path.Join(t.TempDir(), consts.LayoutFile)
+	assert.NoError(t, os.WriteFile(src, []byte(`layouts:
+  - path: OWNERS
+    body: "[[TplVar "owner"]] [[TplVar "psm"]] [[len TplVars]]"
+`), 0o644))
+	p, err := Render(src, &Data{Vars: map[string]string{"owner": "team-a"}})
+	assert.NoError(t, err)
+	defer RemoveTemplate(p)
+	content, err := os.ReadFile(p)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), `body: "team-a  1"`)
+}
@@ -17,15 +17,42 @@ package kx_registry
 import (
 	"fmt"
 	"os"
-	"path"
+	"strings"
 
 	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/cwgo/pkg/common/errs"
 	"github.com/cloudwego/cwgo/pkg/consts"
 	"github.com/cloudwego/cwgo/tpl"
+	kargs "github.com/cloudwego/kitex/tool/cmd/kitex/args"
 	"github.com/cloudwego/kitex/tool/internal_pkg/generator"
 )
 
-func HandleRegistry(ca *config.CommonParam, dir string) {
+// Registries lists the supported registries.
+var Registries = []string{consts.Zk, consts.Nacos, consts.Etcd, consts.Polaris, consts.Consul, consts.Eureka, consts.Kubernetes}
+
+// IsSupported reports whether the registry is supported, an empty registry means none.
+func IsSupported(registry string) bool {
+	if registry == "" {
+		return true
+	}
+	for _, r := range Registries {
+		if r == registry {
+			return true
+		}
+	}
+	return false
+}
+
+// Feature is the name of the kitex template feature enabled for the registry,
+// templates query it with {{if HasFeature .Features "registry_consul"}}.
+func Feature(registry string) string {
+	return "registry_" + strings.ToLower(registry)
+}
+
+// HandleRegistry writes the template extension of the registry into the private template root
+// and points args.ExtensionFile to it, the extension file given by -template-extension is merged.
+// It returns the path of the written extension file, which is empty when no registry is used.
+func HandleRegistry(ca *config.CommonParam, args *kargs.Arguments) (string, error) {
 	te := &generator.TemplateExtension{
 		Dependencies: map[string]string{
 			ca.GoMod + "/conf":                       "conf",
@@ -81,18 +108,74 @@ func HandleRegistry(ca *config.CommonParam, dir string) {
 			ImportPaths:  []string{"github.com/cloudwego/kitex/pkg/klog", "github.com/kitex-contrib/registry-nacos/resolver"},
 			ExtendOption: nacosClient,
 		}
+	case consts.Consul:
+		te.Dependencies["github.com/kitex-contrib/registry-consul"] = "consul"
+		te.ExtendServer = &generator.APIExtension{
+			ImportPaths:  append(importPath, "github.com/kitex-contrib/registry-consul", "github.com/cloudwego/kitex/pkg/rpcinfo"),
+			ExtendOption: fmt.Sprintf(consulServer, ca.ServerName),
+		}
+		te.ExtendClient = &generator.APIExtension{
+			ImportPaths:  append(importPath, "github.com/kitex-contrib/registry-consul"),
+			ExtendOption: consulClient,
+		}
+	case consts.Eureka:
+		te.Dependencies["github.com/kitex-contrib/registry-eureka/registry"] = "eurekaregistry"
+		te.Dependencies["github.com/kitex-contrib/registry-eureka/resolver"] = "eurekaresolver"
+		te.Dependencies["time"] = "time"
+		te.ExtendServer = &generator.APIExtension{
+			ImportPaths:  []string{ca.GoMod + "/conf", "github.com/kitex-contrib/registry-eureka/registry", "github.com/cloudwego/kitex/pkg/rpcinfo", "time"},
+			ExtendOption: fmt.Sprintf(eurekaServer, ca.ServerName),
+		}
+		te.ExtendClient = &generator.APIExtension{
+			ImportPaths:  []string{ca.GoMod + "/conf", "github.com/kitex-contrib/registry-eureka/resolver"},
+			ExtendOption: eurekaClient,
+		}
+	case consts.Kubernetes:
+		// the pods are registered by kubernetes itself, clients resolve
+		// the headless service of the destination through the cluster DNS
+		te.Dependencies["github.com/kitex-contrib/resolver-dns"] = "dns"
+		te.Dependencies["fmt"] = "fmt"
+		te.ExtendClient = &generator.APIExtension{
+			ImportPaths:  []string{ca.GoMod + "/conf", "github.com/kitex-contrib/resolver-dns", "fmt"},
+			ExtendOption: kubernetesClient,
+		}
+	case "":
+		return "", nil
 	default:
-		RemoveExtension()
-		return
+		return "", errs.New(errs.InvalidArgs, "unsupported registry %s (support %s)", ca.Registry, strings.Join(Registries, ", "))
+	}
+
+	feature := Feature(ca.Registry)
+	te.FeatureNames = []string{feature}
+	te.EnableFeatures = []string{feature}
+
+	if args.ExtensionFile != "" {
+		userExt := new(generator.TemplateExtension)
+		if err := userExt.FromYAMLFile(args.ExtensionFile); err != nil {
+			return "", fmt.Errorf("read template extension %s failed: %s", args.ExtensionFile, err)
+		}
+		te.Merge(userExt)
 	}
 
-	path := path.Join(dir, consts.KitexExtensionYaml)
-	te.ToYAMLFile(path)
+	f, err := os.CreateTemp(tpl.KitexDir, "*-"+consts.KitexExtensionYaml)
+	if err != nil {
+		return "", err
+	}
+	f.Close()
+	if err = te.ToYAMLFile(f.Name()); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	args.ExtensionFile = f.Name()
+	return f.Name(), nil
 }
 
-func RemoveExtension() {
-	path := tpl.KitexDir + consts.KitexExtensionYaml
-	os.RemoveAll(path)
+// RemoveExtension removes the extension file written by HandleRegistry.
+func RemoveExtension(path string) {
+	if path == "" {
+		return
+	}
+	os.Remove(path)
 }
 
 const etcdServer = `
@@ -174,3 +257,48 @@ const polarisClient = `
 	}
 	options = append(options, client.WithResolver(r))
 `
+
+// consul takes a single address, the default agent address is used when conf.yaml has none
+const consulServer = `
+	consulAddr := "127.0.0.1:8500"
+	if addrs := conf.GetConf().Registry.RegistryAddress; len(addrs) > 0 {
+		consulAddr = addrs[0]
+	}
+	r, err := consul.NewConsulRegister(consulAddr)
+	if err != nil {
+		klog.Fatal(err)
+	}
+	options = append(options, server.WithRegistry(r), server.WithServerBasicInfo(&rpcinfo.EndpointBasicInfo{
+		ServiceName: "%s",
+	}))
+`
+
+const consulClient = `
+	consulAddr := "127.0.0.1:8500"
+	if addrs := conf.GetConf().Registry.RegistryAddress; len(addrs) > 0 {
+		consulAddr = addrs[0]
+	}
+	r, err := consul.NewConsulResolver(consulAddr)
+	if err != nil {
+		klog.Fatal(err)
+	}
+	options = append(options, client.WithResolver(r))
+`
+
+const eurekaServer = `
+	r := eurekaregistry.NewEurekaRegistry(conf.GetConf().Registry.RegistryAddress, 15*time.Second)
+	options = append(options, server.WithRegistry(r), server.WithServerBasicInfo(&rpcinfo.EndpointBasicInfo{
+		ServiceName: "%s",
+	}))
+`
+
+const eurekaClient = `
+	r := eurekaresolver.NewEurekaResolver(conf.GetConf().Registry.RegistryAddress)
+	options = append(options, client.WithResolver(r))
+`
+
+const kubernetesClient = `
+	options = append(options, client.WithResolver(dns.NewDNSResolver()), client.WithDestService(
+		fmt.Sprintf("%s.%s.svc.cluster.local:%d", destService, conf.GetConf().Registry.Namespace, conf.GetConf().Registry.Port),
+	))
+`
@@ -0,0 +1,99 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kx_registry
+
+import (
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/cloudwego/cwgo/tpl"
+	kargs "github.com/cloudwego/kitex/tool/cmd/kitex/args"
+	"github.com/cloudwego/kitex/tool/internal_pkg/generator"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleRegistryConcurrently(t *testing.T) {
+	assert.NoError(t, tpl.Init())
+	defer tpl.Cleanup()
+
+	registries := []string{consts.Etcd, consts.Zk, consts.Nacos, consts.Polaris, consts.Consul, consts.Eureka, consts.Etcd, consts.Nacos}
+	var wg sync.WaitGroup
+	for _, r := range registries {
+		wg.Add(1)
+		go func(registry string) {
+			defer wg.Done()
+			args := &kargs.Arguments{}
+			path, err := HandleRegistry(&config.CommonParam{ServerName: "demo", GoMod: "demo", Registry: registry}, args)
+			assert.NoError(t, err)
+			assert.Equal(t, path, args.ExtensionFile)
+
+			te := new(generator.TemplateExtension)
+			assert.NoError(t, te.FromYAMLFile(path))
+			assert.NotNil(t, te.ExtendServer)
+			assert.NotNil(t, te.ExtendClient)
+			assert.Equal(t, []string{Feature(registry)}, te.EnableFeatures)
+
+			RemoveExtension(path)
+			_, err = os.Stat(path)
+			assert.True(t, os.IsNotExist(err))
+		}(r)
+	}
+	wg.Wait()
+
+	path, err := HandleRegistry(&config.CommonParam{ServerName: "demo", GoMod: "demo"}, &kargs.Arguments{})
+	assert.NoError(t, err)
+	assert.Empty(t, path)
+}
+
+func TestHandleRegistryConsul(t *testing.T) {
+	assert.NoError(t, tpl.Init())
+	defer tpl.Cleanup()
+
+	// an empty registry_address falls back to the default agent instead of panicking at startup
+	path, err := HandleRegistry(&config.CommonParam{ServerName: "demo", GoMod: "demo", Registry: consts.Consul}, &kargs.Arguments{})
+	assert.NoError(t, err)
+	defer RemoveExtension(path)
+	te := new(generator.TemplateExtension)
+	assert.NoError(t, te.FromYAMLFile(path))
+	for _, option := range []string{te.ExtendServer.ExtendOption, te.ExtendClient.ExtendOption} {
+		assert.NotContains(t, option, "RegistryAddress[0]")
+		assert.Contains(t, option, `consulAddr := "127.0.0.1:8500"`)
+		assert.Contains(t, option, "if addrs := conf.GetConf().Registry.RegistryAddress; len(addrs) > 0 {")
+	}
+}
+
+func TestHandleRegistryKubernetes(t *testing.T) {
+	assert.NoError(t, tpl.Init())
+	defer tpl.Cleanup()
+
+	// kubernetes registers the pods itself, only the clients resolve through the cluster DNS
+	path, err := HandleRegistry(&config.CommonParam{ServerName: "demo", GoMod: "demo", Registry: consts.Kubernetes}, &kargs.Arguments{})
+	assert.NoError(t, err)
+	defer RemoveExtension(path)
+	te := new(generator.TemplateExtension)
+	assert.NoError(t, te.FromYAMLFile(path))
+	assert.Nil(t, te.ExtendServer)
+	assert.Contains(t, te.ExtendClient.ExtendOption, "dns.NewDNSResolver()")
+	assert.Equal(t, []string{"registry_kubernetes"}, te.EnableFeatures)
+
+	_, err = HandleRegistry(&config.CommonParam{ServerName: "demo", GoMod: "demo", Registry: "MDNS"}, &kargs.Arguments{})
+	assert.Error(t, err)
+	assert.False(t, IsSupported("MDNS"))
+}
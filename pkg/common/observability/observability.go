@@ -0,0 +1,78 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package observability
+
+import (
+	"os"
+	"strings"
+
+	"github.com/cloudwego/cwgo/pkg/common/utils"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	kargs "github.com/cloudwego/kitex/tool/cmd/kitex/args"
+)
+
+var (
+	// ServerBackends lists the observability integrations supported by servers.
+	ServerBackends = []string{consts.Otel, consts.Prometheus}
+	// ClientBackends lists the observability integrations supported by clients,
+	// prometheus is left out as the metrics endpoint is served by the server of the process.
+	ClientBackends = []string{consts.Otel}
+)
+
+// Unsupported returns the first backend missing from supported, it is empty when all of them are supported.
+func Unsupported(backends, supported []string) string {
+	for _, b := range backends {
+		if !Has(supported, b) {
+			return b
+		}
+	}
+	return ""
+}
+
+// Has reports whether the backend is enabled.
+func Has(backends []string, backend string) bool {
+	for _, b := range backends {
+		if b == backend {
+			return true
+		}
+	}
+	return false
+}
+
+// Feature is the name of the kitex template feature enabled for the backend,
+// templates query it with {{if HasFeature .Features "observability_otel"}}.
+func Feature(backend string) string {
+	return "observability_" + strings.ToLower(backend)
+}
+
+// HandleKitex enables the features of the backends in a copy of the template extension of args,
+// see utils.EnableKitexFeatures. The returned path is empty when no backend is enabled.
+func HandleKitex(backends []string, args *kargs.Arguments) (string, error) {
+	var features []string
+	for _, b := range backends {
+		features = append(features, Feature(b))
+	}
+	return utils.EnableKitexFeatures(args, features...)
+}
+
+// RemoveExtension removes the extension file written by HandleKitex.
+func RemoveExtension(path string) {
+	if path == "" {
+		return
+	}
+	os.Remove(path)
+}
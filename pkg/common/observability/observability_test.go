@@ -0,0 +1,59 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package observability
+
+import (
+	"testing"
+
+	"github.com/cloudwego/cwgo/pkg/common/utils"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/cloudwego/cwgo/tpl"
+	kargs "github.com/cloudwego/kitex/tool/cmd/kitex/args"
+	"github.com/cloudwego/kitex/tool/internal_pkg/generator"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnsupported(t *testing.T) {
+	assert.Empty(t, Unsupported(nil, ClientBackends))
+	assert.Empty(t, Unsupported([]string{consts.Otel, consts.Prometheus}, ServerBackends))
+	assert.Equal(t, consts.Prometheus, Unsupported([]string{consts.Otel, consts.Prometheus}, ClientBackends))
+	assert.Equal(t, "observability_prometheus", Feature(consts.Prometheus))
+}
+
+func TestHandleKitex(t *testing.T) {
+	assert.NoError(t, tpl.Init())
+	defer tpl.Cleanup()
+
+	args := &kargs.Arguments{}
+	path, err := HandleKitex(nil, args)
+	assert.NoError(t, err)
+	assert.Empty(t, path)
+	assert.Empty(t, args.ExtensionFile)
+
+	path, err = HandleKitex([]string{consts.Otel}, args)
+	assert.NoError(t, err)
+	defer RemoveExtension(path)
+
+	// the features are merged into the extension already passed
+	merged, err := utils.EnableKitexFeatures(args, "config_center_file")
+	assert.NoError(t, err)
+	defer RemoveExtension(merged)
+
+	te := new(generator.TemplateExtension)
+	assert.NoError(t, te.FromYAMLFile(args.ExtensionFile))
+	assert.Equal(t, []string{Feature(consts.Otel), "config_center_file"}, te.EnableFeatures)
+}
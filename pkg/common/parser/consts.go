@@ -22,4 +22,5 @@ package parser
 const (
 	includePattern = `include\s*"([^"]+)"`
 	importPattern  = `import\s+"([^"]+)"`
+	servicePattern = `(?m)^\s*service\s+(\w+)\s*\{`
 )
@@ -27,12 +27,14 @@ import (
 )
 
 type ProtoParser struct {
-	reg *regexp.Regexp
+	reg        *regexp.Regexp
+	serviceReg *regexp.Regexp
 }
 
 func NewProtoParser() *ProtoParser {
 	return &ProtoParser{
-		reg: regexp.MustCompile(importPattern),
+		reg:        regexp.MustCompile(importPattern),
+		serviceReg: regexp.MustCompile(servicePattern),
 	}
 }
 
@@ -108,6 +110,11 @@ func (p *ProtoParser) GetDependentFilePaths(baseDirPath, mainIdlPath string) (st
 		return "", nil, err
 	}
 
+	// the IDL imports nothing, so there is no import base dir to relate to
+	if importBaseDirPath == "" {
+		return ".", nil, nil
+	}
+
 	// calculate the relative paths to the base dir path
 	relativePaths := make([]string, len(resultPaths))
 	for i, path := range resultPaths {
@@ -122,3 +129,16 @@ func (p *ProtoParser) GetDependentFilePaths(baseDirPath, mainIdlPath string) (st
 
 	return rel, relativePaths, nil
 }
+
+// GetServiceNames returns the names of the services defined in the IDL, in the order of definition.
+func (p *ProtoParser) GetServiceNames(idlPath string) ([]string, error) {
+	protoContent, err := os.ReadFile(idlPath)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, match := range p.serviceReg.FindAllStringSubmatch(string(protoContent), -1) {
+		names = append(names, match[1])
+	}
+	return names, nil
+}
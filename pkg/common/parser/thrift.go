@@ -0,0 +1,71 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser
+
+import (
+	"path/filepath"
+
+	"github.com/cloudwego/thriftgo/parser"
+)
+
+type ThriftParser struct{}
+
+func NewThriftParser() *ThriftParser {
+	return &ThriftParser{}
+}
+
+// GetDependentFilePaths returns the paths of all the IDLs the main IDL includes,
+// directly or transitively. The include dirs are searched after the dir of the
+// including file, the same way thriftgo does.
+func (t *ThriftParser) GetDependentFilePaths(mainIdlPath string, includeDirs []string) ([]string, error) {
+	ast, err := parser.ParseFile(mainIdlPath, includeDirs, true)
+	if err != nil {
+		return nil, err
+	}
+
+	mainAbsPath, _ := filepath.Abs(ast.Filename)
+	processedPaths := map[string]bool{mainAbsPath: true}
+	var resultPaths []string
+
+	var processFile func(ast *parser.Thrift)
+	processFile = func(ast *parser.Thrift) {
+		for _, include := range ast.Includes {
+			if include.Reference == nil {
+				continue
+			}
+			path, _ := filepath.Abs(include.Reference.Filename)
+			if processedPaths[path] {
+				continue
+			}
+			processedPaths[path] = true
+			resultPaths = append(resultPaths, path)
+			processFile(include.Reference)
+		}
+	}
+	processFile(ast)
+
+	return resultPaths, nil
+}
+
+// GetServices returns the services defined in the main IDL, in the order of definition.
+func (t *ThriftParser) GetServices(mainIdlPath string, includeDirs []string) ([]*parser.Service, error) {
+	ast, err := parser.ParseFile(mainIdlPath, includeDirs, true)
+	if err != nil {
+		return nil, err
+	}
+	return ast.Services, nil
+}
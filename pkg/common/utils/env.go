@@ -32,6 +32,7 @@ import (
 	"github.com/cloudwego/kitex/tool/cmd/kitex/args"
 	"github.com/cloudwego/kitex/tool/internal_pkg/pluginmode/thriftgo"
 
+	"github.com/cloudwego/cwgo/pkg/common/errs"
 	"github.com/cloudwego/cwgo/pkg/consts"
 )
 
@@ -126,7 +127,7 @@ func InitGoMod(module string) error {
 	}
 	gg, err := exec.LookPath(consts.Go)
 	if err != nil {
-		return err
+		return errs.Wrap(errs.MissingTool, err)
 	}
 	cmd := &exec.Cmd{
 		Path:   gg,
@@ -151,7 +152,7 @@ func commandAndNotice(cmd, notice string) {
 	if err != nil {
 		res = err.Error()
 	}
-	logs.Warn(notice, res)
+	logs.Warnf("%s%s", notice, res)
 }
 
 func ReplaceThriftVersion() {
@@ -175,29 +176,38 @@ func Hessian2PostProcessing(args args.Arguments) {
 	}
 
 	if err := thriftgo.Hessian2PatchByReplace(args.Config, ""); err != nil {
-		logs.Warn("replace java object fail, you can fix it then regenerate", err)
+		logs.Warnf("replace java object fail, you can fix it then regenerate: %s", err)
 	}
 }
 
-func LookupTool(idlType string) (string, error) {
-	tool := meta.TpCompilerThrift
-	if idlType == meta.IdlProto {
-		tool = meta.TpCompilerProto
-	}
-
-	path, err := exec.LookPath(tool)
+// FindTool looks for the executable in PATH and then in GOPATH/bin, it never installs it.
+func FindTool(tool string) (path string, isExist bool, err error) {
+	path, err = exec.LookPath(tool)
 	logs.Debugf("[DEBUG]path:%v", path)
 	if err != nil {
 		goPath, err := util.GetGOPATH()
 		if err != nil {
-			return "", fmt.Errorf("get 'GOPATH' failed for find %s : %v", tool, path)
+			return "", false, errs.New(errs.MissingTool, "get 'GOPATH' failed for find %s : %v", tool, path)
 		}
 		path = filepath.Join(goPath, "bin", tool)
 	}
 
-	isExist, err := util.PathExist(path)
+	isExist, err = util.PathExist(path)
+	if err != nil {
+		return "", false, fmt.Errorf("check '%s' path error: %v", path, err)
+	}
+	return path, isExist, nil
+}
+
+func LookupTool(idlType string) (string, error) {
+	tool := meta.TpCompilerThrift
+	if idlType == meta.IdlProto {
+		tool = meta.TpCompilerProto
+	}
+
+	path, isExist, err := FindTool(tool)
 	if err != nil {
-		return "", fmt.Errorf("check '%s' path error: %v", path, err)
+		return "", err
 	}
 
 	if !isExist {
@@ -205,10 +215,10 @@ func LookupTool(idlType string) (string, error) {
 			// If thriftgo does not exist, the latest version will be installed automatically.
 			err := util.InstallAndCheckThriftgo()
 			if err != nil {
-				return "", fmt.Errorf("can't install '%s' automatically, please install it manually for https://github.com/cloudwego/thriftgo, err : %v", tool, err)
+				return "", errs.New(errs.MissingTool, "can't install '%s' automatically, please install it manually for https://github.com/cloudwego/thriftgo, err : %v", tool, err)
 			}
 		} else {
-			return "", fmt.Errorf("%s is not installed, please install it first", tool)
+			return "", errs.New(errs.MissingTool, "%s is not installed, please install it first", tool)
 		}
 	}
 
@@ -216,7 +226,7 @@ func LookupTool(idlType string) (string, error) {
 		// If thriftgo exists, the version is detected; if the version is lower than v0.2.0 then the latest version of thriftgo is automatically installed.
 		err := util.CheckAndUpdateThriftgo()
 		if err != nil {
-			return "", fmt.Errorf("update thriftgo version failed, please install it manually for https://github.com/cloudwego/thriftgo, err: %v", err)
+			return "", errs.New(errs.MissingTool, "update thriftgo version failed, please install it manually for https://github.com/cloudwego/thriftgo, err: %v", err)
 		}
 	}
 
@@ -17,11 +17,11 @@
 package utils
 
 import (
-	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 
+	"github.com/cloudwego/cwgo/pkg/common/errs"
 	"github.com/cloudwego/hertz/cmd/hz/meta"
 )
 
@@ -42,7 +42,7 @@ func PathExist(path string) (bool, error) {
 func GetIdlType(path string, pbName ...string) (string, error) {
 	ext := filepath.Ext(path)
 	if ext == "" || ext[0] != '.' {
-		return "", fmt.Errorf("idl path %s is not a valid file", path)
+		return "", errs.New(errs.InvalidArgs, "idl path %s is not a valid file", path)
 	}
 	ext = ext[1:]
 	switch ext {
@@ -54,7 +54,7 @@ func GetIdlType(path string, pbName ...string) (string, error) {
 		}
 		return meta.IdlProto, nil
 	default:
-		return "", fmt.Errorf("IDL type %s is not supported", ext)
+		return "", errs.New(errs.InvalidArgs, "IDL type %s is not supported", ext)
 	}
 }
 
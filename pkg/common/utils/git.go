@@ -22,13 +22,14 @@ import (
 	"os/exec"
 	"strings"
 
+	"github.com/cloudwego/cwgo/pkg/common/errs"
 	"github.com/cloudwego/cwgo/pkg/consts"
 )
 
 func GitClone(gitURL, path string) error {
 	_, err := exec.LookPath("git")
 	if err != nil {
-		return err
+		return errs.Wrap(errs.MissingTool, err)
 	}
 	c := exec.Command("git", "clone", gitURL)
 	c.Dir = path
@@ -47,6 +48,17 @@ func GitCheckout(branch, path string) error {
 	return c.Run()
 }
 
+// GitHead returns the commit checked out in the repo at path.
+func GitHead(path string) (string, error) {
+	c := exec.Command("git", "rev-parse", "HEAD")
+	c.Dir = path
+	out, err := c.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
 func GitPath(gitURL string) (string, error) {
 	if len(gitURL) > 3 && gitURL[0:3] == "git" {
 		p := strings.Split(gitURL, consts.Slash)
@@ -17,6 +17,9 @@
 package utils
 
 import (
+	"fmt"
+	"log"
+	"os"
 	"path"
 
 	"github.com/cloudwego/cwgo/pkg/consts"
@@ -35,3 +38,34 @@ func IsHzNew(outputDir string) bool {
 	exist, _ := PathExist(path.Join(outputDir, consts.HzFile))
 	return !exist
 }
+
+// logger prints warnings right away, hz's StdLogger buffers them and never prints them.
+type logger struct {
+	*logs.StdLogger
+	level int
+	warn  *log.Logger
+}
+
+// SetLogger replaces the default logger of hz's logs package, which cwgo logs with as well.
+func SetLogger() {
+	logs.SetLogger(&logger{
+		StdLogger: logs.NewStdLogger(logs.LevelInfo),
+		level:     logs.LevelInfo,
+		warn:      log.New(os.Stderr, "[WARN]", log.Llongfile),
+	})
+}
+
+func (l *logger) SetLevel(level int) error {
+	if err := l.StdLogger.SetLevel(level); err != nil {
+		return err
+	}
+	l.level = level
+	return nil
+}
+
+func (l *logger) Warnf(format string, v ...interface{}) {
+	if l.level > logs.LevelWarn {
+		return
+	}
+	l.warn.Output(3, fmt.Sprintf(format, v...))
+}
@@ -0,0 +1,57 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/cloudwego/cwgo/tpl"
+	kargs "github.com/cloudwego/kitex/tool/cmd/kitex/args"
+	"github.com/cloudwego/kitex/tool/internal_pkg/generator"
+)
+
+// EnableKitexFeatures enables the features in a copy of the template extension of args
+// written into the private template root, and points args.ExtensionFile to it.
+// It returns the path of the written extension file, which is empty when there is no feature.
+func EnableKitexFeatures(args *kargs.Arguments, features ...string) (string, error) {
+	if len(features) == 0 {
+		return "", nil
+	}
+
+	te := new(generator.TemplateExtension)
+	if args.ExtensionFile != "" {
+		if err := te.FromYAMLFile(args.ExtensionFile); err != nil {
+			return "", fmt.Errorf("read template extension %s failed: %s", args.ExtensionFile, err)
+		}
+	}
+	te.FeatureNames = append(te.FeatureNames, features...)
+	te.EnableFeatures = append(te.EnableFeatures, features...)
+
+	f, err := os.CreateTemp(tpl.KitexDir, "*-"+consts.KitexExtensionYaml)
+	if err != nil {
+		return "", err
+	}
+	f.Close()
+	if err = te.ToYAMLFile(f.Name()); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	args.ExtensionFile = f.Name()
+	return f.Name(), nil
+}
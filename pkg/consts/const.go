@@ -23,11 +23,17 @@ const (
 	Hertz = "hertz"
 )
 
+// EnvPrefix prefixes the environment variables holding default flag values, e.g. CWGO_DSN or CWGO_SERVER_IDL.
+const EnvPrefix = "CWGO_"
+
 const (
 	CwgoDocPluginMode       = "CWGO_DOC_PLUGIN_DOC"
 	ThriftCwgoDocPluginName = "thrift-gen-cwgo-doc"
 )
 
+// TemplateVarsEnv passes the template variables as json to the kitex and doc plugin processes, see tpl.CommandEnv.
+const TemplateVarsEnv = "CWGO_TEMPLATE_VARS_JSON"
+
 const (
 	HertzRepoDefaultUrl = "github.com/cloudwego/hertz"
 )
@@ -69,11 +75,13 @@ const (
 	DefaultHZModelDir     = "hertz_gen"
 	DefaultHZClientDir    = "biz/http"
 	DefaultKitexModelDir  = "kitex_gen"
+	DefaultKitexClientDir = "rpc"
 	DefaultDbOutDir       = "biz/dal/query"
 	DefaultDocModelOutDir = "biz/doc/model"
 	DefaultDocDaoOutDir   = "biz/doc/dao"
 	Standard              = "standard"
 	StandardV2            = "standard_v2"
+	Hex                   = "hex"
 	CurrentDir            = "."
 )
 
@@ -87,14 +95,46 @@ const (
 	Main               = "main.go"
 	GoMod              = "go.mod"
 	HzFile             = ".hz"
+	ManifestFile       = "cwgo.yaml"
+	DefaultsFile       = ".cwgo.yaml"
+	LockFile           = ".cwgo.lock"
 )
 
 // Registration Center
 const (
-	Zk      = "ZK"
-	Nacos   = "NACOS"
-	Etcd    = "ETCD"
-	Polaris = "POLARIS"
+	Zk         = "ZK"
+	Nacos      = "NACOS"
+	Etcd       = "ETCD"
+	Polaris    = "POLARIS"
+	Consul     = "CONSUL"
+	Eureka     = "EUREKA"
+	Kubernetes = "KUBERNETES"
+)
+
+// Configuration Center, nacos and etcd are shared with the registration center
+const (
+	Apollo    = "APOLLO"
+	LocalFile = "FILE"
+)
+
+// Observability
+const (
+	Otel       = "OTEL"
+	Prometheus = "PROMETHEUS"
+)
+
+// Deployment artifacts
+const (
+	Docker = "DOCKER"
+	K8s    = "K8S"
+	Helm   = "HELM"
+)
+
+// Middleware scopes
+const (
+	ServerScope     = "server"
+	ClientScope     = "client"
+	RouteGroupScope = "route-group"
 )
 
 type DataBaseType string
@@ -116,17 +156,22 @@ const (
 )
 
 const (
-	Go     = "go"
-	GOPATH = "GOPATH"
-	Env    = "env"
-	Mod    = "mod"
-	Init   = "init"
-
-	OutDir   = "out_dir"
-	Verbose  = "verbose"
-	Template = "template"
-	Branch   = "branch"
-	Name     = "name"
+	Go          = "go"
+	GOPATH      = "GOPATH"
+	Env         = "env"
+	Mod         = "mod"
+	Init        = "init"
+	GOVERSION   = "GOVERSION"
+	ProtocGenGo = "protoc-gen-go"
+
+	OutDir          = "out_dir"
+	Verbose         = "verbose"
+	Template        = "template"
+	TemplateOverlay = "template_overlay"
+	TplVar          = "tpl_var"
+	TemplateVars    = "template_vars"
+	Branch          = "branch"
+	Name            = "name"
 
 	ModelDir = "model_dir"
 	DaoDir   = "dao_dir"
@@ -137,6 +182,8 @@ const (
 	Module          = "module"
 	IDLPath         = "idl"
 	Registry        = "registry"
+	ConfigCenter    = "config_center"
+	Observability   = "observability"
 	Pass            = "pass"
 	ProtoSearchPath = "proto_search_path"
 	ThriftGo        = "thriftgo"
@@ -159,6 +206,15 @@ const (
 	TypeTag       = "type_tag"
 	HexTag        = "hex"
 	SQLDir        = "sql_dir"
+	File          = "file"
+	DryRun        = "dry_run"
+	JSON          = "json"
+	Interactive   = "interactive"
+	Watch         = "watch"
+	Locked        = "locked"
+	Image         = "image"
+	Scope         = "scope"
+	Group         = "group"
 )
 
 const (
@@ -17,14 +17,13 @@
 package doc
 
 import (
-	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/cloudwego/cwgo/pkg/common/errs"
 	"github.com/cloudwego/cwgo/pkg/curd/doc/mongo/plugin"
-	"github.com/cloudwego/kitex/tool/internal_pkg/log"
 
 	"github.com/cloudwego/cwgo/pkg/common/utils"
 
@@ -37,6 +36,9 @@ func Doc(c *config.DocArgument) error {
 	if err := check(c); err != nil {
 		return err
 	}
+	if err := prepare(c); err != nil {
+		return err
+	}
 
 	switch c.Name {
 	case consts.MongoDb:
@@ -52,15 +54,46 @@ func Doc(c *config.DocArgument) error {
 	return nil
 }
 
+// Check validates the arguments the same way the generator does, the derived fields are filled.
+// Unlike the generator, it never touches the file system.
+func Check(c *config.DocArgument) error {
+	return check(c)
+}
+
+// prepare creates the output dirs, and go.mod if the output is not in a module yet.
+func prepare(c *config.DocArgument) error {
+	for _, dir := range []string{c.ModelDir, c.DaoDir} {
+		if isExist, _ := utils.PathExist(dir); !isExist {
+			if err := os.MkdirAll(dir, 0o755); err != nil {
+				return err
+			}
+		}
+	}
+	if c.GoMod == "" {
+		return nil
+	}
+	curpath, err := filepath.Abs(consts.CurrentDir)
+	if err != nil {
+		return fmt.Errorf("get current path failed: %s", err)
+	}
+	if _, _, ok := utils.SearchGoMod(curpath, true); ok {
+		return nil
+	}
+	if err = utils.InitGoMod(c.GoMod); err != nil {
+		return fmt.Errorf("init go mod failed: %w", err)
+	}
+	return nil
+}
+
 func check(c *config.DocArgument) (err error) {
 	if c.Name == "" {
 		c.Name = consts.MongoDb
 	}
 	if c.Name != consts.MongoDb {
-		return errors.New("doc name not supported")
+		return errs.New(errs.InvalidArgs, "doc name not supported")
 	}
 	if c.IdlPath == "" {
-		return errors.New("must specify idl path")
+		return errs.New(errs.InvalidArgs, "must specify idl path")
 	}
 
 	c.OutDir, err = filepath.Abs(c.OutDir)
@@ -75,11 +108,6 @@ func check(c *config.DocArgument) (err error) {
 	if err != nil {
 		return err
 	}
-	if isExist, _ := utils.PathExist(c.ModelDir); !isExist {
-		if err = os.MkdirAll(c.ModelDir, 0o755); err != nil {
-			return err
-		}
-	}
 
 	if c.DaoDir == "" {
 		c.DaoDir = consts.DefaultDocDaoOutDir
@@ -88,11 +116,6 @@ func check(c *config.DocArgument) (err error) {
 	if err != nil {
 		return err
 	}
-	if isExist, _ := utils.PathExist(c.DaoDir); !isExist {
-		if err = os.MkdirAll(c.DaoDir, 0o755); err != nil {
-			return err
-		}
-	}
 
 	c.IdlType, err = utils.GetIdlType(c.IdlPath)
 	if err != nil {
@@ -110,20 +133,17 @@ func check(c *config.DocArgument) (err error) {
 	gosrc := filepath.Join(gopath, consts.Src)
 	gosrc, err = filepath.Abs(gosrc)
 	if err != nil {
-		log.Warn("Get GOPATH/src path failed:", err.Error())
-		os.Exit(1)
+		return fmt.Errorf("get GOPATH/src path failed: %s", err)
 	}
 	curpath, err := filepath.Abs(consts.CurrentDir)
 	if err != nil {
-		log.Warn("Get current path failed:", err.Error())
-		os.Exit(1)
+		return fmt.Errorf("get current path failed: %s", err)
 	}
 
 	if strings.HasPrefix(curpath, gosrc) {
 		goPkg := ""
 		if goPkg, err = filepath.Rel(gosrc, curpath); err != nil {
-			log.Warn("Get GOPATH/src relpath failed:", err.Error())
-			os.Exit(1)
+			return fmt.Errorf("get GOPATH/src relpath failed: %s", err)
 		}
 
 		if c.GoMod == "" {
@@ -138,11 +158,11 @@ func check(c *config.DocArgument) (err error) {
 			if utils.IsWindows() {
 				goPkgSlash := strings.ReplaceAll(goPkg, consts.BackSlash, consts.Slash)
 				if goPkgSlash != c.GoMod {
-					return fmt.Errorf("module name: %s is not the same with GoPkg under GoPath: %s", c.GoMod, goPkgSlash)
+					return errs.New(errs.Conflict, "module name: %s is not the same with GoPkg under GoPath: %s", c.GoMod, goPkgSlash)
 				}
 			} else {
 				if c.GoMod != goPkg {
-					return fmt.Errorf("module name: %s is not the same with GoPkg under GoPath: %s", c.GoMod, goPkg)
+					return errs.New(errs.Conflict, "module name: %s is not the same with GoPkg under GoPath: %s", c.GoMod, goPkg)
 				}
 			}
 		}
@@ -150,13 +170,11 @@ func check(c *config.DocArgument) (err error) {
 
 	if strings.HasPrefix(curpath, gosrc) {
 		if c.PackagePrefix, err = filepath.Rel(gosrc, c.ModelDir); err != nil {
-			log.Warn("Get GOPATH/src relpath failed:", err.Error())
-			os.Exit(1)
+			return fmt.Errorf("get GOPATH/src relpath failed: %s", err)
 		}
 	} else {
 		if c.GoMod == "" {
-			log.Warn("Outside of $GOPATH. Please specify a module name with the '-module' flag.")
-			os.Exit(1)
+			return errs.New(errs.InvalidArgs, "outside of $GOPATH. Please specify a module name with the '-module' flag")
 		}
 	}
 
@@ -165,23 +183,16 @@ func check(c *config.DocArgument) (err error) {
 		if ok {
 			// go.mod exists
 			if module != c.GoMod {
-				log.Warnf("The module name given by the '-module' option ('%s') is not consist with the name defined in go.mod ('%s' from %s)\n",
+				return errs.New(errs.Conflict, "the module name given by the '-module' option ('%s') is not consist with the name defined in go.mod ('%s' from %s)",
 					c.GoMod, module, path)
-				os.Exit(1)
 			}
 			if c.PackagePrefix, err = filepath.Rel(path, c.ModelDir); err != nil {
-				log.Warn("Get package prefix failed:", err.Error())
-				os.Exit(1)
+				return fmt.Errorf("get package prefix failed: %s", err)
 			}
 			c.PackagePrefix = filepath.Join(c.GoMod, c.PackagePrefix)
 		} else {
-			if err = utils.InitGoMod(c.GoMod); err != nil {
-				log.Warn("Init go mod failed:", err.Error())
-				os.Exit(1)
-			}
 			if c.PackagePrefix, err = filepath.Rel(curpath, c.ModelDir); err != nil {
-				log.Warn("Get package prefix failed:", err.Error())
-				os.Exit(1)
+				return fmt.Errorf("get package prefix failed: %s", err)
 			}
 			c.PackagePrefix = filepath.Join(c.GoMod, c.PackagePrefix)
 		}
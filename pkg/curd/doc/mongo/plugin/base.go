@@ -108,7 +108,7 @@ func getInterfaceMethods() []*extract.InterfaceMethod {
 	return methods
 }
 
-func generateBaseMongoFile(daoDir string, importPaths []string, methodRenders []*template.MethodRender) (err error) {
+func generateBaseMongoFile(daoDir string, importPaths []string, methodRenders []*template.MethodRender, vars map[string]string) (err error) {
 	st := &extract.IdlExtractStruct{
 		Name:          "Base",
 		StructFields:  []*extract.StructField{},
@@ -131,7 +131,7 @@ func generateBaseMongoFile(daoDir string, importPaths []string, methodRenders []
 	}
 
 	// build new mongo file
-	formattedCode, err := getNewMongoCode(methodRenders, st, baseRender)
+	formattedCode, err := getNewMongoCode(methodRenders, st, baseRender, vars)
 	if err != nil {
 		return err
 	}
@@ -153,7 +153,7 @@ func generateBaseMongoFile(daoDir string, importPaths []string, methodRenders []
 	}
 
 	// build new interface file
-	formattedCode, err = getNewIfCode(st, baseRender)
+	formattedCode, err = getNewIfCode(st, baseRender, vars)
 	if err != nil {
 		return err
 	}
@@ -23,6 +23,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/cloudwego/cwgo/pkg/common/errs"
 	"github.com/cloudwego/cwgo/pkg/common/parser"
 
 	"github.com/cloudwego/cwgo/pkg/curd/doc/mongo/codegen"
@@ -33,18 +34,19 @@ import (
 	"github.com/cloudwego/cwgo/config"
 	"github.com/cloudwego/cwgo/pkg/common/utils"
 	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/cloudwego/cwgo/tpl"
 	"github.com/cloudwego/hertz/cmd/hz/meta"
 )
 
 func MongoTriggerPlugin(c *config.DocArgument) error {
 	cmd, err := buildPluginCmd(c)
 	if err != nil {
-		return fmt.Errorf("build plugin command failed: %v", err)
+		return fmt.Errorf("build plugin command failed: %w", err)
 	}
 
 	buf, err := cmd.CombinedOutput()
 	if err != nil {
-		return fmt.Errorf("plugin cwgo-doc returns error: %v, cause:\n%v", err, string(buf))
+		return errs.New(errs.IDLParse, "plugin cwgo-doc returns error: %v, cause:\n%v", err, string(buf))
 	}
 
 	// If len(buf) != 0, the plugin returned the log.
@@ -58,16 +60,16 @@ func MongoTriggerPlugin(c *config.DocArgument) error {
 		}
 		rawStructs, err := info.ParsePbIdl()
 		if err != nil {
-			return err
+			return errs.Wrap(errs.IDLParse, err)
 		}
 		operations, err := parse.HandleOperations(rawStructs)
 		if err != nil {
-			return err
+			return errs.Wrap(errs.IDLParse, err)
 		}
 		methodRenders := codegen.HandleCodegen(operations)
 
 		if c.GenBase {
-			if err = generateBaseMongoFile(info.DocArgs.DaoDir, info.ImportPaths, codegen.HandleBaseCodegen()); err != nil {
+			if err = generateBaseMongoFile(info.DocArgs.DaoDir, info.ImportPaths, codegen.HandleBaseCodegen(), info.DocArgs.TemplateVars); err != nil {
 				return err
 			}
 		}
@@ -152,6 +154,13 @@ func buildPluginCmd(args *config.DocArgument) (*exec.Cmd, error) {
 		cmd.Args = append(cmd.Args, args.IdlPath)
 	}
 
+	// the template variables reach the plugin process through its environment only
+	env, envErr := tpl.CommandEnv(nil, args.TemplateVars)
+	if envErr != nil {
+		return nil, envErr
+	}
+	cmd.Env = env
+
 	return cmd, err
 }
 
@@ -184,7 +193,7 @@ func generatePbMongoFile(structs []*extract.IdlExtractStruct, methodRenders [][]
 
 		if st.Update {
 			// build update mongo file
-			formattedCode, err := getUpdateMongoCode(methodRenders[index], string(st.UpdateCurdFileContent))
+			formattedCode, err := getUpdateMongoCode(methodRenders[index], string(st.UpdateCurdFileContent), info.DocArgs.TemplateVars)
 			if err != nil {
 				return err
 			}
@@ -201,7 +210,7 @@ func generatePbMongoFile(structs []*extract.IdlExtractStruct, methodRenders [][]
 			}
 
 			// build update interface file
-			formattedCode, err = getUpdateIfCode(st, baseRender)
+			formattedCode, err = getUpdateIfCode(st, baseRender, info.DocArgs.TemplateVars)
 			if err != nil {
 				return err
 			}
@@ -218,7 +227,7 @@ func generatePbMongoFile(structs []*extract.IdlExtractStruct, methodRenders [][]
 			}
 		} else {
 			// build new mongo file
-			formattedCode, err := getNewMongoCode(methodRenders[index], st, baseRender)
+			formattedCode, err := getNewMongoCode(methodRenders[index], st, baseRender, info.DocArgs.TemplateVars)
 			if err != nil {
 				return err
 			}
@@ -235,7 +244,7 @@ func generatePbMongoFile(structs []*extract.IdlExtractStruct, methodRenders [][]
 			}
 
 			// build new interface file
-			formattedCode, err = getNewIfCode(st, baseRender)
+			formattedCode, err = getNewIfCode(st, baseRender, info.DocArgs.TemplateVars)
 			if err != nil {
 				return err
 			}
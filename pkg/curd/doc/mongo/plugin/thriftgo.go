@@ -30,6 +30,7 @@ import (
 
 	"github.com/cloudwego/cwgo/config"
 	cwgoMeta "github.com/cloudwego/cwgo/meta"
+	"github.com/cloudwego/cwgo/tpl"
 	"github.com/cloudwego/hertz/cmd/hz/meta"
 	"github.com/cloudwego/hertz/cmd/hz/util/logs"
 	"github.com/cloudwego/thriftgo/plugin"
@@ -85,7 +86,7 @@ func thriftPluginRun() int {
 	}
 
 	if plu.docArgs.GenBase {
-		if err = generateBaseMongoFile(plu.docArgs.DaoDir, tfUsedInfo.ImportPaths, codegen.HandleBaseCodegen()); err != nil {
+		if err = generateBaseMongoFile(plu.docArgs.DaoDir, tfUsedInfo.ImportPaths, codegen.HandleBaseCodegen(), plu.docArgs.TemplateVars); err != nil {
 			return meta.PluginError
 		}
 	}
@@ -117,6 +118,12 @@ func (plu *thriftGoPlugin) parseArgs() error {
 		logs.Errorf("unpack args failed: %s", err.Error())
 		return err
 	}
+	// the template variables are not packed with the arguments, see DocArgument.Pack
+	vars, err := tpl.LoadVars()
+	if err != nil {
+		return err
+	}
+	args.TemplateVars = vars
 	plu.docArgs = args
 	return nil
 }
@@ -144,7 +151,7 @@ func (plu *thriftGoPlugin) buildResponse(structs []*extract.IdlExtractStruct, me
 
 		if st.Update {
 			// build update mongo file
-			formattedCode, err := getUpdateMongoCode(methodRenders[index], string(st.UpdateCurdFileContent))
+			formattedCode, err := getUpdateMongoCode(methodRenders[index], string(st.UpdateCurdFileContent), plu.docArgs.TemplateVars)
 			if err != nil {
 				return nil, err
 			}
@@ -162,7 +169,7 @@ func (plu *thriftGoPlugin) buildResponse(structs []*extract.IdlExtractStruct, me
 			})
 
 			// build update interface file
-			formattedCode, err = getUpdateIfCode(st, baseRender)
+			formattedCode, err = getUpdateIfCode(st, baseRender, plu.docArgs.TemplateVars)
 			if err != nil {
 				return nil, err
 			}
@@ -180,7 +187,7 @@ func (plu *thriftGoPlugin) buildResponse(structs []*extract.IdlExtractStruct, me
 			})
 		} else {
 			// build new mongo file
-			formattedCode, err := getNewMongoCode(methodRenders[index], st, baseRender)
+			formattedCode, err := getNewMongoCode(methodRenders[index], st, baseRender, plu.docArgs.TemplateVars)
 			if err != nil {
 				return nil, err
 			}
@@ -198,7 +205,7 @@ func (plu *thriftGoPlugin) buildResponse(structs []*extract.IdlExtractStruct, me
 			})
 
 			// build new interface file
-			formattedCode, err = getNewIfCode(st, baseRender)
+			formattedCode, err = getNewIfCode(st, baseRender, plu.docArgs.TemplateVars)
 			if err != nil {
 				return nil, err
 			}
@@ -229,9 +236,10 @@ func getBaseRender(st *extract.IdlExtractStruct) *template.BaseRender {
 	}
 }
 
-func getUpdateMongoCode(methodRenders []*template.MethodRender, fileContent string) (string, error) {
+func getUpdateMongoCode(methodRenders []*template.MethodRender, fileContent string, vars map[string]string) (string, error) {
 	tplMongo := &template.Template{
 		Renders: []template.Render{},
+		Vars:    vars,
 	}
 	for _, methodRender := range methodRenders {
 		tplMongo.Renders = append(tplMongo.Renders, methodRender)
@@ -250,9 +258,10 @@ func getUpdateMongoCode(methodRenders []*template.MethodRender, fileContent stri
 	return string(formattedCode), nil
 }
 
-func getUpdateIfCode(st *extract.IdlExtractStruct, baseRender *template.BaseRender) (string, error) {
+func getUpdateIfCode(st *extract.IdlExtractStruct, baseRender *template.BaseRender, vars map[string]string) (string, error) {
 	tplIf := &template.Template{
 		Renders: []template.Render{},
+		Vars:    vars,
 	}
 	tplIf.Renders = append(tplIf.Renders, baseRender)
 
@@ -290,9 +299,10 @@ func getUpdateIfCode(st *extract.IdlExtractStruct, baseRender *template.BaseRend
 	return string(formattedCode), nil
 }
 
-func getNewMongoCode(methodRenders []*template.MethodRender, st *extract.IdlExtractStruct, baseRender *template.BaseRender) (string, error) {
+func getNewMongoCode(methodRenders []*template.MethodRender, st *extract.IdlExtractStruct, baseRender *template.BaseRender, vars map[string]string) (string, error) {
 	tplMongo := &template.Template{
 		Renders: []template.Render{},
+		Vars:    vars,
 	}
 
 	tplMongo.Renders = append(tplMongo.Renders, baseRender)
@@ -314,9 +324,10 @@ func getNewMongoCode(methodRenders []*template.MethodRender, st *extract.IdlExtr
 	return string(formattedCode), nil
 }
 
-func getNewIfCode(st *extract.IdlExtractStruct, baseRender *template.BaseRender) (string, error) {
+func getNewIfCode(st *extract.IdlExtractStruct, baseRender *template.BaseRender, vars map[string]string) (string, error) {
 	tplIf := &template.Template{
 		Renders: []template.Render{},
+		Vars:    vars,
 	}
 	tplIf.Renders = append(tplIf.Renders, baseRender)
 
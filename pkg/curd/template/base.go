@@ -20,6 +20,8 @@ import (
 	"bytes"
 	"fmt"
 	"text/template"
+
+	"github.com/cloudwego/cwgo/tpl"
 )
 
 var baseTemplate = `// Code generated by cwgo ({{.Version}}). DO NOT EDIT.
@@ -36,8 +38,8 @@ type BaseRender struct {
 	Imports     map[string]string // key:import path value:import name
 }
 
-func (bt *BaseRender) RenderObj(buffer *bytes.Buffer) error {
-	if err := templateRender(buffer, "baseTemplate", baseTemplate, bt); err != nil {
+func (bt *BaseRender) RenderObj(buffer *bytes.Buffer, vars map[string]string) error {
+	if err := templateRender(buffer, "baseTemplate", baseTemplate, bt, vars); err != nil {
 		return err
 	}
 	return nil
@@ -57,8 +59,9 @@ func (bt *BaseRender) GetImports() string {
 	return result
 }
 
-func templateRender(buffer *bytes.Buffer, templateName, parseText string, data any) error {
-	tmpl, err := template.New(templateName).Parse(parseText)
+// templateRender renders the template with data, the template reads the template variables vars with TplVar.
+func templateRender(buffer *bytes.Buffer, templateName, parseText string, data any, vars map[string]string) error {
+	tmpl, err := template.New(templateName).Funcs(tpl.VarFuncs(vars)).Parse(parseText)
 	if err != nil {
 		return err
 	}
@@ -35,8 +35,8 @@ type FuncRender struct {
 	FuncBody code.Body
 }
 
-func (fr *FuncRender) RenderObj(buffer *bytes.Buffer) error {
-	if err := templateRender(buffer, "funcTemplate", funcTemplate, fr); err != nil {
+func (fr *FuncRender) RenderObj(buffer *bytes.Buffer, vars map[string]string) error {
+	if err := templateRender(buffer, "funcTemplate", funcTemplate, fr, vars); err != nil {
 		return err
 	}
 	return nil
@@ -33,8 +33,8 @@ type InterfaceRender struct {
 	Methods code.InterfaceMethods
 }
 
-func (ir *InterfaceRender) RenderObj(buffer *bytes.Buffer) error {
-	if err := templateRender(buffer, "interfaceTemplate", interfaceTemplate, ir); err != nil {
+func (ir *InterfaceRender) RenderObj(buffer *bytes.Buffer, vars map[string]string) error {
+	if err := templateRender(buffer, "interfaceTemplate", interfaceTemplate, ir, vars); err != nil {
 		return err
 	}
 	return nil
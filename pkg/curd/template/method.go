@@ -36,8 +36,8 @@ type MethodRender struct {
 	MethodBody     code.Body
 }
 
-func (mr *MethodRender) RenderObj(buffer *bytes.Buffer) error {
-	if err := templateRender(buffer, "methodTemplate", methodTemplate, mr); err != nil {
+func (mr *MethodRender) RenderObj(buffer *bytes.Buffer, vars map[string]string) error {
+	if err := templateRender(buffer, "methodTemplate", methodTemplate, mr, vars); err != nil {
 		return err
 	}
 	return nil
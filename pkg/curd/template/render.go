@@ -19,5 +19,5 @@ package template
 import "bytes"
 
 type Render interface {
-	RenderObj(buffer *bytes.Buffer) error
+	RenderObj(buffer *bytes.Buffer, vars map[string]string) error
 }
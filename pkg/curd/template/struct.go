@@ -33,8 +33,8 @@ type StructRender struct {
 	StructFields code.StructFields
 }
 
-func (sr *StructRender) RenderObj(buffer *bytes.Buffer) error {
-	if err := templateRender(buffer, "structTemplate", structTemplate, sr); err != nil {
+func (sr *StructRender) RenderObj(buffer *bytes.Buffer, vars map[string]string) error {
+	if err := templateRender(buffer, "structTemplate", structTemplate, sr, vars); err != nil {
 		return err
 	}
 	return nil
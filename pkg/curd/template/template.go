@@ -20,6 +20,8 @@ import "bytes"
 
 type Template struct {
 	Renders []Render
+	// Vars are the template variables read by the renders with TplVar.
+	Vars map[string]string
 }
 
 func (t *Template) AddRender(render Render) {
@@ -30,7 +32,7 @@ func (t *Template) Build() (*bytes.Buffer, error) {
 	buffer := new(bytes.Buffer)
 
 	for _, render := range t.Renders {
-		if err := render.RenderObj(buffer); err != nil {
+		if err := render.RenderObj(buffer, t.Vars); err != nil {
 			return nil, err
 		}
 	}
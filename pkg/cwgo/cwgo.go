@@ -0,0 +1,340 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package cwgo runs the cwgo generators from a Go program.
+//
+// Every Generate function takes a context and options, and returns the generated files instead of
+// writing into the process working directory or exiting the process. The generators run in a
+// sandbox copy of the project dir, the created and modified files are then written to Output.
+//
+// kitex, hz and the cwgo generators resolve their output and their templates from the working
+// directory, the environment and the template dirs of package tpl, which are process-wide. So every
+// generation runs in a child process executing the running program, with its own working directory,
+// environment and template dirs: the calls run in parallel and the state of the calling process is
+// never changed. The running program is also executed as a thriftgo / protoc plugin by kitex, hz and
+// the doc generator, so it must call PluginMode at the very beginning of its main.
+//
+// ctx is checked before and after the run, a canceled call writes nothing to Output, but a running
+// generation is not interrupted.
+package cwgo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+
+	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/cwgo/pkg/common/dryrun"
+	"github.com/cloudwego/cwgo/pkg/common/errs"
+	"github.com/cloudwego/cwgo/pkg/curd/doc/mongo/plugin"
+	"github.com/cloudwego/cwgo/pkg/gen"
+	"github.com/cloudwego/cwgo/tpl"
+	"github.com/cloudwego/hertz/cmd/hz/app"
+	kargs "github.com/cloudwego/kitex/tool/cmd/kitex/args"
+	"github.com/cloudwego/kitex/tool/internal_pkg/pluginmode/protoc"
+	"github.com/cloudwego/kitex/tool/internal_pkg/pluginmode/thriftgo"
+)
+
+// Status tells what happened to a generated file.
+type Status = dryrun.Status
+
+const (
+	Created   = dryrun.Created
+	Modified  = dryrun.Modified
+	Deleted   = dryrun.Deleted
+	Unchanged = dryrun.Unchanged
+	Skipped   = dryrun.Skipped
+)
+
+// File is a file touched by a generation, Path is slash separated and relative to the project dir.
+type File struct {
+	Path   string
+	Status Status
+}
+
+// Result lists the files touched by a generation.
+type Result struct {
+	Files []File
+}
+
+// Options common to all generators. Dir is the project dir, it defaults to the working directory,
+// relative paths in the spec are based on it. Output defaults to DirFS(Dir).
+type (
+	ServerOptions struct {
+		config.ServerSpec
+		Output FS
+	}
+	ClientOptions struct {
+		config.ClientSpec
+		Output FS
+	}
+	ModelOptions struct {
+		config.ModelSpec
+		Output FS
+	}
+	DocOptions struct {
+		config.DocSpec
+		Output FS
+	}
+	JobOptions struct {
+		config.JobSpec
+		Output FS
+	}
+)
+
+func GenerateServer(ctx context.Context, opts ServerOptions) (*Result, error) {
+	dir, spec := opts.Dir, opts.ServerSpec
+	spec.Dir = ""
+	return generate(ctx, "server", dir, opts.Output, func(m *config.Manifest) {
+		m.Services = []config.ServerSpec{spec}
+	})
+}
+
+func GenerateClient(ctx context.Context, opts ClientOptions) (*Result, error) {
+	dir, spec := opts.Dir, opts.ClientSpec
+	spec.Dir = ""
+	return generate(ctx, "client", dir, opts.Output, func(m *config.Manifest) {
+		m.Clients = []config.ClientSpec{spec}
+	})
+}
+
+func GenerateModel(ctx context.Context, opts ModelOptions) (*Result, error) {
+	dir, spec := opts.Dir, opts.ModelSpec
+	spec.Dir = ""
+	return generate(ctx, "model", dir, opts.Output, func(m *config.Manifest) {
+		m.Models = []config.ModelSpec{spec}
+	})
+}
+
+func GenerateDoc(ctx context.Context, opts DocOptions) (*Result, error) {
+	dir, spec := opts.Dir, opts.DocSpec
+	spec.Dir = ""
+	return generate(ctx, "doc", dir, opts.Output, func(m *config.Manifest) {
+		m.Docs = []config.DocSpec{spec}
+	})
+}
+
+func GenerateJob(ctx context.Context, opts JobOptions) (*Result, error) {
+	dir, spec := opts.Dir, opts.JobSpec
+	spec.Dir = ""
+	return generate(ctx, "job", dir, opts.Output, func(m *config.Manifest) {
+		m.Jobs = []config.JobSpec{spec}
+	})
+}
+
+// envGenerate is set for the child process running a generation, it names the file the result is written to.
+const envGenerate = "CWGO_GENERATE_MODE"
+
+var registerOnce sync.Once
+
+// request is the generation a child process reads from its stdin.
+type request struct {
+	Generator string
+	Manifest  *config.Manifest
+}
+
+// response is the result a child process writes to the file named by envGenerate.
+type response struct {
+	Changes []dryrun.FileChange
+	Kind    errs.Kind
+	Err     string
+}
+
+func generate(ctx context.Context, generator, dir string, out FS, entry func(m *config.Manifest)) (*Result, error) {
+	fail := func(err error) (*Result, error) {
+		return nil, &Error{Generator: generator, Err: err}
+	}
+	if err := ctx.Err(); err != nil {
+		return fail(err)
+	}
+
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return fail(err)
+	}
+	m := &config.Manifest{Dir: dir}
+	entry(m)
+	if err = m.Validate(); err != nil {
+		return fail(fmt.Errorf("%w: %s", ErrInvalidOptions, err))
+	}
+	if err = m.Sandbox(dir); err != nil {
+		return fail(fmt.Errorf("%w: %s", ErrInvalidOptions, err))
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return fail(fmt.Errorf("%w: %s is not a directory", ErrInvalidOptions, dir))
+	}
+	if out == nil {
+		out = DirFS(dir)
+	}
+
+	resp, err := runChild(&request{Generator: generator, Manifest: m})
+	if err != nil {
+		return fail(err)
+	}
+	if resp.Err != "" {
+		return fail(errs.New(resp.Kind, "%s", resp.Err))
+	}
+	// the output is dropped when ctx is done once the child process is over
+	if err = ctx.Err(); err != nil {
+		return fail(err)
+	}
+
+	res := new(Result)
+	for _, c := range resp.Changes {
+		switch c.Status {
+		case Created, Modified:
+			if err = out.WriteFile(c.Path, c.New, c.Mode); err != nil {
+				return fail(fmt.Errorf("write %s failed: %w", c.Path, err))
+			}
+		case Deleted:
+			if r, ok := out.(Remover); ok {
+				if err = r.Remove(c.Path); err != nil {
+					return fail(fmt.Errorf("remove %s failed: %w", c.Path, err))
+				}
+			}
+		}
+		res.Files = append(res.Files, File{Path: c.Path, Status: c.Status})
+	}
+	return res, nil
+}
+
+// runChild runs the generation in a child process executing the running program.
+func runChild(req *request) (*response, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	input, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	// the temporary files of the child are in a dir of its own, it is removed even when the child crashes
+	tmp, err := os.MkdirTemp("", "cwgo-generate-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+	result := filepath.Join(tmp, "result.json")
+
+	output := new(bytes.Buffer)
+	cmd := exec.Command(exe)
+	cmd.Env = append(os.Environ(), envGenerate+"="+result, "TMPDIR="+tmp, "TMP="+tmp, "TEMP="+tmp)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout, cmd.Stderr = output, output
+	if err = cmd.Run(); err != nil {
+		return nil, fmt.Errorf("generation process failed: %s\n%s", err, bytes.TrimSpace(output.Bytes()))
+	}
+	data, err := os.ReadFile(result)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%s did not run the generation, cwgo.PluginMode must be called at the beginning of its main", exe)
+	}
+	if err != nil {
+		return nil, err
+	}
+	resp := new(response)
+	if err = json.Unmarshal(data, resp); err != nil {
+		return nil, fmt.Errorf("read the generation result failed: %s", err)
+	}
+	return resp, nil
+}
+
+// generateMode runs the generation requested by generate when the program is executed as its child process,
+// it never returns in that case.
+func generateMode() {
+	path := os.Getenv(envGenerate)
+	if path == "" {
+		return
+	}
+	// the plugins executed by kitex and hz are not generation processes
+	os.Unsetenv(envGenerate)
+
+	resp := new(response)
+	req := new(request)
+	if err := json.NewDecoder(os.Stdin).Decode(req); err != nil {
+		resp.Err = fmt.Sprintf("read the generation request failed: %s", err)
+	} else if resp.Changes, err = run(req); err != nil {
+		resp.Kind, resp.Err = errs.KindOf(err), err.Error()
+	}
+	data, err := json.Marshal(resp)
+	if err == nil {
+		err = os.WriteFile(path, data, 0o600)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "write the generation result failed: %s\n", err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// run runs the generation in a sandbox copy of the project dir, it uses the template dirs of a private root.
+func run(req *request) (changes []dryrun.FileChange, err error) {
+	registerOnce.Do(tpl.RegisterTemplateFunc)
+	root, err := tpl.NewRoot()
+	if err != nil {
+		return nil, err
+	}
+	defer root.Close()
+	tpl.KitexDir, tpl.HertzDir = root.KitexDir, root.HertzDir
+
+	// the task is made for every run, as its arguments are changed by the generator
+	report, err := dryrun.RunIn(req.Manifest.Dir, func() (err error) {
+		task := gen.Tasks(req.Manifest, false)[0]
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("%s panicked: %v", task.Name, r)
+			}
+		}()
+		return task.Run()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report.Changes, nil
+}
+
+// PluginMode runs the program as the hz, kitex or mongo plugin or as the process of a generation
+// when it is executed as one, it never returns in that case.
+func PluginMode() {
+	// run a generation requested by generate
+	generateMode()
+
+	registerOnce.Do(tpl.RegisterTemplateFunc)
+
+	// run as hz plugin mode
+	app.PluginMode()
+	// run as kitex plugin mode
+	kitexPluginMode()
+	// run as mongo plugin mode
+	plugin.MongoPluginMode()
+}
+
+func kitexPluginMode() {
+	mode := os.Getenv(kargs.EnvPluginMode)
+	if len(os.Args) <= 1 && mode != "" {
+		// run as a plugin
+		switch mode {
+		case thriftgo.PluginName:
+			os.Exit(thriftgo.Run())
+		case protoc.PluginName:
+			os.Exit(protoc.Run())
+		}
+	}
+}
@@ -0,0 +1,338 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cwgo
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/cwgo/pkg/common/errs"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// TestMain runs the test binary as the plugin of kitex and hz when they execute it.
+func TestMain(m *testing.M) {
+	PluginMode()
+	os.Exit(m.Run())
+}
+
+const demoIDL = `namespace go demo
+struct Req { 1: string name }
+service Demo { string Echo(1: Req req) }
+`
+
+// newProject writes a project of module example.com/demo with the demo IDL into a new dir,
+// the tests of the kitex and hz generators are skipped when thriftgo is not installed.
+func newProject(t *testing.T) string {
+	if _, err := exec.LookPath("thriftgo"); err != nil {
+		t.Skip("thriftgo is not installed")
+	}
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/demo\n"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "demo.thrift"), []byte(demoIDL), 0o644))
+	return dir
+}
+
+func TestGenerateServersConcurrently(t *testing.T) {
+	registries := map[string]string{
+		consts.Etcd:  "github.com/kitex-contrib/registry-etcd",
+		consts.Nacos: "github.com/kitex-contrib/registry-nacos",
+	}
+	outs := make(map[string]*MemFS, len(registries))
+	for registry := range registries {
+		outs[registry] = NewMemFS()
+	}
+
+	var wg sync.WaitGroup
+	for registry, out := range outs {
+		wg.Add(1)
+		go func(registry string, out *MemFS, dir string) {
+			defer wg.Done()
+			_, err := GenerateServer(context.Background(), ServerOptions{
+				ServerSpec: config.ServerSpec{
+					Dir: dir, ServerName: "demo", Type: consts.RPC, Module: "example.com/demo",
+					IdlPath: "demo.thrift", Registry: registry,
+				},
+				Output: out,
+			})
+			assert.NoError(t, err)
+		}(registry, out, newProject(t))
+	}
+	wg.Wait()
+
+	// each server registers to its own registry only
+	for registry, out := range outs {
+		server, err := out.ReadFile("kitex_gen/demo/demo/server.go")
+		assert.NoError(t, err)
+		for other, pkg := range registries {
+			if other == registry {
+				assert.Contains(t, string(server), pkg)
+			} else {
+				assert.NotContains(t, string(server), pkg)
+			}
+		}
+	}
+}
+
+func TestGenerateJob(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/demo\n"), 0o644))
+
+	out := NewMemFS()
+	res, err := GenerateJob(context.Background(), JobOptions{
+		JobSpec: config.JobSpec{Dir: dir, Module: "example.com/demo", JobName: []string{"email"}},
+		Output:  out,
+	})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, res.Files)
+
+	schedule, err := out.ReadFile("schedule.go")
+	assert.NoError(t, err)
+	assert.Contains(t, string(schedule), "email.Run()")
+
+	// nothing is written to the project dir
+	_, err = os.Stat(filepath.Join(dir, "schedule.go"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+// generateJobs generates a project of each job into a new dir, in parallel or one after the other.
+func generateJobs(t *testing.T, jobs []string, parallel bool) []*MemFS {
+	outs := make([]*MemFS, len(jobs))
+	var wg sync.WaitGroup
+	for i, job := range jobs {
+		dir := t.TempDir()
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/demo\n"), 0o644))
+		outs[i] = NewMemFS()
+		gen := func(job, dir string, out *MemFS) {
+			_, err := GenerateJob(context.Background(), JobOptions{
+				JobSpec: config.JobSpec{Dir: dir, Module: "example.com/demo", JobName: []string{job}},
+				Output:  out,
+			})
+			assert.NoError(t, err)
+		}
+		if !parallel {
+			gen(job, dir, outs[i])
+			continue
+		}
+		wg.Add(1)
+		go func(job, dir string, out *MemFS) {
+			defer wg.Done()
+			gen(job, dir, out)
+		}(job, dir, outs[i])
+	}
+	wg.Wait()
+	return outs
+}
+
+func TestGenerateJobsConcurrently(t *testing.T) {
+	jobs := []string{"email", "clean", "report", "backup"}
+	parallel := generateJobs(t, jobs, true)
+	serial := generateJobs(t, jobs, false)
+
+	for i, job := range jobs {
+		// the generations running at the same time do not see each other
+		assert.Equal(t, serial[i].Files(), parallel[i].Files(), job)
+		for _, name := range serial[i].Files() {
+			want, _ := serial[i].ReadFile(name)
+			got, err := parallel[i].ReadFile(name)
+			assert.NoError(t, err)
+			assert.Equal(t, string(want), string(got), name)
+		}
+		schedule, err := parallel[i].ReadFile("schedule.go")
+		assert.NoError(t, err)
+		for _, other := range jobs {
+			if other == job {
+				assert.Contains(t, string(schedule), other+".Run()")
+			} else {
+				assert.NotContains(t, string(schedule), other+".Run()")
+			}
+		}
+	}
+}
+
+func TestGenerateInvalidOptions(t *testing.T) {
+	_, err := GenerateServer(context.Background(), ServerOptions{ServerSpec: config.ServerSpec{Type: "RPC"}})
+	assert.True(t, errors.Is(err, ErrInvalidOptions))
+	var e *Error
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, "server", e.Generator)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = GenerateJob(ctx, JobOptions{JobSpec: config.JobSpec{JobName: []string{"email"}}})
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestGenerateFailure(t *testing.T) {
+	dir := newProject(t)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "bad.thrift"), []byte("service {"), 0o644))
+
+	// the error of the generation process keeps its kind
+	_, err := GenerateServer(context.Background(), ServerOptions{
+		ServerSpec: config.ServerSpec{Dir: dir, ServerName: "demo", Type: consts.RPC, Module: "example.com/demo", IdlPath: "bad.thrift"},
+	})
+	var e *Error
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, errs.IDLParse, errs.KindOf(err))
+	assert.Contains(t, err.Error(), "bad.thrift")
+}
+
+// assertUntouched checks that a generation wrote nothing into the project dir and restored the working directory.
+func assertUntouched(t *testing.T, dir, cwd string, files ...string) {
+	for _, f := range files {
+		_, err := os.Stat(filepath.Join(dir, filepath.FromSlash(f)))
+		assert.True(t, os.IsNotExist(err), f)
+	}
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.Equal(t, cwd, wd)
+}
+
+func TestGenerateServer(t *testing.T) {
+	dir := newProject(t)
+	cwd, err := os.Getwd()
+	assert.NoError(t, err)
+
+	out := NewMemFS()
+	res, err := GenerateServer(context.Background(), ServerOptions{
+		ServerSpec: config.ServerSpec{Dir: dir, ServerName: "demo", Type: consts.RPC, Module: "example.com/demo", IdlPath: "demo.thrift"},
+		Output:     out,
+	})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, res.Files)
+
+	main, err := out.ReadFile("main.go")
+	assert.NoError(t, err)
+	assert.Contains(t, string(main), "demo.NewServer(new(DemoImpl), opts...)")
+	_, err = out.ReadFile("kitex_gen/demo/demo/server.go")
+	assert.NoError(t, err)
+	assertUntouched(t, dir, cwd, "main.go", "kitex_gen")
+}
+
+func TestRegenerateServer(t *testing.T) {
+	dir := newProject(t)
+	opts := ServerOptions{
+		ServerSpec: config.ServerSpec{Dir: dir, ServerName: "demo", Type: consts.RPC, Module: "example.com/demo", IdlPath: "demo.thrift"},
+	}
+	_, err := GenerateServer(context.Background(), opts)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0o644))
+
+	// main.go is skipped by the update, the kitex code is rewritten as is
+	out := NewMemFS()
+	opts.Output = out
+	res, err := GenerateServer(context.Background(), opts)
+	assert.NoError(t, err)
+	statuses := make(map[string]Status)
+	for _, f := range res.Files {
+		statuses[f.Path] = f.Status
+	}
+	assert.Equal(t, Skipped, statuses["main.go"])
+	assert.Equal(t, Unchanged, statuses["kitex_gen/demo/demo/server.go"])
+	assert.Empty(t, out.Files())
+}
+
+func TestGenerateClient(t *testing.T) {
+	dir := newProject(t)
+	cwd, err := os.Getwd()
+	assert.NoError(t, err)
+
+	out := NewMemFS()
+	res, err := GenerateClient(context.Background(), ClientOptions{
+		ClientSpec: config.ClientSpec{Dir: dir, ServerName: "demo", Type: consts.RPC, Module: "example.com/demo", IdlPath: "demo.thrift"},
+		Output:     out,
+	})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, res.Files)
+
+	_, err = out.ReadFile("kitex_gen/demo/demo/client.go")
+	assert.NoError(t, err)
+	client, err := out.ReadFile("rpc/demo/demo_client.go")
+	assert.NoError(t, err)
+	assert.Contains(t, string(client), "package demo")
+	_, err = out.ReadFile("main.go")
+	assert.Error(t, err)
+	assertUntouched(t, dir, cwd, "kitex_gen", "rpc")
+}
+
+func TestGenerateModel(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/demo\n"), 0o644))
+	dsn := filepath.Join(t.TempDir(), "demo.db")
+	db, err := gorm.Open(sqlite.Open(dsn))
+	assert.NoError(t, err)
+	assert.NoError(t, db.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)").Error)
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, sqlDB.Close())
+	cwd, err := os.Getwd()
+	assert.NoError(t, err)
+
+	out := NewMemFS()
+	res, err := GenerateModel(context.Background(), ModelOptions{
+		ModelSpec: config.ModelSpec{Dir: dir, DSN: dsn, DBType: string(consts.Sqlite), OutDir: "biz/dal/query", OnlyModel: true},
+		Output:    out,
+	})
+	assert.NoError(t, err)
+
+	var model []byte
+	for _, f := range res.Files {
+		if filepath.Base(f.Path) == "users.gen.go" {
+			model, err = out.ReadFile(f.Path)
+			assert.NoError(t, err)
+		}
+	}
+	assert.Contains(t, string(model), "type User struct")
+	assertUntouched(t, dir, cwd, "biz")
+}
+
+// cancelAfter is a context done after its Err is called n times.
+type cancelAfter struct {
+	context.Context
+	n int
+}
+
+func (c *cancelAfter) Err() error {
+	if c.n--; c.n < 0 {
+		return context.Canceled
+	}
+	return nil
+}
+
+func TestGenerateCanceledDuringRun(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/demo\n"), 0o644))
+
+	// ctx is checked before the run, it is done once the run is over
+	out := NewMemFS()
+	_, err := GenerateJob(&cancelAfter{Context: context.Background(), n: 1}, JobOptions{
+		JobSpec: config.JobSpec{Dir: dir, Module: "example.com/demo", JobName: []string{"email"}},
+		Output:  out,
+	})
+	assert.True(t, errors.Is(err, context.Canceled))
+	_, err = out.ReadFile("schedule.go")
+	assert.Error(t, err)
+}
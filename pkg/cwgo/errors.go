@@ -0,0 +1,39 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cwgo
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidOptions is wrapped by the error returned for options rejected before the generation starts.
+var ErrInvalidOptions = errors.New("invalid options")
+
+// Error is the error returned by the Generate functions.
+type Error struct {
+	Generator string // server, client, model, doc or job
+	Err       error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("cwgo %s: %s", e.Generator, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,296 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package deploy
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/cwgo/pkg/common/errs"
+	"github.com/cloudwego/cwgo/pkg/common/utils"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/cloudwego/hertz/cmd/hz/util/logs"
+	"gopkg.in/yaml.v3"
+)
+
+// Types lists the supported artifacts.
+var Types = []string{consts.Docker, consts.K8s, consts.Helm}
+
+// hexFile is generated by cwgo server --hex, the kitex server then serves HTTP on its port as well.
+const hexFile = "hex_trans_handler.go"
+
+var (
+	goVersionReg = regexp.MustCompile(`(?m)^go\s+(\d+\.\d+)`)
+	// k8sNameReg matches what is not allowed in a kubernetes resource name.
+	k8sNameReg = regexp.MustCompile(`[^a-z0-9-]+`)
+)
+
+// Project is what the artifacts are generated from, it is read from conf/<env>/conf.yaml of a generated project.
+type Project struct {
+	// Service is the service name in conf.yaml, the binary built by build.sh is named after it.
+	Service string
+	// Name is the name of the kubernetes resources.
+	Name  string
+	Image string
+	Env   string
+	// Kind is rpc for kitex servers and http for hertz servers.
+	Kind        string
+	Port        int
+	MetricsPort int
+	MetricsPath string
+	// HealthPort serves /healthz and /readyz for the probes, there is no probe when it is zero.
+	HealthPort int
+	// Hex is set when a kitex server serves HTTP on its port as well.
+	Hex bool
+	// Namespace is set by the kubernetes registry, clients resolve the headless service in it.
+	Namespace string
+	GoVersion string
+	Conf      string
+}
+
+type confFile struct {
+	Kitex *struct {
+		Service       string `yaml:"service"`
+		Address       string `yaml:"address"`
+		HealthAddress string `yaml:"health_address"`
+	} `yaml:"kitex"`
+	Hertz *struct {
+		Service string `yaml:"service"`
+		Address string `yaml:"address"`
+	} `yaml:"hertz"`
+	Registry struct {
+		Namespace string `yaml:"namespace"`
+		Port      int    `yaml:"port"`
+	} `yaml:"registry"`
+	Prometheus *struct {
+		Address string `yaml:"address"`
+		Path    string `yaml:"path"`
+	} `yaml:"prometheus"`
+}
+
+func Deploy(c *config.DeployArgument) error {
+	if len(c.Types) == 0 {
+		c.Types = []string{consts.Docker}
+	}
+	for _, t := range c.Types {
+		if !isSupported(t) {
+			return errs.New(errs.InvalidArgs, "unsupported deploy type %s (support %s)", t, strings.Join(Types, ", "))
+		}
+	}
+
+	dir := c.OutDir
+	if dir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("get current path failed: %s", err)
+		}
+		dir = cwd
+	}
+
+	p, err := Load(dir, c.Env)
+	if err != nil {
+		return err
+	}
+	if c.Image != "" {
+		p.Image = c.Image
+	}
+
+	for _, t := range c.Types {
+		if err = Generate(dir, t, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isSupported(t string) bool {
+	for _, s := range Types {
+		if s == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Load reads the project in dir deployed with the given GO_ENV.
+func Load(dir, env string) (*Project, error) {
+	if env == "" {
+		env = "online"
+	}
+	confPath := filepath.Join(dir, "conf", env, "conf.yaml")
+	content, err := os.ReadFile(confPath)
+	if err != nil {
+		return nil, errs.New(errs.InvalidArgs, "read %s failed, deploy must be run in a project generated by cwgo server: %s", confPath, err)
+	}
+	var cf confFile
+	if err = yaml.Unmarshal(content, &cf); err != nil {
+		return nil, errs.New(errs.InvalidArgs, "parse %s failed: %s", confPath, err)
+	}
+
+	p := &Project{
+		Env:       env,
+		Namespace: cf.Registry.Namespace,
+		GoVersion: "1",
+		Conf:      string(content),
+	}
+	var address string
+	switch {
+	case cf.Kitex != nil:
+		p.Service, address, p.Kind = cf.Kitex.Service, cf.Kitex.Address, "rpc"
+		p.Hex, _ = utils.PathExist(filepath.Join(dir, hexFile))
+		if cf.Kitex.HealthAddress != "" {
+			if p.HealthPort, err = port(cf.Kitex.HealthAddress); err != nil {
+				return nil, errs.New(errs.InvalidArgs, "invalid health address in %s: %s", confPath, err)
+			}
+		}
+	case cf.Hertz != nil:
+		p.Service, address, p.Kind = cf.Hertz.Service, cf.Hertz.Address, "http"
+	default:
+		return nil, errs.New(errs.InvalidArgs, "neither kitex nor hertz is configured in %s", confPath)
+	}
+	if p.Service == "" {
+		return nil, errs.New(errs.InvalidArgs, "the service name is missing in %s", confPath)
+	}
+	if p.Port, err = port(address); err != nil {
+		return nil, errs.New(errs.InvalidArgs, "invalid address of %s in %s: %s", p.Kind, confPath, err)
+	}
+	if cf.Hertz != nil {
+		// hertz serves the probes on its own port
+		p.HealthPort = p.Port
+	}
+	if cf.Prometheus != nil {
+		if p.MetricsPort, err = port(cf.Prometheus.Address); err != nil {
+			return nil, errs.New(errs.InvalidArgs, "invalid prometheus address in %s: %s", confPath, err)
+		}
+		p.MetricsPath = cf.Prometheus.Path
+	}
+	if p.Namespace != "" && cf.Registry.Port != 0 && cf.Registry.Port != p.Port {
+		// clients dial the pods resolved from the headless service on the registry port
+		logs.Warnf("registry.port %d differs from the server port %d in %s, clients will not reach the pods", cf.Registry.Port, p.Port, confPath)
+	}
+
+	p.Name = strings.Trim(k8sNameReg.ReplaceAllString(strings.ToLower(p.Service), "-"), "-")
+	p.Image = p.Name
+	if p.Namespace != "" && p.Name != p.Service {
+		logs.Warnf("service %s is deployed as %s, clients of the kubernetes registry resolve it by the service name", p.Service, p.Name)
+	}
+	if gomod, err := os.ReadFile(filepath.Join(dir, "go.mod")); err == nil {
+		if m := goVersionReg.FindSubmatch(gomod); m != nil {
+			p.GoVersion = string(m[1])
+		}
+	}
+	return p, nil
+}
+
+// ImageRepository is the image without its tag.
+func (p *Project) ImageRepository() string {
+	repo, _ := splitImage(p.Image)
+	return repo
+}
+
+// ImageTag is the tag of the image, it is latest when the image has no tag.
+func (p *Project) ImageTag() string {
+	_, tag := splitImage(p.Image)
+	return tag
+}
+
+func splitImage(image string) (string, string) {
+	// the port of the image registry is not a tag
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[:i], image[i+1:]
+	}
+	return image, "latest"
+}
+
+func port(address string) (int, error) {
+	_, p, err := net.SplitHostPort(address)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(p)
+}
+
+// Generate writes the artifacts of the type into dir, existing files are kept.
+func Generate(dir, typ string, p *Project) error {
+	var files map[string]string
+	switch typ {
+	case consts.Docker:
+		files = map[string]string{"Dockerfile": dockerfileTpl}
+	case consts.K8s:
+		files = map[string]string{
+			"deploy/k8s/configmap.yaml":  configMapTpl,
+			"deploy/k8s/deployment.yaml": deploymentTpl,
+			"deploy/k8s/service.yaml":    serviceTpl,
+		}
+	case consts.Helm:
+		chart := filepath.Join("deploy/helm", p.Name)
+		files = map[string]string{
+			filepath.Join(chart, "Chart.yaml"):                chartTpl,
+			filepath.Join(chart, "values.yaml"):               valuesTpl,
+			filepath.Join(chart, "templates/configmap.yaml"):  helmConfigMapTpl,
+			filepath.Join(chart, "templates/deployment.yaml"): helmDeploymentTpl,
+			filepath.Join(chart, "templates/service.yaml"):    helmServiceTpl,
+		}
+	}
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if exist, _ := utils.PathExist(path); exist {
+			logs.Warnf("%s already exists, skip it", path)
+			continue
+		}
+		if err := write(path, content, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func write(path, content string, p *Project) error {
+	// the helm templates keep {{ }} for helm itself
+	tmpl, err := template.New(filepath.Base(path)).Delims("[[", "]]").Funcs(template.FuncMap{"indent": indent}).Parse(content)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, p); err != nil {
+		return fmt.Errorf("render %s failed: %s", path, err)
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return utils.CreateFile(path, buf.String())
+}
+
+// indent prefixes every non-empty line of s with n spaces.
+func indent(n int, s string) string {
+	pad := strings.Repeat(" ", n)
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	for i, l := range lines {
+		if l != "" {
+			lines[i] = pad + l
+		}
+	}
+	return strings.Join(lines, "\n")
+}
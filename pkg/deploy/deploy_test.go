@@ -0,0 +1,139 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package deploy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+const kitexConf = `kitex:
+  service: "demo"
+  address: ":8888"
+  health_address: ":8889"
+
+registry:
+  registry_address: []
+  namespace: default
+  port: 8888
+
+prometheus:
+  address: ":9091"
+  path: "/metrics"
+`
+
+func writeProject(t *testing.T, conf string, files ...string) string {
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "conf", "online"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "conf", "online", "conf.yaml"), []byte(conf), 0o644))
+	for _, f := range files {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, f), nil, 0o644))
+	}
+	return dir
+}
+
+func TestLoad(t *testing.T) {
+	dir := writeProject(t, kitexConf, hexFile)
+	p, err := Load(dir, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "demo", p.Service)
+	assert.Equal(t, "rpc", p.Kind)
+	assert.Equal(t, 8888, p.Port)
+	assert.Equal(t, 9091, p.MetricsPort)
+	assert.Equal(t, 8889, p.HealthPort)
+	assert.Equal(t, "default", p.Namespace)
+	assert.True(t, p.Hex)
+
+	p, err = Load(writeProject(t, "hertz:\n  service: \"Web_Demo\"\n  address: \":8080\"\n"), "online")
+	assert.NoError(t, err)
+	assert.Equal(t, "http", p.Kind)
+	assert.Equal(t, "web-demo", p.Name)
+	assert.Equal(t, 8080, p.Port)
+	assert.Equal(t, 8080, p.HealthPort)
+	assert.False(t, p.Hex)
+
+	_, err = Load(dir, "dev")
+	assert.Error(t, err)
+	_, err = Load(writeProject(t, "mysql:\n  dsn: \"\"\n"), "online")
+	assert.Error(t, err)
+}
+
+func TestImage(t *testing.T) {
+	p := &Project{Image: "registry.example.com:5000/demo"}
+	assert.Equal(t, "registry.example.com:5000/demo", p.ImageRepository())
+	assert.Equal(t, "latest", p.ImageTag())
+	p.Image = "demo:v1"
+	assert.Equal(t, "demo", p.ImageRepository())
+	assert.Equal(t, "v1", p.ImageTag())
+}
+
+func TestGenerate(t *testing.T) {
+	dir := writeProject(t, kitexConf, hexFile)
+	p, err := Load(dir, "online")
+	assert.NoError(t, err)
+	for _, typ := range Types {
+		assert.NoError(t, Generate(dir, typ, p))
+	}
+
+	var svc struct {
+		Spec struct {
+			ClusterIP string `yaml:"clusterIP"`
+			Ports     []struct {
+				Name       string `yaml:"name"`
+				Port       int    `yaml:"port"`
+				TargetPort string `yaml:"targetPort"`
+			} `yaml:"ports"`
+		} `yaml:"spec"`
+	}
+	content, err := os.ReadFile(filepath.Join(dir, "deploy/k8s/service.yaml"))
+	assert.NoError(t, err)
+	assert.NoError(t, yaml.Unmarshal(content, &svc))
+	assert.Equal(t, "None", svc.Spec.ClusterIP)
+	var names []string
+	for _, port := range svc.Spec.Ports {
+		names = append(names, port.Name)
+	}
+	// the hex server exposes both rpc and http
+	assert.Equal(t, []string{"rpc", "http", "metrics"}, names)
+	assert.Equal(t, "rpc", svc.Spec.Ports[1].TargetPort)
+
+	var cm struct {
+		Data map[string]string `yaml:"data"`
+	}
+	content, err = os.ReadFile(filepath.Join(dir, "deploy/k8s/configmap.yaml"))
+	assert.NoError(t, err)
+	assert.NoError(t, yaml.Unmarshal(content, &cm))
+	assert.Equal(t, kitexConf, cm.Data["conf.yaml"])
+
+	var values map[string]interface{}
+	content, err = os.ReadFile(filepath.Join(dir, "deploy/helm/demo/values.yaml"))
+	assert.NoError(t, err)
+	assert.NoError(t, yaml.Unmarshal(content, &values))
+	assert.Equal(t, kitexConf, values["conf"])
+
+	// existing files are kept
+	dockerfile := filepath.Join(dir, "Dockerfile")
+	assert.NoError(t, os.WriteFile(dockerfile, []byte("FROM scratch\n"), 0o644))
+	assert.NoError(t, Generate(dir, consts.Docker, p))
+	content, err = os.ReadFile(dockerfile)
+	assert.NoError(t, err)
+	assert.Equal(t, "FROM scratch\n", string(content))
+}
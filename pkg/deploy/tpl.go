@@ -0,0 +1,278 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package deploy
+
+const dockerfileTpl = `# Generated by cwgo deploy, the binary is built by build.sh of the project.
+FROM golang:[[.GoVersion]] AS builder
+WORKDIR /src
+COPY go.mod go.sum* ./
+RUN go mod download
+COPY . .
+RUN CGO_ENABLED=0 bash build.sh
+
+FROM alpine:3.20
+WORKDIR /app
+COPY --from=builder /src/output/bin/[[.Service]] ./[[.Service]]
+COPY --from=builder /src/output/conf ./conf
+ENV GO_ENV=[[.Env]]
+EXPOSE [[.Port]][[if .MetricsPort]] [[.MetricsPort]][[end]]
+CMD ["./[[.Service]]"]
+`
+
+const configMapTpl = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: [[.Name]]-conf
+[[- if .Namespace]]
+  namespace: [[.Namespace]]
+[[- end]]
+data:
+  conf.yaml: |
+[[indent 4 .Conf]]
+`
+
+const deploymentTpl = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: [[.Name]]
+[[- if .Namespace]]
+  namespace: [[.Namespace]]
+[[- end]]
+  labels:
+    app: [[.Name]]
+spec:
+  replicas: 1
+  selector:
+    matchLabels:
+      app: [[.Name]]
+  template:
+    metadata:
+      labels:
+        app: [[.Name]]
+[[- if .MetricsPort]]
+      annotations:
+        prometheus.io/scrape: "true"
+        prometheus.io/port: "[[.MetricsPort]]"
+        prometheus.io/path: "[[.MetricsPath]]"
+[[- end]]
+    spec:
+      containers:
+        - name: [[.Name]]
+          image: [[.Image]]
+          env:
+            - name: GO_ENV
+              value: [[.Env]]
+          ports:
+            - name: [[.Kind]]
+              containerPort: [[.Port]]
+[[- if and .HealthPort (ne .HealthPort .Port)]]
+            - name: health
+              containerPort: [[.HealthPort]]
+[[- end]]
+[[- if .MetricsPort]]
+            - name: metrics
+              containerPort: [[.MetricsPort]]
+[[- end]]
+[[- if .HealthPort]]
+          livenessProbe:
+            httpGet:
+              path: /healthz
+              port: [[.HealthPort]]
+          readinessProbe:
+            httpGet:
+              path: /readyz
+              port: [[.HealthPort]]
+[[- end]]
+          volumeMounts:
+            - name: conf
+              mountPath: /app/conf/[[.Env]]
+      volumes:
+        - name: conf
+          configMap:
+            name: [[.Name]]-conf
+`
+
+const serviceTpl = `apiVersion: v1
+kind: Service
+metadata:
+  name: [[.Name]]
+[[- if .Namespace]]
+  namespace: [[.Namespace]]
+[[- end]]
+spec:
+[[- if .Namespace]]
+  # clients of the kubernetes registry resolve the pods from the headless service
+  clusterIP: None
+[[- end]]
+  selector:
+    app: [[.Name]]
+  ports:
+    - name: [[.Kind]]
+      port: [[.Port]]
+      targetPort: [[.Kind]]
+[[- if .Hex]]
+    # the hex trans handler serves HTTP on the RPC port as well
+    - name: http
+      port: 80
+      targetPort: [[.Kind]]
+[[- end]]
+[[- if .MetricsPort]]
+    - name: metrics
+      port: [[.MetricsPort]]
+      targetPort: metrics
+[[- end]]
+`
+
+const chartTpl = `apiVersion: v2
+name: [[.Name]]
+description: A Helm chart of [[.Service]] generated by cwgo
+type: application
+version: 0.1.0
+appVersion: "[[.ImageTag]]"
+`
+
+const valuesTpl = `name: [[.Name]]
+replicaCount: 1
+
+image:
+  repository: [[.ImageRepository]]
+  tag: "[[.ImageTag]]"
+  pullPolicy: IfNotPresent
+
+# env is the GO_ENV of the service, conf is mounted as conf/<env>/conf.yaml
+env: [[.Env]]
+
+# the ports are those of conf, keep them in sync when the addresses are changed
+service:
+[[- if .Namespace]]
+  # clients of the kubernetes registry resolve the pods from the headless service,
+  # install the chart into the [[.Namespace]] namespace of registry.namespace
+  headless: true
+[[- else]]
+  headless: false
+[[- end]]
+  port: [[.Port]]
+[[- if .Hex]]
+  # the hex trans handler serves HTTP on the RPC port as well
+  httpPort: 80
+[[- end]]
+
+# the probes request /healthz and /readyz at the port, they are disabled when it is 0
+health:
+  port: [[.HealthPort]]
+
+metrics:
+  port: [[.MetricsPort]]
+  path: "[[.MetricsPath]]"
+
+conf: |
+[[indent 2 .Conf]]
+`
+
+const helmConfigMapTpl = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ .Values.name }}-conf
+data:
+  conf.yaml: |
+{{ .Values.conf | indent 4 }}
+`
+
+const helmDeploymentTpl = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ .Values.name }}
+  labels:
+    app: {{ .Values.name }}
+spec:
+  replicas: {{ .Values.replicaCount }}
+  selector:
+    matchLabels:
+      app: {{ .Values.name }}
+  template:
+    metadata:
+      labels:
+        app: {{ .Values.name }}
+      annotations:
+        checksum/conf: {{ .Values.conf | sha256sum }}
+        {{- if .Values.metrics.port }}
+        prometheus.io/scrape: "true"
+        prometheus.io/port: "{{ .Values.metrics.port }}"
+        prometheus.io/path: {{ .Values.metrics.path | quote }}
+        {{- end }}
+    spec:
+      containers:
+        - name: {{ .Values.name }}
+          image: "{{ .Values.image.repository }}:{{ .Values.image.tag }}"
+          imagePullPolicy: {{ .Values.image.pullPolicy }}
+          env:
+            - name: GO_ENV
+              value: {{ .Values.env | quote }}
+          ports:
+            - name: [[.Kind]]
+              containerPort: {{ .Values.service.port }}
+            {{- if and .Values.health.port (ne (int .Values.health.port) (int .Values.service.port)) }}
+            - name: health
+              containerPort: {{ .Values.health.port }}
+            {{- end }}
+            {{- if .Values.metrics.port }}
+            - name: metrics
+              containerPort: {{ .Values.metrics.port }}
+            {{- end }}
+          {{- if .Values.health.port }}
+          livenessProbe:
+            httpGet:
+              path: /healthz
+              port: {{ .Values.health.port }}
+          readinessProbe:
+            httpGet:
+              path: /readyz
+              port: {{ .Values.health.port }}
+          {{- end }}
+          volumeMounts:
+            - name: conf
+              mountPath: /app/conf/{{ .Values.env }}
+      volumes:
+        - name: conf
+          configMap:
+            name: {{ .Values.name }}-conf
+`
+
+const helmServiceTpl = `apiVersion: v1
+kind: Service
+metadata:
+  name: {{ .Values.name }}
+spec:
+  {{- if .Values.service.headless }}
+  clusterIP: None
+  {{- end }}
+  selector:
+    app: {{ .Values.name }}
+  ports:
+    - name: [[.Kind]]
+      port: {{ .Values.service.port }}
+      targetPort: [[.Kind]]
+    {{- if .Values.service.httpPort }}
+    - name: http
+      port: {{ .Values.service.httpPort }}
+      targetPort: [[.Kind]]
+    {{- end }}
+    {{- if .Values.metrics.port }}
+    - name: metrics
+      port: {{ .Values.metrics.port }}
+      targetPort: metrics
+    {{- end }}
+`
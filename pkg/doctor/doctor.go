@@ -0,0 +1,270 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package doctor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/cwgo/meta"
+	"github.com/cloudwego/cwgo/pkg/common/utils"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/cloudwego/cwgo/tpl"
+	hzMeta "github.com/cloudwego/hertz/cmd/hz/meta"
+	"github.com/cloudwego/kitex"
+	thriftgoVersion "github.com/cloudwego/thriftgo/version"
+)
+
+type Status string
+
+const (
+	OK   Status = "ok"
+	Warn Status = "warn"
+	Fail Status = "fail"
+)
+
+type Check struct {
+	Name   string `json:"name"`
+	Status Status `json:"status"`
+	Detail string `json:"detail"`
+	Hint   string `json:"hint,omitempty"`
+}
+
+type Component struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type Report struct {
+	Checks []Check `json:"checks"`
+	// Build lists the versions cwgo was built against.
+	Build []Component `json:"build"`
+}
+
+// Failed returns the number of failed checks.
+func (r *Report) Failed() int {
+	n := 0
+	for _, c := range r.Checks {
+		if c.Status == Fail {
+			n++
+		}
+	}
+	return n
+}
+
+// Doctor prints the report of Diagnose for the current path to w, it fails when a check fails.
+func Doctor(c *config.DoctorArgument, w io.Writer) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("get current path failed: %s", err)
+	}
+	r := Diagnose(cwd, c.GoMod)
+	if c.JSON {
+		err = r.PrintJSON(w)
+	} else {
+		err = r.Print(w)
+	}
+	if err != nil {
+		return err
+	}
+	if n := r.Failed(); n > 0 {
+		return fmt.Errorf("%d of %d checks failed", n, len(r.Checks))
+	}
+	return nil
+}
+
+// Diagnose checks the environment the generators rely on, module is the name given by the '-module' flag.
+func Diagnose(cwd, module string) *Report {
+	r := &Report{
+		Build: []Component{
+			{Name: meta.Name, Version: meta.Version},
+			{Name: "go", Version: runtime.Version()},
+			{Name: consts.Kitex, Version: kitex.Version},
+			{Name: string(consts.Hz), Version: hzMeta.Version},
+			{Name: hzMeta.TpCompilerThrift, Version: thriftgoVersion.ThriftgoVersion},
+		},
+	}
+	r.Checks = append(r.Checks, checkGo())
+	gopath, check := checkGOPATH()
+	r.Checks = append(r.Checks, check)
+	r.Checks = append(r.Checks, checkGoMod(cwd, gopath, module))
+	r.Checks = append(r.Checks,
+		checkTool(hzMeta.TpCompilerThrift, "go install github.com/cloudwego/thriftgo@latest, cwgo also installs it on first use"),
+		checkTool(hzMeta.TpCompilerProto, "install protoc from https://github.com/protocolbuffers/protobuf/releases, only needed for protobuf IDL"),
+		checkTool(consts.ProtocGenGo, "go install google.golang.org/protobuf/cmd/protoc-gen-go@latest, only needed for protobuf IDL"),
+		checkTemplateDir(consts.Kitex, tpl.KitexDir),
+		checkTemplateDir(consts.Hertz, tpl.HertzDir),
+	)
+	return r
+}
+
+func checkGo() Check {
+	c := Check{Name: "go"}
+	out, err := run(consts.Go, consts.Env, consts.GOVERSION)
+	if err != nil {
+		c.Status, c.Detail = Fail, err.Error()
+		c.Hint = "install go from https://go.dev/dl and add it to PATH"
+		return c
+	}
+	c.Status, c.Detail = OK, out
+	return c
+}
+
+func checkGOPATH() (string, Check) {
+	c := Check{Name: "GOPATH"}
+	gopath, err := utils.GetGOPATH()
+	if err != nil || gopath == "" {
+		c.Status, c.Detail = Fail, "GOPATH is not set"
+		if err != nil {
+			c.Detail = fmt.Sprintf("get gopath failed: %s", err)
+		}
+		c.Hint = "set GOPATH, e.g. go env -w GOPATH=$HOME/go"
+		return "", c
+	}
+	c.Status, c.Detail = OK, gopath
+	if isExist, _ := utils.PathExist(filepath.Join(gopath, consts.Src)); !isExist {
+		c.Detail = fmt.Sprintf("%s, GOPATH/src does not exist", gopath)
+	}
+	return gopath, c
+}
+
+// checkGoMod mirrors the module checks of the generators: under GOPATH/src the module name defaults to
+// the path relative to GOPATH/src, elsewhere it must be given with '-module' unless go.mod exists.
+func checkGoMod(cwd, gopath, module string) Check {
+	c := Check{Name: "go.mod"}
+	goMod, path, found := utils.SearchGoMod(cwd, true)
+
+	var goPkg string
+	if gopath != "" {
+		gosrc := filepath.Join(gopath, consts.Src)
+		if strings.HasPrefix(cwd, gosrc) {
+			if rel, err := filepath.Rel(gosrc, cwd); err == nil {
+				goPkg = filepath.ToSlash(rel)
+			}
+		}
+	}
+	if module == "" {
+		module = goPkg
+	}
+
+	switch {
+	case goPkg != "" && module != goPkg:
+		c.Status = Fail
+		c.Detail = fmt.Sprintf("module name %s is not the same with GoPkg under GOPATH: %s", module, goPkg)
+		c.Hint = "move the project out of GOPATH/src or use its path relative to GOPATH/src as module name"
+	case found && module != "" && module != goMod:
+		c.Status = Fail
+		c.Detail = fmt.Sprintf("module name %s is not consist with the name defined in go.mod (%s from %s)", module, goMod, path)
+		c.Hint = fmt.Sprintf("pass '-module %s' or fix the module directive of go.mod", goMod)
+	case found:
+		c.Status, c.Detail = OK, fmt.Sprintf("%s (%s)", goMod, path)
+	case module != "":
+		c.Status = OK
+		c.Detail = fmt.Sprintf("go.mod not found, the generators will create it for %s", module)
+	default:
+		c.Status, c.Detail = Warn, "go.mod not found and not under GOPATH/src"
+		c.Hint = "run 'go mod init <module>' or pass '-module' to the generators"
+	}
+	return c
+}
+
+func checkTool(tool, hint string) Check {
+	c := Check{Name: tool}
+	path, isExist, err := utils.FindTool(tool)
+	if err != nil || !isExist {
+		c.Status, c.Detail, c.Hint = Warn, fmt.Sprintf("%s is not installed", tool), hint
+		if err != nil {
+			c.Detail = err.Error()
+		}
+		return c
+	}
+	version, err := run(path, "--version")
+	if err != nil {
+		c.Status, c.Detail, c.Hint = Warn, fmt.Sprintf("%s: %s", path, err), hint
+		return c
+	}
+	c.Status, c.Detail = OK, fmt.Sprintf("%s (%s)", version, path)
+	return c
+}
+
+func checkTemplateDir(name, dir string) Check {
+	c := Check{Name: name + " template dir"}
+	if dir == "" {
+		c.Status, c.Detail = Fail, "template dir is not initialized"
+		c.Hint = "make sure the temporary directory is writable, or set TMPDIR"
+		return c
+	}
+	f, err := os.CreateTemp(dir, "doctor-*")
+	if err != nil {
+		c.Status, c.Detail = Fail, err.Error()
+		c.Hint = "make sure the temporary directory is writable, or set TMPDIR"
+		return c
+	}
+	f.Close()
+	os.Remove(f.Name())
+	c.Status, c.Detail = OK, dir
+	return c
+}
+
+// run returns the trimmed output of a short command.
+func run(name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func (r *Report) Print(w io.Writer) error {
+	for _, c := range r.Checks {
+		if _, err := fmt.Fprintf(w, "[%-4s] %s: %s\n", strings.ToUpper(string(c.Status)), c.Name, c.Detail); err != nil {
+			return err
+		}
+		if c.Hint != "" {
+			if _, err := fmt.Fprintf(w, "       hint: %s\n", c.Hint); err != nil {
+				return err
+			}
+		}
+	}
+	if _, err := fmt.Fprintln(w, "\nbuilt with:"); err != nil {
+		return err
+	}
+	for _, b := range r.Build {
+		if _, err := fmt.Fprintf(w, "  %s %s\n", b.Name, b.Version); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Report) PrintJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(r)
+}
@@ -0,0 +1,138 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package doctor
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/cwgo/tpl"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeTools makes dir the only PATH, the tools print their name and version or fail when version is empty.
+func fakeTools(t *testing.T, dir string, tools map[string]string) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake tools are shell scripts")
+	}
+	for tool, version := range tools {
+		script := "#!/bin/sh\necho " + version + "\n"
+		if version == "" {
+			script = "#!/bin/sh\nexit 1\n"
+		}
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, tool), []byte(script), 0o755))
+	}
+	t.Setenv("PATH", dir)
+	t.Setenv("GOPATH", t.TempDir())
+}
+
+func TestCheckGoMod(t *testing.T) {
+	gopath := t.TempDir()
+	project := filepath.Join(gopath, "src", "example.com", "demo")
+	assert.NoError(t, os.MkdirAll(project, 0o755))
+	outside := t.TempDir()
+
+	// under GOPATH/src without go.mod, the module name defaults to the relative path
+	assert.Equal(t, OK, checkGoMod(project, gopath, "").Status)
+	assert.Equal(t, Fail, checkGoMod(project, gopath, "example.com/other").Status)
+
+	assert.Equal(t, Warn, checkGoMod(outside, gopath, "").Status)
+	assert.Equal(t, OK, checkGoMod(outside, gopath, "example.com/demo").Status)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(outside, "go.mod"), []byte("module example.com/demo\n"), 0o644))
+	assert.Equal(t, OK, checkGoMod(outside, gopath, "").Status)
+	assert.Equal(t, OK, checkGoMod(outside, gopath, "example.com/demo").Status)
+	c := checkGoMod(outside, gopath, "example.com/other")
+	assert.Equal(t, Fail, c.Status)
+	assert.Contains(t, c.Hint, "-module example.com/demo")
+}
+
+func TestCheckTool(t *testing.T) {
+	fakeTools(t, t.TempDir(), map[string]string{"thriftgo": "thriftgo 0.3.6", "protoc": ""})
+
+	c := checkTool("thriftgo", "install thriftgo")
+	assert.Equal(t, OK, c.Status)
+	assert.Contains(t, c.Detail, "thriftgo 0.3.6")
+	assert.Empty(t, c.Hint)
+
+	// the tool is found but can't tell its version
+	c = checkTool("protoc", "install protoc")
+	assert.Equal(t, Warn, c.Status)
+	assert.Equal(t, "install protoc", c.Hint)
+
+	c = checkTool("protoc-gen-go", "install protoc-gen-go")
+	assert.Equal(t, Warn, c.Status)
+	assert.Equal(t, "protoc-gen-go is not installed", c.Detail)
+	assert.Equal(t, "install protoc-gen-go", c.Hint)
+}
+
+func TestCheckTemplateDir(t *testing.T) {
+	dir := t.TempDir()
+	c := checkTemplateDir("kitex", dir)
+	assert.Equal(t, OK, c.Status)
+	assert.Equal(t, dir, c.Detail)
+	// the probe file is removed
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+
+	c = checkTemplateDir("kitex", "")
+	assert.Equal(t, Fail, c.Status)
+	assert.Equal(t, "kitex template dir", c.Name)
+	assert.NotEmpty(t, c.Hint)
+
+	c = checkTemplateDir("hertz", filepath.Join(dir, "missing"))
+	assert.Equal(t, Fail, c.Status)
+	assert.NotEmpty(t, c.Hint)
+}
+
+func TestDoctor(t *testing.T) {
+	assert.NoError(t, tpl.Init())
+	defer tpl.Cleanup()
+	fakeTools(t, t.TempDir(), map[string]string{"go": "go1.22.12", "thriftgo": "thriftgo 0.3.6", "protoc": "libprotoc 25.1", "protoc-gen-go": "protoc-gen-go v1.33.0"})
+	project := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(project, "go.mod"), []byte("module example.com/demo\n"), 0o644))
+	cwd, _ := os.Getwd()
+	assert.NoError(t, os.Chdir(project))
+	defer os.Chdir(cwd)
+
+	var buf bytes.Buffer
+	assert.NoError(t, Doctor(&config.DoctorArgument{JSON: true}, &buf))
+	r := new(Report)
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), r))
+	var names []string
+	for _, c := range r.Checks {
+		assert.Equal(t, OK, c.Status, c.Name)
+		names = append(names, c.Name)
+	}
+	assert.Equal(t, []string{"go", "GOPATH", "go.mod", "thriftgo", "protoc", "protoc-gen-go", "kitex template dir", "hertz template dir"}, names)
+	assert.Equal(t, "go1.22.12", r.Checks[0].Detail)
+	assert.NotEmpty(t, r.Build)
+
+	// a module not matching go.mod fails, the text report shows the hint
+	buf.Reset()
+	err := Doctor(&config.DoctorArgument{GoMod: "example.com/other"}, &buf)
+	assert.EqualError(t, err, "1 of 8 checks failed")
+	assert.Contains(t, buf.String(), "[FAIL] go.mod: module name example.com/other is not consist with the name defined in go.mod")
+	assert.Contains(t, buf.String(), "       hint: pass '-module example.com/demo'")
+	assert.Contains(t, buf.String(), "\nbuilt with:\n")
+}
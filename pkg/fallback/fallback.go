@@ -18,16 +18,20 @@ package fallback
 
 import (
 	"bytes"
+	"errors"
 	"os"
 	"strings"
 
 	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/cwgo/pkg/common/errs"
 	"github.com/cloudwego/cwgo/pkg/consts"
 	"github.com/cloudwego/hertz/cmd/hz/app"
+	"github.com/cloudwego/hertz/cmd/hz/meta"
 	"github.com/cloudwego/hertz/cmd/hz/util/logs"
 	"github.com/cloudwego/kitex"
 	kargs "github.com/cloudwego/kitex/tool/cmd/kitex/args"
 	"github.com/cloudwego/kitex/tool/internal_pkg/pluginmode/thriftgo"
+	"github.com/urfave/cli/v2"
 )
 
 func Fallback(c *config.FallbackArgument) error {
@@ -44,10 +48,10 @@ func Fallback(c *config.FallbackArgument) error {
 			if args.Use != "" {
 				out := strings.TrimSpace(out.String())
 				if strings.HasSuffix(out, thriftgo.TheUseOptionMessage) {
-					os.Exit(0)
+					return nil
 				}
 			}
-			os.Exit(1)
+			return errs.New(errs.IDLParse, "kitex generation failed: %s\n%s", err, strings.TrimSpace(out.String()))
 		}
 	case consts.Hz:
 		os.Args = c.Args
@@ -55,11 +59,29 @@ func Fallback(c *config.FallbackArgument) error {
 			logs.Flush()
 		}()
 
-		cli := app.Init()
-		err := cli.Run(os.Args)
-		if err != nil {
-			logs.Errorf("%v\n", err)
+		hzCli := app.Init()
+		// hz exits on failures by default, its exit codes are classified by hzError instead
+		hzCli.ExitErrHandler = func(*cli.Context, error) {}
+		if err := hzCli.Run(os.Args); err != nil {
+			return hzError(err)
 		}
 	}
 	return nil
 }
+
+// hzError classifies the errors returned by hz with its exit codes.
+func hzError(err error) error {
+	var ec cli.ExitCoder
+	if !errors.As(err, &ec) {
+		return err
+	}
+	switch ec.ExitCode() {
+	case meta.LoadError:
+		return errs.Wrap(errs.Conflict, err)
+	case meta.PluginError:
+		return errs.Wrap(errs.IDLParse, err)
+	case meta.PersistError:
+		return errs.Wrap(errs.PostProcess, err)
+	}
+	return err
+}
@@ -0,0 +1,157 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gen
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/cwgo/pkg/client"
+	"github.com/cloudwego/cwgo/pkg/common/utils"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/cloudwego/cwgo/pkg/curd/doc"
+	"github.com/cloudwego/cwgo/pkg/job"
+	"github.com/cloudwego/cwgo/pkg/model"
+	"github.com/cloudwego/cwgo/pkg/server"
+	"github.com/cloudwego/hertz/cmd/hz/util/logs"
+)
+
+// Gen regenerates every component described in the manifest.
+func Gen(c *config.GenArgument) error {
+	if c.File == "" {
+		c.File = consts.ManifestFile
+	}
+	m, err := config.LoadManifest(c.File)
+	if err != nil {
+		return err
+	}
+	if c.SandboxDir != "" {
+		if err = m.Sandbox(c.SandboxDir); err != nil {
+			return err
+		}
+	}
+	utils.SetHzVerboseLog(c.Verbose)
+
+	for _, t := range Tasks(m, c.Verbose) {
+		logs.Infof("cwgo gen: %s", t.Name)
+		run := t.Run
+		if c.WrapTask != nil {
+			// the task runs before the next iteration, t is not shared
+			run = func() error {
+				fn, err := c.WrapTask(t.Args, t.Run)
+				if err != nil {
+					return err
+				}
+				return fn()
+			}
+		}
+		if err = runIn(t.Dir, run); err != nil {
+			return fmt.Errorf("generate %s failed: %w", t.Name, err)
+		}
+	}
+	return nil
+}
+
+// Task is a single generation step of the manifest.
+type Task struct {
+	Name string
+	Dir  string
+	// Args is the argument of the generator, e.g. *config.ServerArgument.
+	Args interface{}
+	Run  func() error
+}
+
+// Tasks returns the generation steps in dependency order:
+// db models and doc models come first since services depend on them,
+// then services, then clients which may reuse the kitex_gen of the services, and jobs at last.
+func Tasks(m *config.Manifest, verbose bool) []Task {
+	var tasks []Task
+	for _, spec := range m.Models {
+		ma := m.ModelArgument(spec)
+		tasks = append(tasks, Task{
+			Name: fmt.Sprintf("model %s", modelName(ma)),
+			Dir:  m.EntryDir(spec.Dir),
+			Args: ma,
+			Run:  func() error { return model.Model(ma) },
+		})
+	}
+	for _, spec := range m.Docs {
+		da := m.DocArgument(spec)
+		da.Verbose = da.Verbose || verbose
+		tasks = append(tasks, Task{
+			Name: fmt.Sprintf("doc %s", filepath.Base(da.IdlPath)),
+			Dir:  m.EntryDir(spec.Dir),
+			Args: da,
+			Run:  func() error { return doc.Doc(da) },
+		})
+	}
+	for _, spec := range m.Services {
+		sa := m.ServerArgument(spec)
+		sa.Verbose = sa.Verbose || verbose
+		tasks = append(tasks, Task{
+			Name: fmt.Sprintf("server %s", sa.ServerName),
+			Dir:  m.EntryDir(spec.Dir),
+			Args: sa,
+			Run:  func() error { return server.Server(sa) },
+		})
+	}
+	for _, spec := range m.Clients {
+		ca := m.ClientArgument(spec)
+		ca.Verbose = ca.Verbose || verbose
+		tasks = append(tasks, Task{
+			Name: fmt.Sprintf("client %s", ca.ServerName),
+			Dir:  m.EntryDir(spec.Dir),
+			Args: ca,
+			Run:  func() error { return client.Client(ca) },
+		})
+	}
+	for _, spec := range m.Jobs {
+		ja := m.JobArgument(spec)
+		tasks = append(tasks, Task{
+			Name: fmt.Sprintf("job %v", ja.JobName),
+			Dir:  m.EntryDir(spec.Dir),
+			Args: ja,
+			Run:  func() error { return job.Job(ja) },
+		})
+	}
+	return tasks
+}
+
+func modelName(ma *config.ModelArgument) string {
+	if ma.SQLDir != "" {
+		return filepath.Base(ma.SQLDir)
+	}
+	return ma.Type
+}
+
+// runIn runs fn with dir as the working directory, generators rely on it to locate go.mod and output paths.
+func runIn(dir string, fn func() error) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("get current path failed: %s", err)
+	}
+	if err = os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	if err = os.Chdir(dir); err != nil {
+		return err
+	}
+	defer os.Chdir(cwd)
+	return fn()
+}
@@ -0,0 +1,46 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gen
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudwego/cwgo/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTasksOrder(t *testing.T) {
+	m := &config.Manifest{
+		Dir:      "/repo",
+		Jobs:     []config.JobSpec{{JobName: []string{"clean"}}},
+		Clients:  []config.ClientSpec{{ServerName: "user", IdlPath: "user.thrift"}},
+		Services: []config.ServerSpec{{ServerName: "user", IdlPath: "user.thrift", Dir: "app/user"}},
+		Docs:     []config.DocSpec{{IdlPath: "doc.thrift"}},
+		Models:   []config.ModelSpec{{DSN: "dsn", DBType: "mysql"}},
+	}
+
+	var names []string
+	for _, task := range Tasks(m, false) {
+		names = append(names, task.Name)
+	}
+	assert.Equal(t, []string{"model mysql", "doc doc.thrift", "server user", "client user", "job [clean]"}, names)
+	assert.Equal(t, filepath.Join("/repo", "app/user"), Tasks(m, false)[2].Dir)
+	sa, ok := Tasks(m, false)[2].Args.(*config.ServerArgument)
+	assert.True(t, ok)
+	assert.Equal(t, "user", sa.ServerName)
+}
@@ -18,7 +18,6 @@ package job
 
 import (
 	"bytes"
-	"errors"
 	"fmt"
 	"go/ast"
 	"go/parser"
@@ -31,11 +30,12 @@ import (
 	"text/template"
 
 	"github.com/cloudwego/cwgo/meta"
+	"github.com/cloudwego/cwgo/pkg/common/errs"
 
 	"github.com/cloudwego/cwgo/config"
 	"github.com/cloudwego/cwgo/pkg/common/utils"
 	"github.com/cloudwego/cwgo/pkg/consts"
-	"github.com/cloudwego/kitex/tool/internal_pkg/log"
+	"github.com/cloudwego/cwgo/tpl"
 
 	"golang.org/x/tools/go/ast/astutil"
 )
@@ -44,8 +44,11 @@ func Job(c *config.JobArgument) error {
 	if err := check(c); err != nil {
 		return err
 	}
+	if err := initGoMod(c.GoMod); err != nil {
+		return err
+	}
 
-	err := generateJobFile(c.GoMod, c.PackagePrefix, c.JobName, c.OutDir)
+	err := generateJobFile(c.GoMod, c.PackagePrefix, c.JobName, c.OutDir, c.TemplateVars)
 	if err != nil {
 		return err
 	}
@@ -53,9 +56,30 @@ func Job(c *config.JobArgument) error {
 	return nil
 }
 
+// Check validates the arguments the same way the generator does, the derived fields are filled.
+// Unlike the generator, it never touches the file system.
+func Check(c *config.JobArgument) error {
+	return check(c)
+}
+
+// initGoMod creates go.mod if the output is not in a module yet.
+func initGoMod(module string) error {
+	curpath, err := filepath.Abs(consts.CurrentDir)
+	if err != nil {
+		return fmt.Errorf("get current path failed: %s", err)
+	}
+	if _, _, ok := utils.SearchGoMod(curpath, true); ok {
+		return nil
+	}
+	if err = utils.InitGoMod(module); err != nil {
+		return fmt.Errorf("init go mod failed: %w", err)
+	}
+	return nil
+}
+
 func check(c *config.JobArgument) (err error) {
 	if len(c.JobName) == 0 {
-		return errors.New("job name is empty")
+		return errs.New(errs.InvalidArgs, "job name is empty")
 	}
 
 	c.OutDir, err = filepath.Abs(c.OutDir)
@@ -74,20 +98,17 @@ func check(c *config.JobArgument) (err error) {
 	gosrc := filepath.Join(gopath, consts.Src)
 	gosrc, err = filepath.Abs(gosrc)
 	if err != nil {
-		log.Warn("Get GOPATH/src path failed:", err.Error())
-		os.Exit(1)
+		return fmt.Errorf("get GOPATH/src path failed: %s", err)
 	}
 	curpath, err := filepath.Abs(consts.CurrentDir)
 	if err != nil {
-		log.Warn("Get current path failed:", err.Error())
-		os.Exit(1)
+		return fmt.Errorf("get current path failed: %s", err)
 	}
 
 	if strings.HasPrefix(curpath, gosrc) {
 		goPkg := ""
 		if goPkg, err = filepath.Rel(gosrc, curpath); err != nil {
-			log.Warn("Get GOPATH/src relpath failed:", err.Error())
-			os.Exit(1)
+			return fmt.Errorf("get GOPATH/src relpath failed: %s", err)
 		}
 
 		if c.GoMod == "" {
@@ -102,19 +123,18 @@ func check(c *config.JobArgument) (err error) {
 			if utils.IsWindows() {
 				goPkgSlash := strings.ReplaceAll(goPkg, consts.BackSlash, consts.Slash)
 				if goPkgSlash != c.GoMod {
-					return fmt.Errorf("module name: %s is not the same with GoPkg under GoPath: %s", c.GoMod, goPkgSlash)
+					return errs.New(errs.Conflict, "module name: %s is not the same with GoPkg under GoPath: %s", c.GoMod, goPkgSlash)
 				}
 			} else {
 				if c.GoMod != goPkg {
-					return fmt.Errorf("module name: %s is not the same with GoPkg under GoPath: %s", c.GoMod, goPkg)
+					return errs.New(errs.Conflict, "module name: %s is not the same with GoPkg under GoPath: %s", c.GoMod, goPkg)
 				}
 			}
 		}
 	}
 
 	if !strings.HasPrefix(curpath, gosrc) && c.GoMod == "" {
-		log.Warn("Outside of $GOPATH. Please specify a module name with the '-module' flag.")
-		os.Exit(1)
+		return errs.New(errs.InvalidArgs, "outside of $GOPATH. Please specify a module name with the '-module' flag")
 	}
 
 	if c.GoMod != "" {
@@ -123,23 +143,16 @@ func check(c *config.JobArgument) (err error) {
 		if ok {
 			// go.mod exists
 			if module != c.GoMod {
-				log.Warnf("The module name given by the '-module' option ('%s') is not consist with the name defined in go.mod ('%s' from %s)\n",
+				return errs.New(errs.Conflict, "the module name given by the '-module' option ('%s') is not consist with the name defined in go.mod ('%s' from %s)",
 					c.GoMod, module, path)
-				os.Exit(1)
 			}
 			if c.PackagePrefix, err = filepath.Rel(path, c.OutDir); err != nil {
-				log.Warn("Get package prefix failed:", err.Error())
-				os.Exit(1)
+				return fmt.Errorf("get package prefix failed: %s", err)
 			}
 			c.PackagePrefix = filepath.Join(c.GoMod, c.PackagePrefix)
 		} else {
-			if err = utils.InitGoMod(c.GoMod); err != nil {
-				log.Warn("Init go mod failed:", err.Error())
-				os.Exit(1)
-			}
 			if c.PackagePrefix, err = filepath.Rel(curpath, c.OutDir); err != nil {
-				log.Warn("Get package prefix failed:", err.Error())
-				os.Exit(1)
+				return fmt.Errorf("get package prefix failed: %s", err)
 			}
 			c.PackagePrefix = filepath.Join(c.GoMod, c.PackagePrefix)
 		}
@@ -294,7 +307,7 @@ func createRunCall(jobName string) []ast.Stmt {
 	}
 }
 
-func generateJobFile(GoModule, PackagePrefix string, jobNames []string, outDir string) error {
+func generateJobFile(GoModule, PackagePrefix string, jobNames []string, outDir string, vars map[string]string) error {
 	// Ensure the base output directory exists
 	err := os.MkdirAll(outDir, 0o755)
 	if err != nil {
@@ -309,7 +322,7 @@ func generateJobFile(GoModule, PackagePrefix string, jobNames []string, outDir s
 	}
 
 	mainGoPath := filepath.Join(cmdDir, "main.go")
-	tmpl, err := template.New("job_main").Parse(jobMainTemplate)
+	tmpl, err := template.New("job_main").Funcs(tpl.VarFuncs(vars)).Parse(jobMainTemplate)
 	if err != nil {
 		return err
 	}
@@ -345,7 +358,7 @@ func generateJobFile(GoModule, PackagePrefix string, jobNames []string, outDir s
 
 	// Create or append to schedule.go
 	scheduleGoPath := filepath.Join(outDir, "schedule.go")
-	scheduleTmpl, err := template.New("job_schedule").Parse(jobScheduleTemplate)
+	scheduleTmpl, err := template.New("job_schedule").Funcs(tpl.VarFuncs(vars)).Parse(jobScheduleTemplate)
 	if err != nil {
 		return err
 	}
@@ -386,7 +399,7 @@ func generateJobFile(GoModule, PackagePrefix string, jobNames []string, outDir s
 
 	// Create run.sh
 	runShPath := filepath.Join(scriptsDir, "run.sh")
-	scriptTmpl, err := template.New("job_script").Parse(scriptTemplate)
+	scriptTmpl, err := template.New("job_script").Funcs(tpl.VarFuncs(vars)).Parse(scriptTemplate)
 	if err != nil {
 		return err
 	}
@@ -416,7 +429,7 @@ func generateJobFile(GoModule, PackagePrefix string, jobNames []string, outDir s
 
 		// Create or append to job.go
 		jobFilePath := filepath.Join(internalJobDir, "job.go")
-		jobTmpl, err := template.New("job").Parse(jobTemplate)
+		jobTmpl, err := template.New("job").Funcs(tpl.VarFuncs(vars)).Parse(jobTemplate)
 		if err != nil {
 			return err
 		}
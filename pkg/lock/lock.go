@@ -0,0 +1,210 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package lock records what a generation depended on in the .cwgo.lock file,
+// so a later regeneration can tell whether it would produce different code.
+package lock
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sort"
+
+	"github.com/cloudwego/cwgo/meta"
+	"github.com/cloudwego/cwgo/pkg/common/errs"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	hzMeta "github.com/cloudwego/hertz/cmd/hz/meta"
+	"github.com/cloudwego/kitex"
+	thriftgoVersion "github.com/cloudwego/thriftgo/version"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	Kitex    = consts.Kitex
+	Hz       = string(consts.Hz)
+	Thriftgo = hzMeta.TpCompilerThrift
+	GormGen  = "gorm_gen"
+
+	gormGenPath = "gorm.io/gen"
+	header      = "# Code generated by cwgo. DO NOT EDIT.\n"
+)
+
+type Lock struct {
+	Entries []*Entry `yaml:"generations"`
+}
+
+// Entry records one generation, it is identified by the command and the name.
+type Entry struct {
+	Command string `yaml:"command"`
+	Name    string `yaml:"name"`
+	// Version is the version of cwgo.
+	Version string `yaml:"cwgo"`
+	// Tools are the versions of the embedded generators.
+	Tools          map[string]string `yaml:"tools,omitempty"`
+	Template       string            `yaml:"template,omitempty"`
+	TemplateCommit string            `yaml:"template_commit,omitempty"`
+	// IDL maps every IDL, includes too, to its sha256 checksum.
+	IDL map[string]string `yaml:"idl,omitempty"`
+	// Args are the normalized arguments, in the form of a cwgo.yaml entry.
+	Args interface{} `yaml:"args"`
+}
+
+// NewEntry returns an entry with the versions of the running cwgo and of the given tools.
+func NewEntry(command, name string, tools ...string) *Entry {
+	e := &Entry{
+		Command: command,
+		Name:    name,
+		Version: meta.Version,
+	}
+	if len(tools) > 0 {
+		e.Tools = make(map[string]string, len(tools))
+		for _, t := range tools {
+			e.Tools[t] = ToolVersion(t)
+		}
+	}
+	return e
+}
+
+// ToolVersion returns the version of an embedded generator.
+func ToolVersion(tool string) string {
+	switch tool {
+	case Kitex:
+		return kitex.Version
+	case Hz:
+		return hzMeta.Version
+	case Thriftgo:
+		return thriftgoVersion.ThriftgoVersion
+	case GormGen:
+		return moduleVersion(gormGenPath)
+	}
+	return ""
+}
+
+func moduleVersion(path string) string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == path {
+			if dep.Replace != nil {
+				return dep.Replace.Version
+			}
+			return dep.Version
+		}
+	}
+	return ""
+}
+
+// Load reads the lock file in dir, a missing file gives an empty lock.
+func Load(dir string) (*Lock, error) {
+	l := new(Lock)
+	data, err := os.ReadFile(filepath.Join(dir, consts.LockFile))
+	if os.IsNotExist(err) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = yaml.Unmarshal(data, l); err != nil {
+		return nil, errs.New(errs.InvalidArgs, "parse %s failed: %s", filepath.Join(dir, consts.LockFile), err)
+	}
+	return l, nil
+}
+
+// Save writes the lock file in dir, the entries are sorted to keep the diffs small.
+func (l *Lock) Save(dir string) error {
+	sort.Slice(l.Entries, func(i, j int) bool {
+		if l.Entries[i].Command != l.Entries[j].Command {
+			return l.Entries[i].Command < l.Entries[j].Command
+		}
+		return l.Entries[i].Name < l.Entries[j].Name
+	})
+	buf := bytes.NewBufferString(header)
+	enc := yaml.NewEncoder(buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(l); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, consts.LockFile), buf.Bytes(), 0o644)
+}
+
+// Find returns the entry of the command and name, or nil if there is none.
+func (l *Lock) Find(command, name string) *Entry {
+	for _, e := range l.Entries {
+		if e.Command == command && e.Name == name {
+			return e
+		}
+	}
+	return nil
+}
+
+// Put adds the entry, replacing the one with the same command and name.
+func (l *Lock) Put(entry *Entry) {
+	for i, e := range l.Entries {
+		if e.Command == entry.Command && e.Name == entry.Name {
+			l.Entries[i] = entry
+			return
+		}
+	}
+	l.Entries = append(l.Entries, entry)
+}
+
+// Diff lists the versions which differ from the ones recorded in the locked entry.
+// Tools which are not recorded on both sides are not compared.
+func (e *Entry) Diff(locked *Entry) []string {
+	var diffs []string
+	if e.Version != locked.Version {
+		diffs = append(diffs, fmt.Sprintf("%s %s -> %s", meta.Name, locked.Version, e.Version))
+	}
+	tools := make([]string, 0, len(e.Tools))
+	for t := range e.Tools {
+		tools = append(tools, t)
+	}
+	sort.Strings(tools)
+	for _, t := range tools {
+		if v, ok := locked.Tools[t]; ok && v != e.Tools[t] {
+			diffs = append(diffs, fmt.Sprintf("%s %s -> %s", t, v, e.Tools[t]))
+		}
+	}
+	return diffs
+}
+
+// Checksums returns the sha256 of the files, keyed by their slash separated path relative to dir.
+func Checksums(dir string, files []string) (map[string]string, error) {
+	sums := make(map[string]string, len(files))
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(data)
+		key := f
+		if rel, err := filepath.Rel(dir, f); err == nil {
+			key = rel
+		}
+		sums[filepath.ToSlash(key)] = hex.EncodeToString(sum[:])
+	}
+	return sums, nil
+}
@@ -0,0 +1,74 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lock
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/cwgo/meta"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLock(t *testing.T) {
+	dir := t.TempDir()
+	l, err := Load(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, l.Entries)
+
+	idl := filepath.Join(dir, "idl", "hello.thrift")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(idl), 0o755))
+	assert.NoError(t, os.WriteFile(idl, []byte("service Hello {}\n"), 0o644))
+
+	server := NewEntry("server", "hello", Kitex, Thriftgo)
+	server.IDL, err = Checksums(dir, []string{idl})
+	assert.NoError(t, err)
+	assert.Contains(t, server.IDL, "idl/hello.thrift")
+	server.Args = config.ServerSpec{ServerName: "hello", Type: consts.RPC, IdlPath: "idl/hello.thrift"}
+	l.Put(server)
+	l.Put(NewEntry("job", "a"))
+	assert.NoError(t, l.Save(dir))
+
+	l, err = Load(dir)
+	assert.NoError(t, err)
+	assert.Len(t, l.Entries, 2)
+	assert.Equal(t, "job", l.Entries[0].Command)
+	locked := l.Find("server", "hello")
+	assert.Equal(t, server.IDL, locked.IDL)
+	assert.Equal(t, map[string]interface{}{"server_name": "hello", "type": consts.RPC, "idl": "idl/hello.thrift"}, locked.Args)
+	assert.Nil(t, l.Find("client", "hello"))
+
+	// the same versions regenerate silently
+	assert.Empty(t, NewEntry("server", "hello", Kitex, Thriftgo).Diff(locked))
+
+	locked.Version = "v0.0.1"
+	locked.Tools[Kitex] = "v0.0.2"
+	delete(locked.Tools, Thriftgo)
+	diffs := NewEntry("server", "hello", Kitex, Thriftgo, Hz).Diff(locked)
+	assert.Equal(t, []string{
+		meta.Name + " v0.0.1 -> " + meta.Version,
+		Kitex + " v0.0.2 -> " + ToolVersion(Kitex),
+	}, diffs)
+
+	// putting an entry again replaces it
+	l.Put(NewEntry("server", "hello"))
+	assert.Len(t, l.Entries, 2)
+	assert.Nil(t, l.Find("server", "hello").Tools)
+}
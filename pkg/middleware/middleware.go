@@ -0,0 +1,392 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package middleware
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/cwgo/pkg/common/errs"
+	"github.com/cloudwego/cwgo/pkg/common/utils"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/cloudwego/hertz/cmd/hz/util"
+	"github.com/cloudwego/hertz/cmd/hz/util/logs"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+const (
+	// Dir is where the middleware files are generated, relative to the project.
+	Dir = "biz/middleware"
+	// pkg is the name the middleware package is referenced by once registered.
+	pkg = "middleware"
+
+	routerDir          = "biz/router"
+	kitexInit          = "kitexInit"
+	registerMiddleware = "registerMiddleware"
+	clientOpts         = "defaultClientOpts"
+	routerMiddleware   = "middleware.go"
+	groupSuffix        = "Mw"
+)
+
+// Scopes lists the scopes supported by each middleware type.
+var Scopes = map[string][]string{
+	consts.RPC:  {consts.ServerScope, consts.ClientScope},
+	consts.HTTP: {consts.ServerScope, consts.RouteGroupScope},
+}
+
+// Middleware generates the middleware file and registers it in the project,
+// nothing is changed by running it again.
+func Middleware(c *config.MiddlewareArgument) error {
+	if err := check(c); err != nil {
+		return err
+	}
+
+	dir := c.OutDir
+	if dir == "" {
+		dir = consts.CurrentDir
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("get project path failed: %s", err)
+	}
+	module, root, ok := utils.SearchGoMod(dir, true)
+	if !ok {
+		return errs.New(errs.InvalidArgs, "go.mod is not found in %s or its parents, run it in a generated project", dir)
+	}
+	rel, err := filepath.Rel(root, dir)
+	if err != nil {
+		return err
+	}
+	importPath := filepath.ToSlash(filepath.Join(module, rel, Dir))
+
+	name := util.CamelString(c.Name)
+	if err = generate(dir, c.Type, name); err != nil {
+		return err
+	}
+
+	switch c.Scope {
+	case consts.ServerScope:
+		if c.Type == consts.RPC {
+			return registerKitexServer(filepath.Join(dir, consts.Main), importPath, name)
+		}
+		return registerHertzServer(filepath.Join(dir, consts.Main), importPath, name)
+	case consts.ClientScope:
+		return registerKitexClients(dir, c.Service, importPath, name)
+	default:
+		return registerRouteGroup(dir, c.Group, importPath, name)
+	}
+}
+
+func check(c *config.MiddlewareArgument) error {
+	if c.Name == "" {
+		return errs.New(errs.InvalidArgs, "middleware name is empty")
+	}
+	if !token.IsIdentifier(util.CamelString(c.Name)) {
+		return errs.New(errs.InvalidArgs, "middleware name %s is not a valid identifier", c.Name)
+	}
+	scopes, ok := Scopes[c.Type]
+	if !ok {
+		return errs.New(errs.InvalidArgs, "unsupported middleware type %s (support %s, %s)", c.Type, consts.RPC, consts.HTTP)
+	}
+	if !contains(scopes, c.Scope) {
+		return errs.New(errs.InvalidArgs, "unsupported scope %s of %s middleware (support %s)", c.Scope, c.Type, strings.Join(scopes, ", "))
+	}
+	if c.Scope == consts.RouteGroupScope && c.Group == "" {
+		return errs.New(errs.InvalidArgs, "route group is empty, specify it with --%s", consts.Group)
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// generate writes the middleware file, an existing one is kept.
+func generate(dir, typ, name string) error {
+	path := filepath.Join(dir, Dir, util.SnakeString(name)+".go")
+	if exist, _ := utils.PathExist(path); exist {
+		logs.Warnf("%s already exists, skip it", path)
+		return nil
+	}
+
+	content := kitexTpl
+	if typ == consts.HTTP {
+		content = hertzTpl
+	}
+	tmpl, err := template.New(name).Parse(content)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, map[string]string{"Name": name}); err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// registerKitexServer appends server.WithMiddleware to the options returned by kitexInit of main.go.
+func registerKitexServer(path, importPath, name string) error {
+	return edit(path, importPath, func(fset *token.FileSet, file *ast.File, src []byte) ([]byte, error) {
+		fn := findFunc(file, kitexInit)
+		if fn == nil {
+			return nil, errs.New(errs.Conflict, "%s is not found in %s", kitexInit, path)
+		}
+		if registered(fn, name) {
+			return nil, nil
+		}
+		stmt := fmt.Sprintf("opts = append(opts, server.WithMiddleware(%s.%s))\n", pkg, name)
+		return insert(src, offset(fset, beforeReturn(fn)), stmt), nil
+	})
+}
+
+// registerHertzServer appends h.Use to registerMiddleware of main.go.
+func registerHertzServer(path, importPath, name string) error {
+	return edit(path, importPath, func(fset *token.FileSet, file *ast.File, src []byte) ([]byte, error) {
+		fn := findFunc(file, registerMiddleware)
+		if fn == nil || fn.Type.Params.NumFields() == 0 || len(fn.Type.Params.List[0].Names) == 0 {
+			return nil, errs.New(errs.Conflict, "%s(h *server.Hertz) is not found in %s", registerMiddleware, path)
+		}
+		if registered(fn, name) {
+			return nil, nil
+		}
+		stmt := fmt.Sprintf("\n%s.Use(%s.%s())\n", fn.Type.Params.List[0].Names[0].Name, pkg, name)
+		return insert(src, offset(fset, beforeReturn(fn)), stmt), nil
+	})
+}
+
+// registerKitexClients adds client.WithMiddleware to defaultClientOpts of the clients generated under rpc/,
+// only the client of service is changed when it is given.
+func registerKitexClients(dir, service, importPath, name string) error {
+	pattern := filepath.Join(dir, consts.DefaultKitexClientDir, "*", "*_init.go")
+	if service != "" {
+		s := strings.NewReplacer(".", "_", "/", "_").Replace(service)
+		pattern = filepath.Join(dir, consts.DefaultKitexClientDir, s, s+"_init.go")
+	}
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		return errs.New(errs.InvalidArgs, "no kitex client is found by %s, generate it with cwgo client first", pattern)
+	}
+
+	for _, path := range paths {
+		path := path
+		err = edit(path, importPath, func(fset *token.FileSet, file *ast.File, src []byte) ([]byte, error) {
+			lit := findVar(file, clientOpts)
+			if lit == nil {
+				return nil, errs.New(errs.Conflict, "%s is not found in %s", clientOpts, path)
+			}
+			if registered(lit, name) {
+				return nil, nil
+			}
+			return addElement(fset, src, lit, fmt.Sprintf("client.WithMiddleware(%s.%s)", pkg, name)), nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// registerRouteGroup returns the middleware from the group middleware function generated by hz in biz/router.
+// The group is the function name with or without the Mw suffix, it is prefixed with its directory
+// under biz/router when the name is found in more than one package, e.g. hello/_userMw.
+func registerRouteGroup(dir, group, importPath, name string) error {
+	root := filepath.Join(dir, filepath.FromSlash(routerDir))
+	fnName := group
+	if i := strings.LastIndex(group, consts.Slash); i >= 0 {
+		root = filepath.Join(root, filepath.FromSlash(group[:i]))
+		fnName = group[i+1:]
+	}
+	if !strings.HasSuffix(fnName, groupSuffix) {
+		fnName += groupSuffix
+	}
+
+	var found []string
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() || d.Name() != routerMiddleware {
+			return err
+		}
+		file, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+		if err != nil {
+			return err
+		}
+		if findFunc(file, fnName) != nil {
+			found = append(found, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("search route group %s failed: %w", group, err)
+	}
+	switch len(found) {
+	case 0:
+		return errs.New(errs.InvalidArgs, "route group middleware %s is not found in %s", fnName, root)
+	case 1:
+	default:
+		return errs.New(errs.InvalidArgs, "route group middleware %s is found in %s, prefix it with its directory under biz/router", fnName, strings.Join(found, ", "))
+	}
+
+	path := found[0]
+	return edit(path, importPath, func(fset *token.FileSet, file *ast.File, src []byte) ([]byte, error) {
+		fn := findFunc(file, fnName)
+		if registered(fn, name) {
+			return nil, nil
+		}
+		ret, ok := fn.Body.List[len(fn.Body.List)-1].(*ast.ReturnStmt)
+		if !ok || len(ret.Results) != 1 {
+			return nil, errs.New(errs.Conflict, "%s of %s does not end with returning its middleware", fnName, path)
+		}
+		call := fmt.Sprintf("%s.%s()", pkg, name)
+		switch r := ret.Results[0].(type) {
+		case *ast.CompositeLit:
+			return addElement(fset, src, r, call), nil
+		case *ast.Ident:
+			if r.Name == "nil" {
+				return replace(fset, src, r, "[]app.HandlerFunc{"+call+"}"), nil
+			}
+		}
+		expr := ret.Results[0]
+		return replace(fset, src, expr, fmt.Sprintf("append(%s, %s)", src[offset(fset, expr.Pos()):offset(fset, expr.End())], call)), nil
+	})
+}
+
+// edit rewrites the go file at path by change, which returns nil when the file is left as it is.
+// The changes are inserted into the source at the positions found in its syntax tree to keep the
+// comments in place, then the middleware package is imported and the file is formatted.
+func edit(path, importPath string, change func(fset *token.FileSet, file *ast.File, src []byte) ([]byte, error)) error {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, src, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+	changed, err := change(fset, file, src)
+	if err != nil || changed == nil {
+		return err
+	}
+
+	fset = token.NewFileSet()
+	file, err = parser.ParseFile(fset, path, changed, parser.ParseComments)
+	if err != nil {
+		return fmt.Errorf("register middleware in %s failed: %w", path, err)
+	}
+	astutil.AddImport(fset, file, importPath)
+	var buf bytes.Buffer
+	if err = format.Node(&buf, fset, file); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+func findFunc(file *ast.File, name string) *ast.FuncDecl {
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == name && fn.Body != nil {
+			return fn
+		}
+	}
+	return nil
+}
+
+// findVar returns the composite literal the package level variable is initialized with.
+func findVar(file *ast.File, name string) *ast.CompositeLit {
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			vs := spec.(*ast.ValueSpec)
+			for i, n := range vs.Names {
+				if n.Name != name || i >= len(vs.Values) {
+					continue
+				}
+				lit, _ := vs.Values[i].(*ast.CompositeLit)
+				return lit
+			}
+		}
+	}
+	return nil
+}
+
+// registered reports whether the middleware is referenced in node already.
+func registered(node ast.Node, name string) (found bool) {
+	ast.Inspect(node, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if x, ok := sel.X.(*ast.Ident); ok && x.Name == pkg && sel.Sel.Name == name {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// beforeReturn is where a statement is appended to the function, before its final return if any.
+func beforeReturn(fn *ast.FuncDecl) token.Pos {
+	if n := len(fn.Body.List); n > 0 {
+		if ret, ok := fn.Body.List[n-1].(*ast.ReturnStmt); ok {
+			return ret.Pos()
+		}
+	}
+	return fn.Body.Rbrace
+}
+
+// addElement appends elem to the composite literal, keeping it on one line or one element per line.
+func addElement(fset *token.FileSet, src []byte, lit *ast.CompositeLit, elem string) []byte {
+	if len(lit.Elts) == 0 {
+		return insert(src, offset(fset, lit.Rbrace), elem)
+	}
+	last := lit.Elts[len(lit.Elts)-1]
+	if fset.Position(lit.Rbrace).Line > fset.Position(last.End()).Line {
+		return insert(src, offset(fset, lit.Rbrace), elem+",\n")
+	}
+	return insert(src, offset(fset, last.End()), ", "+elem)
+}
+
+func replace(fset *token.FileSet, src []byte, node ast.Node, text string) []byte {
+	start, end := offset(fset, node.Pos()), offset(fset, node.End())
+	return append(append(append([]byte{}, src[:start]...), text...), src[end:]...)
+}
+
+func insert(src []byte, at int, text string) []byte {
+	return append(append(append([]byte{}, src[:at]...), text...), src[at:]...)
+}
+
+func offset(fset *token.FileSet, pos token.Pos) int {
+	return fset.Position(pos).Offset
+}
@@ -0,0 +1,158 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package middleware
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/stretchr/testify/assert"
+)
+
+const kitexMain = `package main
+
+import (
+	"github.com/cloudwego/kitex/server"
+)
+
+func kitexInit() (opts []server.Option) {
+	// address
+	opts = append(opts, server.WithServiceAddr(nil))
+	return
+}
+`
+
+const kitexClientInit = `package demo
+
+import (
+	"github.com/cloudwego/kitex/client"
+)
+
+var (
+	defaultClientOpts = []client.Option{
+		client.WithHostPorts("127.0.0.1:8888"),
+	}
+)
+`
+
+const routerMiddlewareFile = `package api
+
+import (
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+func _userMw() []app.HandlerFunc {
+	// your code...
+	return nil
+}
+
+func _helloMw() []app.HandlerFunc {
+	return []app.HandlerFunc{}
+}
+`
+
+const hertzMain = `package main
+
+import (
+	"github.com/cloudwego/hertz/pkg/app/server"
+)
+
+func registerMiddleware(h *server.Hertz) {
+	// recovery
+	h.Use()
+}
+`
+
+func writeProject(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	files[consts.GoMod] = "module example.com/demo\n"
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	return dir
+}
+
+func read(t *testing.T, dir, name string) string {
+	content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+	assert.NoError(t, err)
+	return string(content)
+}
+
+func TestCheck(t *testing.T) {
+	assert.NoError(t, check(&config.MiddlewareArgument{Name: "access_log", Type: consts.RPC, Scope: consts.ClientScope}))
+	assert.Error(t, check(&config.MiddlewareArgument{Type: consts.RPC, Scope: consts.ServerScope}))
+	assert.Error(t, check(&config.MiddlewareArgument{Name: "a-b", Type: consts.RPC, Scope: consts.ServerScope}))
+	assert.Error(t, check(&config.MiddlewareArgument{Name: "log", Type: "GRPC", Scope: consts.ServerScope}))
+	assert.Error(t, check(&config.MiddlewareArgument{Name: "log", Type: consts.RPC, Scope: consts.RouteGroupScope}))
+	assert.Error(t, check(&config.MiddlewareArgument{Name: "log", Type: consts.HTTP, Scope: consts.RouteGroupScope}))
+}
+
+func TestKitex(t *testing.T) {
+	dir := writeProject(t, map[string]string{
+		consts.Main:             kitexMain,
+		"rpc/demo/demo_init.go": kitexClientInit,
+	})
+
+	// registering again changes nothing
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, Middleware(&config.MiddlewareArgument{Name: "access_log", Type: consts.RPC, Scope: consts.ServerScope, OutDir: dir}))
+		assert.NoError(t, Middleware(&config.MiddlewareArgument{Name: "auth", Type: consts.RPC, Scope: consts.ClientScope, OutDir: dir}))
+	}
+
+	assert.Contains(t, read(t, dir, "biz/middleware/access_log.go"), "func AccessLog(next endpoint.Endpoint) endpoint.Endpoint {")
+	main := read(t, dir, consts.Main)
+	assert.Contains(t, main, `"example.com/demo/biz/middleware"`)
+	assert.Contains(t, main, "// address")
+	assert.Equal(t, 1, strings.Count(main, "server.WithMiddleware(middleware.AccessLog)"))
+	assert.Less(t, strings.Index(main, "WithServiceAddr"), strings.Index(main, "WithMiddleware"))
+
+	init := read(t, dir, "rpc/demo/demo_init.go")
+	assert.Equal(t, 1, strings.Count(init, "client.WithMiddleware(middleware.Auth),"))
+
+	err := Middleware(&config.MiddlewareArgument{Name: "auth", Type: consts.RPC, Scope: consts.ClientScope, Service: "user", OutDir: dir})
+	assert.Error(t, err)
+}
+
+func TestHertz(t *testing.T) {
+	dir := writeProject(t, map[string]string{
+		"biz/router/api/middleware.go":   routerMiddlewareFile,
+		"biz/router/admin/middleware.go": routerMiddlewareFile,
+		consts.Main:                      hertzMain,
+	})
+
+	err := Middleware(&config.MiddlewareArgument{Name: "token", Type: consts.HTTP, Scope: consts.RouteGroupScope, Group: "_user", OutDir: dir})
+	assert.Error(t, err, "the group is ambiguous")
+
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, Middleware(&config.MiddlewareArgument{Name: "access_log", Type: consts.HTTP, Scope: consts.ServerScope, OutDir: dir}))
+		assert.NoError(t, Middleware(&config.MiddlewareArgument{Name: "token", Type: consts.HTTP, Scope: consts.RouteGroupScope, Group: "api/_user", OutDir: dir}))
+		assert.NoError(t, Middleware(&config.MiddlewareArgument{Name: "limit", Type: consts.HTTP, Scope: consts.RouteGroupScope, Group: "api/_helloMw", OutDir: dir}))
+		assert.NoError(t, Middleware(&config.MiddlewareArgument{Name: "cors", Type: consts.HTTP, Scope: consts.RouteGroupScope, Group: "api/_helloMw", OutDir: dir}))
+	}
+
+	assert.Contains(t, read(t, dir, "biz/middleware/token.go"), "func Token() app.HandlerFunc {")
+	assert.Equal(t, 1, strings.Count(read(t, dir, consts.Main), "h.Use(middleware.AccessLog())"))
+	mw := read(t, dir, "biz/router/api/middleware.go")
+	assert.Contains(t, mw, "return []app.HandlerFunc{middleware.Token()}")
+	assert.Contains(t, mw, "return []app.HandlerFunc{middleware.Limit(), middleware.Cors()}")
+	assert.Equal(t, routerMiddlewareFile, read(t, dir, "biz/router/admin/middleware.go"))
+}
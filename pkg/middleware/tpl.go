@@ -0,0 +1,53 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package middleware
+
+const kitexTpl = `package middleware
+
+import (
+	"context"
+
+	"github.com/cloudwego/kitex/pkg/endpoint"
+)
+
+// {{.Name}} is a kitex middleware wrapping the calls of the endpoint.
+func {{.Name}}(next endpoint.Endpoint) endpoint.Endpoint {
+	return func(ctx context.Context, req, resp interface{}) (err error) {
+		// todo edit custom code before the call
+		err = next(ctx, req, resp)
+		// todo edit custom code after the call
+		return err
+	}
+}
+`
+
+const hertzTpl = `package middleware
+
+import (
+	"context"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// {{.Name}} is a hertz middleware wrapping the handlers of the routes it is used by.
+func {{.Name}}() app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		// todo edit custom code before the handler
+		c.Next(ctx)
+		// todo edit custom code after the handler
+	}
+}
+`
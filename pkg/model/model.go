@@ -23,20 +23,23 @@ import (
 	"gorm.io/rawsql"
 
 	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/cwgo/pkg/common/errs"
 	"github.com/cloudwego/cwgo/pkg/consts"
 
 	"gorm.io/gen"
 	"gorm.io/gorm"
 )
 
-var (
-	db  *gorm.DB
-	err error
-)
-
 func Model(c *config.ModelArgument) error {
+	if err := check(c); err != nil {
+		return err
+	}
 	dialector := config.OpenTypeFuncMap[consts.DataBaseType(c.Type)]
 
+	var (
+		db  *gorm.DB
+		err error
+	)
 	if c.SQLDir != "" {
 		db, err = gorm.Open(rawsql.New(rawsql.Config{
 			FilePath: []string{c.SQLDir},
@@ -92,6 +95,24 @@ func Model(c *config.ModelArgument) error {
 	return nil
 }
 
+// Check validates the arguments the same way the generator does.
+func Check(c *config.ModelArgument) error {
+	return check(c)
+}
+
+func check(c *config.ModelArgument) error {
+	if c.SQLDir != "" {
+		return nil
+	}
+	if _, ok := config.OpenTypeFuncMap[consts.DataBaseType(c.Type)]; !ok {
+		return errs.New(errs.InvalidArgs, "unknow db type %s (support mysql || postgres || sqlite || sqlserver for now)", c.Type)
+	}
+	if c.DSN == "" {
+		return errs.New(errs.InvalidArgs, "dsn or sql_dir is required")
+	}
+	return nil
+}
+
 func genModels(g *gen.Generator, db *gorm.DB, tables []string) (models []interface{}, err error) {
 	var tablesNameList []string
 	if len(tables) == 0 {
@@ -17,32 +17,39 @@
 package server
 
 import (
-	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/cwgo/pkg/common/config_center"
+	"github.com/cloudwego/cwgo/pkg/common/errs"
+	"github.com/cloudwego/cwgo/pkg/common/kx_registry"
+	"github.com/cloudwego/cwgo/pkg/common/observability"
 	"github.com/cloudwego/cwgo/pkg/common/utils"
 	"github.com/cloudwego/cwgo/pkg/consts"
 )
 
 func check(sa *config.ServerArgument) error {
 	if sa.Type != consts.RPC && sa.Type != consts.HTTP {
-		return errors.New("generate type not supported")
+		return errs.New(errs.InvalidArgs, "generate type not supported")
 	}
 
-	if sa.Registry != "" &&
-		sa.Registry != consts.Zk &&
-		sa.Registry != consts.Nacos &&
-		sa.Registry != consts.Etcd &&
-		sa.Registry != consts.Polaris {
-		return errors.New("unsupported registry")
+	if !kx_registry.IsSupported(sa.Registry) {
+		return errs.New(errs.InvalidArgs, "unsupported registry %s (support %s)", sa.Registry, strings.Join(kx_registry.Registries, ", "))
+	}
+
+	if !config_center.IsSupported(sa.ConfigCenter) {
+		return errs.New(errs.InvalidArgs, "unsupported config center %s (support %s)", sa.ConfigCenter, strings.Join(config_center.Centers, ", "))
+	}
+
+	if b := observability.Unsupported(sa.Observability, observability.ServerBackends); b != "" {
+		return errs.New(errs.InvalidArgs, "unsupported observability %s (support %s)", b, strings.Join(observability.ServerBackends, ", "))
 	}
 
 	if sa.ServerName == "" {
-		return errors.New("must specify server name")
+		return errs.New(errs.InvalidArgs, "must specify server name")
 	}
 
 	// handle cwd and output dir
@@ -90,14 +97,19 @@ func check(sa *config.ServerArgument) error {
 			if utils.IsWindows() {
 				goPkgSlash := strings.ReplaceAll(sa.GoPkg, consts.BackSlash, consts.Slash)
 				if goPkgSlash != sa.GoMod {
-					return fmt.Errorf("module name: %s is not the same with GoPkg under GoPath: %s", sa.GoMod, goPkgSlash)
+					return errs.New(errs.Conflict, "module name: %s is not the same with GoPkg under GoPath: %s", sa.GoMod, goPkgSlash)
 				}
 			} else {
 				if sa.GoMod != sa.GoPkg {
-					return fmt.Errorf("module name: %s is not the same with GoPkg under GoPath: %s", sa.GoMod, sa.GoPkg)
+					return errs.New(errs.Conflict, "module name: %s is not the same with GoPkg under GoPath: %s", sa.GoMod, sa.GoPkg)
 				}
 			}
 		}
 	}
 	return nil
 }
+
+// Check validates the arguments the same way the generator does, the derived fields are filled.
+func Check(sa *config.ServerArgument) error {
+	return check(sa)
+}
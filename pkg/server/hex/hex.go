@@ -0,0 +1,41 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+// Package hex holds the sources generated as they are into the package main of the hex servers,
+// which serve hertz and kitex on the same port, so that they are compiled and tested in cwgo too.
+package hex
+
+import (
+	"bytes"
+	"embed"
+)
+
+// Files are the sources of the package written into the hex servers.
+var Files = []string{"mux.go", "mux_test.go"}
+
+//go:embed mux.go mux_test.go
+var sources embed.FS
+
+// Source returns the content of file in the package main of a hex server, without the license header of cwgo.
+func Source(file string) ([]byte, error) {
+	content, err := sources.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	if i := bytes.Index(content, []byte("package hex")); i >= 0 {
+		content = append([]byte("package main"), content[i+len("package hex"):]...)
+	}
+	return content, nil
+}
@@ -0,0 +1,126 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package hex
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"regexp"
+)
+
+// connHandler is the part of a kitex trans handler needed to hand a connection over to it.
+type connHandler interface {
+	OnActive(ctx context.Context, conn net.Conn) (context.Context, error)
+	OnRead(ctx context.Context, conn net.Conn) error
+}
+
+// mux hands the connections over by the protocol sniffed from their first bytes:
+// HTTP/1 requests are served by http1, HTTP/2 by http2 and the kitex framings by kitex.
+// A connection is sniffed on its first read, the later reads go to the same handler.
+type mux struct {
+	kitex connHandler
+	// http2 serves gRPC, for the protobuf services and the streaming methods.
+	http2 connHandler
+	// http1 serves the connection with hertz until it is closed.
+	http1 func(ctx context.Context, conn net.Conn) error
+}
+
+// routeKey is the context key of the connRoute of a connection.
+type routeKey struct{}
+
+// connRoute is the handler chosen for a connection and the context it handles the connection with.
+type connRoute struct {
+	ctx     context.Context
+	handler connHandler
+}
+
+// protocol is the protocol of a connection, it is sniffed from the first bytes read.
+type protocol int
+
+const (
+	// protocolKitex is the framing of kitex, for thrift and kitex protobuf.
+	protocolKitex protocol = iota
+	// protocolHTTP1 is a plain HTTP/1.x request.
+	protocolHTTP1
+	// protocolHTTP2 is the client preface of HTTP/2, which carries gRPC.
+	protocolHTTP2
+)
+
+// sniffLen is the number of bytes needed by sniff.
+const sniffLen = 4
+
+var (
+	httpReg      = regexp.MustCompile(`^(?:GET |POST|PUT|DELE|HEAD|OPTI|CONN|TRAC|PATC)$`)
+	http2Preface = []byte("PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n")
+)
+
+// sniff tells the protocol of a connection from its first sniffLen bytes.
+func sniff(pre []byte) protocol {
+	switch {
+	case len(pre) < sniffLen:
+		return protocolKitex
+	case bytes.HasPrefix(http2Preface, pre):
+		return protocolHTTP2
+	case httpReg.Match(pre):
+		return protocolHTTP1
+	}
+	return protocolKitex
+}
+
+// onActive activates the connection for kitex, which handles it until it is sniffed.
+func (r *mux) onActive(ctx context.Context, conn net.Conn) (context.Context, error) {
+	ctx, err := r.kitex.OnActive(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	return context.WithValue(ctx, routeKey{}, &connRoute{}), nil
+}
+
+func (r *mux) onRead(ctx context.Context, conn net.Conn) error {
+	rt, _ := ctx.Value(routeKey{}).(*connRoute)
+	if rt != nil && rt.handler != nil {
+		return rt.handler.OnRead(rt.ctx, conn)
+	}
+	var pre []byte
+	if p, ok := conn.(interface{ Peek(n int) ([]byte, error) }); ok {
+		pre, _ = p.Peek(sniffLen)
+	}
+	var handler connHandler = r.kitex
+	switch sniff(pre) {
+	case protocolHTTP1:
+		return r.http1(ctx, conn)
+	case protocolHTTP2:
+		var err error
+		if ctx, err = r.http2.OnActive(ctx, conn); err != nil {
+			return err
+		}
+		handler = r.http2
+	}
+	if rt != nil {
+		rt.ctx, rt.handler = ctx, handler
+	}
+	return handler.OnRead(ctx, conn)
+}
+
+// routed returns the handler chosen for the connection of ctx and its context,
+// ok is false before the connection is sniffed.
+func routed(ctx context.Context) (handler connHandler, hctx context.Context, ok bool) {
+	if rt, _ := ctx.Value(routeKey{}).(*connRoute); rt != nil && rt.handler != nil {
+		return rt.handler, rt.ctx, true
+	}
+	return nil, ctx, false
+}
@@ -0,0 +1,111 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package hex
+
+import (
+	"context"
+	"net"
+	"testing"
+)
+
+// fakeConn is a connection whose first bytes can be peeked.
+type fakeConn struct {
+	net.Conn
+	data []byte
+}
+
+func (c *fakeConn) Peek(n int) ([]byte, error) {
+	if n > len(c.data) {
+		n = len(c.data)
+	}
+	return c.data[:n], nil
+}
+
+// fakeHandler records the connections handed over to it.
+type fakeHandler struct {
+	active, read int
+}
+
+func (h *fakeHandler) OnActive(ctx context.Context, conn net.Conn) (context.Context, error) {
+	h.active++
+	return ctx, nil
+}
+
+func (h *fakeHandler) OnRead(ctx context.Context, conn net.Conn) error {
+	h.read++
+	return nil
+}
+
+func TestSniff(t *testing.T) {
+	for name, tc := range map[string]struct {
+		pre  string
+		want protocol
+	}{
+		"framed thrift": {"\x00\x00\x00\x1c", protocolKitex},
+		"ttheader":      {"\x00\x00\x00\x2a", protocolKitex},
+		"http1 get":     {"GET ", protocolHTTP1},
+		"http1 delete":  {"DELE", protocolHTTP1},
+		"http2 preface": {"PRI ", protocolHTTP2},
+		"short":         {"GE", protocolKitex},
+	} {
+		if got := sniff([]byte(tc.pre)); got != tc.want {
+			t.Errorf("%s: sniff(%q) = %d, want %d", name, tc.pre, got, tc.want)
+		}
+	}
+}
+
+func TestMux(t *testing.T) {
+	for name, tc := range map[string]struct {
+		data                string
+		kitex, http2, http1 int
+	}{
+		"http1": {"GET /ping HTTP/1.1\r\nHost: localhost\r\n\r\n", 0, 0, 1},
+		"http2": {"PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n\x00\x00\x00\x04\x00\x00\x00\x00\x00", 0, 2, 0},
+		"kitex": {"\x00\x00\x00\x1c\x10\x00\x00\x00\x00\x00\x00\x01", 2, 0, 0},
+	} {
+		kitex, http2, http1 := &fakeHandler{}, &fakeHandler{}, 0
+		r := &mux{kitex: kitex, http2: http2, http1: func(ctx context.Context, conn net.Conn) error {
+			http1++
+			return nil
+		}}
+		conn := &fakeConn{data: []byte(tc.data)}
+		ctx, err := r.onActive(context.Background(), conn)
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		if err = r.onRead(ctx, conn); err != nil {
+			t.Errorf("%s: %s", name, err)
+		}
+		// a connection is sniffed once, hertz serves it until it is closed
+		if _, _, ok := routed(ctx); ok {
+			conn.data = nil
+			if err = r.onRead(ctx, conn); err != nil {
+				t.Errorf("%s: %s", name, err)
+			}
+		}
+
+		if kitex.active != 1 || kitex.read != tc.kitex {
+			t.Errorf("%s: kitex activated %d times, read %d times", name, kitex.active, kitex.read)
+		}
+		if http2.active != tc.http2/2 || http2.read != tc.http2 {
+			t.Errorf("%s: http2 activated %d times, read %d times", name, http2.active, http2.read)
+		}
+		if http1 != tc.http1 {
+			t.Errorf("%s: hertz served %d times", name, http1)
+		}
+	}
+
+}
@@ -18,12 +18,14 @@ package server
 
 import (
 	"flag"
-	"fmt"
 	"path"
 	"path/filepath"
 	"strings"
 
 	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/cwgo/pkg/common/config_center"
+	"github.com/cloudwego/cwgo/pkg/common/errs"
+	"github.com/cloudwego/cwgo/pkg/common/hz_registry"
 	"github.com/cloudwego/cwgo/pkg/common/utils"
 	"github.com/cloudwego/cwgo/pkg/consts"
 	"github.com/cloudwego/cwgo/tpl"
@@ -32,9 +34,11 @@ import (
 
 func convertHzArgument(sa *config.ServerArgument, hzArgument *hzConfig.Argument) (err error) {
 	// Common commands
-	abPath, err := filepath.Abs(sa.IdlPath)
-	if err != nil {
-		return fmt.Errorf("idl path %s is not absolute", sa.IdlPath)
+	var abPath string
+	if sa.IdlPath != "" {
+		if abPath, err = filepath.Abs(sa.IdlPath); err != nil {
+			return errs.New(errs.InvalidArgs, "idl path %s is not absolute", sa.IdlPath)
+		}
 	}
 
 	if strings.HasSuffix(sa.Template, consts.SuffixGit) {
@@ -78,7 +82,6 @@ func convertHzArgument(sa *config.ServerArgument, hzArgument *hzConfig.Argument)
 		}
 	}
 
-	hzArgument.IdlPaths = []string{abPath}
 	hzArgument.Gomod = sa.GoMod
 	hzArgument.ServiceName = sa.ServerName
 	hzArgument.OutDir = sa.OutDir
@@ -88,10 +91,12 @@ func convertHzArgument(sa *config.ServerArgument, hzArgument *hzConfig.Argument)
 	hzArgument.Gopkg = sa.GoPkg
 	hzArgument.Gopath = sa.GoPath
 	hzArgument.Verbose = sa.Verbose
-	// Automatic judgment param
-	hzArgument.IdlType, err = utils.GetIdlType(abPath)
-	if err != nil {
-		return
+	// Automatic judgment param, hz creates a server without IDL when there is none
+	if abPath != "" {
+		hzArgument.IdlPaths = []string{abPath}
+		if hzArgument.IdlType, err = utils.GetIdlType(abPath); err != nil {
+			return
+		}
 	}
 
 	// specific commands from -pass param
@@ -134,3 +139,23 @@ func convertHzArgument(sa *config.ServerArgument, hzArgument *hzConfig.Argument)
 	hzArgument.HandlerByMethod = *handlerByMethod
 	return nil
 }
+
+// renderTemplate renders the registry and config center directives of the hz layout or package
+// template into a copy in the private template root and returns the path of the copy.
+func renderTemplate(sa *config.ServerArgument, tplPath string) (string, error) {
+	data, err := templateData(sa)
+	if err != nil {
+		return "", err
+	}
+	return hz_registry.Render(tplPath, data)
+}
+
+func templateData(sa *config.ServerArgument) (*hz_registry.Data, error) {
+	data, err := hz_registry.NewData(sa.CommonParam)
+	if err != nil {
+		return nil, err
+	}
+	data.ConfigCenter = config_center.Name(sa.ConfigCenter)
+	data.Vars = sa.TemplateVars
+	return data, nil
+}
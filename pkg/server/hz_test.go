@@ -0,0 +1,50 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	hzConfig "github.com/cloudwego/hertz/cmd/hz/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertHzArgument(t *testing.T) {
+	idl := filepath.Join(t.TempDir(), "demo.thrift")
+	for _, c := range []struct {
+		name     string
+		idl      string
+		idlPaths []string
+		idlType  string
+	}{
+		{"idl", idl, []string{idl}, "thrift"},
+		{"no idl", "", nil, ""},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			sa := config.NewServerArgument()
+			sa.Type = consts.HTTP
+			sa.IdlPath = c.idl
+			hzArgument := hzConfig.NewArgument()
+			assert.NoError(t, convertHzArgument(sa, hzArgument))
+			assert.Equal(t, c.idlPaths, hzArgument.IdlPaths)
+			assert.Equal(t, c.idlType, hzArgument.IdlType)
+		})
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"flag"
 	"fmt"
 	"go/ast"
+	"go/format"
 	"go/parser"
 	"go/printer"
 	"go/token"
@@ -31,15 +32,20 @@ import (
 	"text/template"
 
 	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/cwgo/pkg/common/errs"
+	"github.com/cloudwego/cwgo/pkg/common/hz_registry"
+	idlparser "github.com/cloudwego/cwgo/pkg/common/parser"
 	"github.com/cloudwego/cwgo/pkg/common/utils"
 	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/cloudwego/cwgo/pkg/server/hex"
 	"github.com/cloudwego/cwgo/tpl"
 	hzConfig "github.com/cloudwego/hertz/cmd/hz/config"
 	"github.com/cloudwego/hertz/cmd/hz/meta"
 	"github.com/cloudwego/kitex"
 	kargs "github.com/cloudwego/kitex/tool/cmd/kitex/args"
 	"github.com/cloudwego/kitex/tool/internal_pkg/generator"
-	"github.com/cloudwego/kitex/tool/internal_pkg/log"
+	thriftparser "github.com/cloudwego/thriftgo/parser"
+	"golang.org/x/tools/go/ast/astutil"
 )
 
 func convertKitexArgs(sa *config.ServerArgument, kitexArgument *kargs.Arguments) (err error) {
@@ -92,7 +98,6 @@ Usage: %s [flags] IDL
 Flags:
 `, kitexArgument.Version, os.Args[0])
 		f.PrintDefaults()
-		os.Exit(1)
 	}
 
 	err = f.Parse(utils.StringSliceSpilt(sa.SliceParam.Pass))
@@ -138,8 +143,7 @@ func checkKitexArgs(a *kargs.Arguments) (err error) {
 	// check service name
 	if a.ServiceName == "" {
 		if a.Use != "" {
-			log.Warn("-use must be used with -service")
-			os.Exit(2)
+			return errs.New(errs.InvalidArgs, "-use must be used with -service")
 		}
 	}
 
@@ -154,25 +158,21 @@ func checkKitexArgs(a *kargs.Arguments) (err error) {
 	gosrc := filepath.Join(gopath, consts.Src)
 	gosrc, err = filepath.Abs(gosrc)
 	if err != nil {
-		log.Warn("Get GOPATH/src path failed:", err.Error())
-		os.Exit(1)
+		return fmt.Errorf("get GOPATH/src path failed: %s", err)
 	}
 	curpath, err := filepath.Abs(consts.CurrentDir)
 	if err != nil {
-		log.Warn("Get current path failed:", err.Error())
-		os.Exit(1)
+		return fmt.Errorf("get current path failed: %s", err)
 	}
 
 	if strings.HasPrefix(curpath, gosrc) {
 		if a.PackagePrefix, err = filepath.Rel(gosrc, curpath); err != nil {
-			log.Warn("Get GOPATH/src relpath failed:", err.Error())
-			os.Exit(1)
+			return fmt.Errorf("get GOPATH/src relpath failed: %s", err)
 		}
 		a.PackagePrefix = filepath.Join(a.PackagePrefix, generator.KitexGenPath)
 	} else {
 		if a.ModuleName == "" {
-			log.Warn("Outside of $GOPATH. Please specify a module name with the '-module' flag.")
-			os.Exit(1)
+			return errs.New(errs.InvalidArgs, "outside of $GOPATH. Please specify a module name with the '-module' flag")
 		}
 	}
 
@@ -181,19 +181,16 @@ func checkKitexArgs(a *kargs.Arguments) (err error) {
 		if ok {
 			// go.mod exists
 			if module != a.ModuleName {
-				log.Warnf("The module name given by the '-module' option ('%s') is not consist with the name defined in go.mod ('%s' from %s)\n",
+				return errs.New(errs.Conflict, "the module name given by the '-module' option ('%s') is not consist with the name defined in go.mod ('%s' from %s)",
 					a.ModuleName, module, p)
-				os.Exit(1)
 			}
 			if a.PackagePrefix, err = filepath.Rel(p, curpath); err != nil {
-				log.Warn("Get package prefix failed:", err.Error())
-				os.Exit(1)
+				return fmt.Errorf("get package prefix failed: %s", err)
 			}
 			a.PackagePrefix = filepath.Join(a.ModuleName, a.PackagePrefix, generator.KitexGenPath)
 		} else {
 			if err = utils.InitGoMod(a.ModuleName); err != nil {
-				log.Warn("Init go mod failed:", err.Error())
-				os.Exit(1)
+				return fmt.Errorf("init go mod failed: %w", err)
 			}
 			a.PackagePrefix = filepath.Join(a.ModuleName, generator.KitexGenPath)
 		}
@@ -207,6 +204,81 @@ func checkKitexArgs(a *kargs.Arguments) (err error) {
 	return nil
 }
 
+// multiServiceFeature is the kitex template feature enabled when the IDL defines several services,
+// templates query it with {{if HasFeature .Features "multi_service"}}.
+const multiServiceFeature = "multi_service"
+
+// handleMultiService combines the services when the IDL defines more than one, so that all of them
+// are registered on the generated server and their handlers are generated per service.
+// It returns the path of the template extension enabling multiServiceFeature, which is empty otherwise.
+// The inherited methods would be combined twice, the services extending others are served alone
+// as kitex does when the served service extends all the others, and refused otherwise.
+func handleMultiService(a *kargs.Arguments) (string, error) {
+	services, extends, err := idlServices(a)
+	if err != nil {
+		return "", err
+	}
+	if len(services) < 2 {
+		return "", nil
+	}
+	extended := false
+	for _, s := range services {
+		extended = extended || extends[s] != ""
+	}
+	if extended {
+		served := services[len(services)-1]
+		inherited := inheritedServices(served, extends)
+		var dropped []string
+		for _, s := range services[:len(services)-1] {
+			if !inherited[s] {
+				dropped = append(dropped, s)
+			}
+		}
+		if len(dropped) > 0 {
+			return "", errs.New(errs.InvalidArgs, "the services of %s extending other services can't be served together, "+
+				"kitex would serve %s only and drop %s: let %s extend them or move them to an IDL each",
+				a.IDL, served, strings.Join(dropped, ", "), served)
+		}
+		return "", nil
+	}
+	a.CombineService = true
+	return utils.EnableKitexFeatures(a, multiServiceFeature)
+}
+
+// idlServices returns the services of the main IDL in order and, for thrift, the service each one extends.
+func idlServices(a *kargs.Arguments) (services []string, extends map[string]string, err error) {
+	extends = make(map[string]string)
+	if a.IDLType == consts.Thrift {
+		var svcs []*thriftparser.Service
+		svcs, err = idlparser.NewThriftParser().GetServices(a.IDL, a.Includes)
+		for _, s := range svcs {
+			services = append(services, s.Name)
+			extends[s.Name] = s.Extends
+		}
+	} else {
+		services, err = idlparser.NewProtoParser().GetServiceNames(a.IDL)
+	}
+	if err != nil {
+		return nil, nil, errs.New(errs.IDLParse, "parse services of %s failed: %s", a.IDL, err)
+	}
+	return services, extends, nil
+}
+
+// inheritedServices returns the services of the main IDL that served extends directly or not,
+// the services out of the main IDL, e.g. base.Base, end the chain.
+func inheritedServices(served string, extends map[string]string) map[string]bool {
+	inherited := make(map[string]bool)
+	for s := extends[served]; s != "" && !inherited[s]; s = extends[s] {
+		if _, ok := extends[s]; !ok {
+			break
+		}
+		inherited[s] = true
+	}
+	return inherited
+}
+
+const hexTransHandlerFile = "hex_trans_handler.go"
+
 func hzArgsForHex(c *config.ServerArgument) (*hzConfig.Argument, error) {
 	utils.SetHzVerboseLog(c.Verbose)
 	hzArgs := hzConfig.NewArgument()
@@ -224,20 +296,63 @@ func hzArgsForHex(c *config.ServerArgument) (*hzConfig.Argument, error) {
 		hzArgs.Use = fmt.Sprintf("%s/%s", hzArgs.Gomod, consts.DefaultKitexModelDir)
 	}
 	if hzArgs.CustomizePackage == path.Join(tpl.HertzDir, consts.Server, consts.Standard, consts.PackageLayoutFile) {
-		hzArgs.CustomizePackage = "" // disable the default hertz template for hex
+		// the handlers of hex call the kitex implementation instead of the biz/service of the hertz template
+		hzArgs.CustomizePackage = path.Join(tpl.HertzDir, consts.Server, consts.Hex, consts.PackageLayoutFile)
 	}
 	return hzArgs, nil
 }
 
-func generateHexFile(c *config.ServerArgument) error {
-	tmplContent := `package main
+// renderHexTemplate renders the directives of the hz package template of a hex server into a copy
+// in the private template root, the routes of every service are bound to the kitex implementation
+// serving it, see hexImpls.
+func renderHexTemplate(c *config.ServerArgument, a *kargs.Arguments, tplPath string) (string, error) {
+	data, err := templateData(c)
+	if err != nil {
+		return "", err
+	}
+	if data.HexImpls, err = hexImpls(a); err != nil {
+		return "", err
+	}
+	return hz_registry.Render(tplPath, data)
+}
+
+// hexImpls maps the services of the main IDL to the kitex implementation serving their methods.
+// The combined services have an implementation each, otherwise kitex serves the last service only,
+// whose implementation has the methods of the services it extends in the main IDL too.
+// The other services have no implementation and their routes are not bound.
+func hexImpls(a *kargs.Arguments) (map[string]string, error) {
+	services, extends, err := idlServices(a)
+	if err != nil {
+		return nil, err
+	}
+
+	impls := make(map[string]string)
+	if len(services) == 0 {
+		return impls, nil
+	}
+	if a.CombineService {
+		for _, s := range services {
+			impls[s] = s + "Impl"
+		}
+		return impls, nil
+	}
+	served := services[len(services)-1]
+	impls[served] = served + "Impl"
+	for s := range inheritedServices(served, extends) {
+		impls[s] = served + "Impl"
+	}
+	return impls, nil
+}
+
+// hexTransHandlerTpl is the trans handler of the kitex server of hex, the connections are handed over
+// to hertz, nphttp2 or the kitex handler by the mux of package hex.
+const hexTransHandlerTpl = `package main
 
 import (
 	"context"
 	"errors"
 	"fmt"
 	"net"
-	"regexp"
 
 	"github.com/cloudwego/hertz/pkg/app"
 	hertzServer "github.com/cloudwego/hertz/pkg/app/server"
@@ -248,7 +363,6 @@ import (
 	"github.com/cloudwego/kitex/pkg/endpoint"
 	"github.com/cloudwego/kitex/pkg/klog"
 	"github.com/cloudwego/kitex/pkg/remote"
-	"github.com/cloudwego/kitex/pkg/remote/trans/detection"
 	"github.com/cloudwego/kitex/pkg/remote/trans/netpoll"
 	"github.com/cloudwego/kitex/pkg/remote/trans/nphttp2"
 	"{{$.ProjPackage}}/biz/router"
@@ -260,45 +374,109 @@ type mixTransHandlerFactory struct {
 
 type transHandler struct {
 	remote.ServerTransHandler
-}
-
-// SetInvokeHandleFunc is used to set invoke handle func.
-func (t *transHandler) SetInvokeHandleFunc(inkHdlFunc endpoint.Endpoint) {
-	t.ServerTransHandler.(remote.InvokeHandleFuncSetter).SetInvokeHandleFunc(inkHdlFunc)
+	http2 remote.ServerTransHandler
+	mux   *mux
 }
 
 func (m mixTransHandlerFactory) NewTransHandler(opt *remote.ServerOption) (remote.ServerTransHandler, error) {
-	var kitexOrigin remote.ServerTransHandler
-	var err error
-
-	if m.originFactory != nil {
-		kitexOrigin, err = m.originFactory.NewTransHandler(opt)
-	} else {
-		// if no customized factory just use the default factory under detection pkg.
-		kitexOrigin, err = detection.NewSvrTransHandlerFactory(netpoll.NewSvrTransHandlerFactory(), nphttp2.NewSvrTransHandlerFactory()).NewTransHandler(opt)
+	// if no customized factory just use the netpoll one for the kitex framings
+	factory := m.originFactory
+	if factory == nil {
+		factory = netpoll.NewSvrTransHandlerFactory()
 	}
+	kitexOrigin, err := factory.NewTransHandler(opt)
 	if err != nil {
 		return nil, err
 	}
-	return &transHandler{ServerTransHandler: kitexOrigin}, nil
+	// gRPC is served by nphttp2
+	http2, err := nphttp2.NewSvrTransHandlerFactory().NewTransHandler(opt)
+	if err != nil {
+		return nil, err
+	}
+	return &transHandler{
+		ServerTransHandler: kitexOrigin,
+		http2:              http2,
+		mux:                &mux{kitex: kitexOrigin, http2: http2, http1: serveHertz},
+	}, nil
+}
+
+func serveHertz(ctx context.Context, conn net.Conn) error {
+	c, ok := conn.(network.Conn)
+	if !ok {
+		return errors.New("HERTZ: unsupported connection")
+	}
+	klog.Info("using Hertz to process request")
+	if err := hertzEngine.Serve(ctx, c); err != nil {
+		return fmt.Errorf("HERTZ: %w", err)
+	}
+	return nil
 }
 
-var httpReg = regexp.MustCompile(` + "`^(?:GET |POST|PUT|DELE|HEAD|OPTI|CONN|TRAC|PATC)$`" + `)
+func (t *transHandler) OnActive(ctx context.Context, conn net.Conn) (context.Context, error) {
+	return t.mux.onActive(ctx, conn)
+}
 
 func (t *transHandler) OnRead(ctx context.Context, conn net.Conn) error {
-	c, ok := conn.(network.Conn)
-	if ok {
-		pre, _ := c.Peek(4)
-		if httpReg.Match(pre) {
-			klog.Info("using Hertz to process request")
-			err := hertzEngine.Serve(ctx, c)
-			if err != nil {
-				err = errors.New(fmt.Sprintf("HERTZ: %s", err.Error()))
+	return t.mux.onRead(ctx, conn)
+}
+
+func (t *transHandler) OnInactive(ctx context.Context, conn net.Conn) {
+	t.handler(ctx).OnInactive(t.context(ctx), conn)
+}
+
+func (t *transHandler) OnError(ctx context.Context, err error, conn net.Conn) {
+	t.handler(ctx).OnError(ctx, err, conn)
+}
+
+func (t *transHandler) OnMessage(ctx context.Context, args, result remote.Message) (context.Context, error) {
+	return t.handler(ctx).OnMessage(ctx, args, result)
+}
+
+func (t *transHandler) Read(ctx context.Context, conn net.Conn, msg remote.Message) (context.Context, error) {
+	return t.handler(ctx).Read(ctx, conn, msg)
+}
+
+func (t *transHandler) Write(ctx context.Context, conn net.Conn, send remote.Message) (context.Context, error) {
+	return t.handler(ctx).Write(ctx, conn, send)
+}
+
+// handler returns the handler the connection of ctx is routed to, the kitex one until it is sniffed.
+func (t *transHandler) handler(ctx context.Context) remote.ServerTransHandler {
+	if h, _, ok := routed(ctx); ok {
+		return h.(remote.ServerTransHandler)
+	}
+	return t.ServerTransHandler
+}
+
+// context returns the context the connection of ctx is handled with by its handler.
+func (t *transHandler) context(ctx context.Context) context.Context {
+	_, hctx, _ := routed(ctx)
+	return hctx
+}
+
+func (t *transHandler) SetPipeline(pipeline *remote.TransPipeline) {
+	t.ServerTransHandler.SetPipeline(pipeline)
+	t.http2.SetPipeline(pipeline)
+}
+
+// SetInvokeHandleFunc is used to set invoke handle func.
+func (t *transHandler) SetInvokeHandleFunc(inkHdlFunc endpoint.Endpoint) {
+	for _, h := range []remote.ServerTransHandler{t.ServerTransHandler, t.http2} {
+		if s, ok := h.(remote.InvokeHandleFuncSetter); ok {
+			s.SetInvokeHandleFunc(inkHdlFunc)
+		}
+	}
+}
+
+func (t *transHandler) GracefulShutdown(ctx context.Context) error {
+	for _, h := range []remote.ServerTransHandler{t.ServerTransHandler, t.http2} {
+		if g, ok := h.(remote.GracefulShutdown); ok {
+			if err := g.GracefulShutdown(ctx); err != nil {
+				return err
 			}
-			return err
 		}
 	}
-	return t.ServerTransHandler.OnRead(ctx, conn)
+	return nil
 }
 
 func initHertz() *route.Engine {
@@ -323,21 +501,40 @@ func init() {
 	hertzEngine = initHertz()
 }
 `
-	exist, err := utils.PathExist("hex_trans_handler.go")
+
+func generateHexFile(c *config.ServerArgument) error {
+	exist, err := utils.PathExist(hexTransHandlerFile)
 	if err != nil {
 		return err
 	}
 	if exist {
 		return nil
 	}
-	tmpl := template.Must(template.New("hex_trans_handler").Parse(tmplContent))
-	file, err := os.Create("hex_trans_handler.go")
+	if err = writeHexFile(hexTransHandlerFile, hexTransHandlerTpl, c.GoMod); err != nil {
+		return err
+	}
+	// the mux is written as it is compiled and tested in cwgo
+	for _, file := range hex.Files {
+		content, err := hex.Source(file)
+		if err != nil {
+			return err
+		}
+		if err = os.WriteFile("hex_"+file, content, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeHexFile(file, content, module string) error {
+	tmpl := template.Must(template.New(file).Parse(content))
+	f, err := os.Create(file)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
-	return tmpl.Execute(file, map[string]string{
-		"ProjPackage": c.GoMod,
+	defer f.Close()
+	return tmpl.Execute(f, map[string]string{
+		"ProjPackage": module,
 	})
 }
 
@@ -347,7 +544,8 @@ func addHexOptions() error {
 	if err != nil {
 		return err
 	}
-	if bytes.Contains(content, []byte("server.WithTransHandlerFactory(&mixTransHandlerFactory{nil})")) {
+	// the printer may break the inserted call over lines, only the factory is looked for
+	if bytes.Contains(content, []byte("WithTransHandlerFactory(&mixTransHandlerFactory{nil})")) {
 		return nil
 	}
 	fset := token.NewFileSet()
@@ -375,6 +573,71 @@ func addHexOptions() error {
 	return nil
 }
 
+// serverExtensionTpl is the function added to main.go of the combined services, whose server is not
+// created by the NewServer of kitex_gen, the only place kitex puts the options of ExtendServer in.
+const serverExtensionTpl = `
+
+// serverExtension returns the server options of the template extension, e.g. the registry.
+func serverExtension() (options []server.Option) {
+%s
+	return
+}
+%s
+`
+
+// addServerExtension adds the ExtendServer options of the template extension, e.g. the registry
+// and the ones of -template-extension, to the options of kitexInit in main.go.
+func addServerExtension(extensionFile string) error {
+	if extensionFile == "" {
+		return nil
+	}
+	te := new(generator.TemplateExtension)
+	if err := te.FromYAMLFile(extensionFile); err != nil {
+		return err
+	}
+	if te.ExtendServer == nil || strings.TrimSpace(te.ExtendServer.ExtendOption) == "" {
+		return nil
+	}
+	content, err := os.ReadFile(consts.Main)
+	if err != nil {
+		return err
+	}
+	// the call is inserted as text, the printer would break it over lines
+	fset := token.NewFileSet()
+	astFile, err := parser.ParseFile(fset, consts.Main, content, 0)
+	if err != nil {
+		return err
+	}
+	offset := -1
+	for _, decl := range astFile.Decls {
+		if funcDecl, ok := decl.(*ast.FuncDecl); ok && funcDecl.Name.Name == "kitexInit" && funcDecl.Body != nil {
+			offset = fset.Position(funcDecl.Body.Lbrace).Offset + 1
+		}
+	}
+	if offset < 0 {
+		return fmt.Errorf("kitexInit is not found in %s", consts.Main)
+	}
+	code := fmt.Sprintf(serverExtensionTpl, strings.Trim(te.ExtendServer.ExtendOption, "\n"), te.ExtendServer.ExtendFile)
+	content = append([]byte(string(content[:offset])+"\n\topts = append(opts, serverExtension()...)\n"+string(content[offset:])), code...)
+
+	fset = token.NewFileSet()
+	if astFile, err = parser.ParseFile(fset, consts.Main, content, parser.ParseComments); err != nil {
+		return err
+	}
+	for _, importPath := range te.ExtendServer.ImportPaths {
+		if alias := te.Dependencies[importPath]; alias != "" && alias != path.Base(importPath) {
+			astutil.AddNamedImport(fset, astFile, alias, importPath)
+		} else {
+			astutil.AddImport(fset, astFile, importPath)
+		}
+	}
+	var buf bytes.Buffer
+	if err = format.Node(&buf, fset, astFile); err != nil {
+		return err
+	}
+	return os.WriteFile(consts.Main, buf.Bytes(), 0o644)
+}
+
 func insertCodeInFunction(file *ast.File, functionName, left, right string) (bool, error) {
 	for _, decl := range file.Decls {
 		funcDecl, ok := decl.(*ast.FuncDecl)
@@ -399,3 +662,43 @@ func insertCodeInFunction(file *ast.File, functionName, left, right string) (boo
 	}
 	return false, nil
 }
+
+// pruneHandlerImports removes the unused imports of the per service handlers,
+// which are rendered with the imports needed by all the combined services.
+func pruneHandlerImports() error {
+	files, err := filepath.Glob("handler_*.go")
+	if err != nil {
+		return err
+	}
+	for _, filePath := range files {
+		fset := token.NewFileSet()
+		astFile, err := parser.ParseFile(fset, filePath, nil, parser.ParseComments)
+		if err != nil {
+			return err
+		}
+		pruned := false
+		// deleting an import shrinks astFile.Imports, a copy is ranged over
+		for _, spec := range append([]*ast.ImportSpec(nil), astFile.Imports...) {
+			importPath := strings.Trim(spec.Path.Value, `"`)
+			if astutil.UsesImport(astFile, importPath) {
+				continue
+			}
+			var name string
+			if spec.Name != nil {
+				name = spec.Name.Name
+			}
+			pruned = astutil.DeleteNamedImport(fset, astFile, name, importPath) || pruned
+		}
+		if !pruned {
+			continue
+		}
+		var buf bytes.Buffer
+		if err = format.Node(&buf, fset, astFile); err != nil {
+			return err
+		}
+		if err = os.WriteFile(filePath, buf.Bytes(), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
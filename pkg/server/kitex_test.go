@@ -0,0 +1,321 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"bytes"
+	"go/format"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/cwgo/pkg/common/errs"
+	"github.com/cloudwego/cwgo/pkg/common/hz_registry"
+	"github.com/cloudwego/cwgo/pkg/common/kx_registry"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/cloudwego/cwgo/tpl"
+	"github.com/cloudwego/hertz/cmd/hz/generator"
+	kargs "github.com/cloudwego/kitex/tool/cmd/kitex/args"
+	kgenerator "github.com/cloudwego/kitex/tool/internal_pkg/generator"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestHexPackageTemplate(t *testing.T) {
+	assert.NoError(t, tpl.Init())
+	defer tpl.Cleanup()
+
+	// Base is extended by Demo, kitex serves both with DemoImpl
+	rendered, err := hz_registry.Render(path.Join(tpl.HertzDir, consts.Server, consts.Hex, consts.PackageLayoutFile),
+		&hz_registry.Data{HexImpls: map[string]string{"Base": "DemoImpl", "Demo": "DemoImpl"}})
+	assert.NoError(t, err)
+	defer hz_registry.RemoveTemplate(rendered)
+	content, err := os.ReadFile(rendered)
+	assert.NoError(t, err)
+	config := new(generator.TemplateConfig)
+	assert.NoError(t, yaml.Unmarshal(content, config))
+
+	hello := &generator.HttpMethod{
+		Name: "Hello", RequestTypeName: "demo.Req", RequestTypePackage: "demo", ReturnTypeName: "demo.Resp", ReturnTypePackage: "demo",
+		Serializer: "JSON", GenHandler: true, RefPackage: "demo/biz/handler/demo", RefPackageAlias: "demo",
+	}
+	ping := &generator.HttpMethod{
+		Name: "Ping", ReturnTypeName: "demo.Resp", ReturnTypePackage: "demo",
+		Serializer: "JSON", GenHandler: true, RefPackage: "demo/biz/handler/demo", RefPackageAlias: "demo",
+	}
+	// the second route of a method does not generate another handler
+	helloPost := *hello
+	helloPost.GenHandler = false
+	methods := []*generator.HttpMethod{hello, ping, &helloPost}
+
+	render := func(path string, data interface{}) string {
+		for _, layout := range config.Layouts {
+			if layout.Path != path {
+				continue
+			}
+			tp := template.Must(template.New(path).Parse(layout.Body))
+			buf := new(bytes.Buffer)
+			assert.NoError(t, tp.Execute(buf, data))
+			formatted, err := format.Source(buf.Bytes())
+			assert.NoError(t, err, buf.String())
+			return string(formatted)
+		}
+		t.Fatalf("layout %s not found", path)
+		return ""
+	}
+
+	handler := render("handler.go", generator.Handler{PackageName: "demo", Methods: methods[:2]})
+	assert.Contains(t, handler, "var HelloCall func(ctx context.Context, req *demo.Req) (*demo.Resp, error)")
+	assert.Contains(t, handler, "resp, err := HelloCall(ctx, &req)")
+	assert.Contains(t, handler, "var PingCall func(ctx context.Context) (*demo.Resp, error)")
+	assert.Contains(t, handler, "resp, err := PingCall(ctx)")
+	assert.Contains(t, handler, "c.JSON(consts.StatusOK, resp)")
+
+	// the services without api annotations have no handler
+	empty := render("handler.go", generator.Handler{PackageName: "demo"})
+	assert.NotContains(t, empty, "import")
+
+	single := render("handler_single.go", generator.SingleHandler{HttpMethod: hello})
+	assert.Contains(t, single, "resp, err := HelloCall(ctx, &req)")
+
+	gateway := render("hex_{{ToSnakeCase .ServiceName}}.go", generator.CustomizedFileForService{Service: &generator.Service{Name: "Demo", Methods: methods}})
+	assert.Contains(t, gateway, `import demo "demo/biz/handler/demo"`)
+	assert.Contains(t, gateway, "impl := new(DemoImpl)\n\tdemo.HelloCall = impl.Hello\n\tdemo.PingCall = impl.Ping\n}")
+
+	base := render("hex_{{ToSnakeCase .ServiceName}}.go", generator.CustomizedFileForService{Service: &generator.Service{Name: "Base", Methods: methods[1:2]}})
+	assert.Contains(t, base, "impl := new(DemoImpl)\n\tdemo.PingCall = impl.Ping\n}")
+
+	// the services without implementation or without routes are not bound
+	for _, service := range []*generator.Service{
+		{Name: "Other", Methods: methods},
+		{Name: "Demo"},
+		{Name: "Demo", Methods: methods[2:]},
+	} {
+		empty := render("hex_{{ToSnakeCase .ServiceName}}.go", generator.CustomizedFileForService{Service: service})
+		assert.NotContains(t, empty, "import")
+		assert.NotContains(t, empty, "func init")
+	}
+}
+
+func TestHexImpls(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		p := path.Join(dir, name)
+		assert.NoError(t, os.WriteFile(p, []byte(content), 0o644))
+		return p
+	}
+	write("base.thrift", "service Base {}\n")
+	extended := write("extended.thrift", "include \"base.thrift\"\nservice Other {}\nservice Common extends base.Base {}\nservice Demo extends Common {}\n")
+	multi := write("multi.thrift", "service User {}\nservice Order {}\n")
+
+	impls, err := hexImpls(&kargs.Arguments{Config: kgenerator.Config{IDL: extended, IDLType: consts.Thrift}})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"Demo": "DemoImpl", "Common": "DemoImpl"}, impls)
+
+	impls, err = hexImpls(&kargs.Arguments{Config: kgenerator.Config{IDL: multi, IDLType: consts.Thrift, CombineService: true}})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"User": "UserImpl", "Order": "OrderImpl"}, impls)
+
+	impls, err = hexImpls(&kargs.Arguments{Config: kgenerator.Config{IDL: multi, IDLType: consts.Thrift}})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"Order": "OrderImpl"}, impls)
+}
+
+func TestHandleMultiService(t *testing.T) {
+	assert.NoError(t, tpl.Init())
+	defer tpl.Cleanup()
+
+	dir := t.TempDir()
+	single := path.Join(dir, "single.thrift")
+	multi := path.Join(dir, "multi.thrift")
+	assert.NoError(t, os.WriteFile(single, []byte("service Demo {}\n"), 0o644))
+	assert.NoError(t, os.WriteFile(multi, []byte("service User {}\nservice Order {}\n"), 0o644))
+	extended := path.Join(dir, "extended.thrift")
+	assert.NoError(t, os.WriteFile(extended, []byte("service Base {}\nservice Demo extends Base {}\n"), 0o644))
+
+	args := &kargs.Arguments{Config: kgenerator.Config{IDL: single, IDLType: consts.Thrift}}
+	extension, err := handleMultiService(args)
+	assert.NoError(t, err)
+	assert.Empty(t, extension)
+	assert.False(t, args.CombineService)
+
+	// the service extending all the others is served alone, the inherited methods are not combined twice
+	args = &kargs.Arguments{Config: kgenerator.Config{IDL: extended, IDLType: consts.Thrift}}
+	extension, err = handleMultiService(args)
+	assert.NoError(t, err)
+	assert.Empty(t, extension)
+	assert.False(t, args.CombineService)
+
+	// the services not extended by the served one would be dropped
+	partial := path.Join(dir, "partial.thrift")
+	assert.NoError(t, os.WriteFile(partial, []byte("service Base {}\nservice Other {}\nservice Demo extends Base {}\n"), 0o644))
+	args = &kargs.Arguments{Config: kgenerator.Config{IDL: partial, IDLType: consts.Thrift}}
+	_, err = handleMultiService(args)
+	assert.ErrorContains(t, err, "kitex would serve Demo only and drop Other")
+	assert.Equal(t, errs.InvalidArgs, errs.KindOf(err))
+	assert.False(t, args.CombineService)
+
+	args = &kargs.Arguments{Config: kgenerator.Config{IDL: multi, IDLType: consts.Thrift}}
+	extension, err = handleMultiService(args)
+	assert.NoError(t, err)
+	defer os.Remove(extension)
+	assert.True(t, args.CombineService)
+	assert.Equal(t, extension, args.ExtensionFile)
+	te := new(kgenerator.TemplateExtension)
+	assert.NoError(t, te.FromYAMLFile(extension))
+	assert.Contains(t, te.EnableFeatures, multiServiceFeature)
+}
+
+func TestPruneHandlerImports(t *testing.T) {
+	dir := t.TempDir()
+	cwd, _ := os.Getwd()
+	assert.NoError(t, os.Chdir(dir))
+	defer os.Chdir(cwd)
+
+	handler := `package main
+
+import (
+	"context"
+	"demo/biz/service"
+	base "demo/kitex_gen/base"
+	demo "demo/kitex_gen/demo"
+)
+
+type OrderServiceImpl struct{}
+
+func (s *OrderServiceImpl) GetOrder(ctx context.Context, req *demo.Req) (resp *demo.Resp, err error) {
+	return service.NewGetOrderService(ctx).Run(req)
+}
+`
+	assert.NoError(t, os.WriteFile("handler_order_service.go", []byte(handler), 0o644))
+	assert.NoError(t, pruneHandlerImports())
+	content, err := os.ReadFile("handler_order_service.go")
+	assert.NoError(t, err)
+	assert.NotContains(t, string(content), "kitex_gen/base")
+	assert.Contains(t, string(content), `demo "demo/kitex_gen/demo"`)
+}
+
+func TestAddServerExtension(t *testing.T) {
+	assert.NoError(t, tpl.Init())
+	defer tpl.Cleanup()
+	dir := t.TempDir()
+	cwd, _ := os.Getwd()
+	assert.NoError(t, os.Chdir(dir))
+	defer os.Chdir(cwd)
+
+	main := `package main
+
+import (
+	"net"
+
+	"demo/conf"
+	"github.com/cloudwego/kitex/server"
+)
+
+func kitexInit() (opts []server.Option) {
+	addr, err := net.ResolveTCPAddr("tcp", conf.GetConf().Kitex.Address)
+	if err != nil {
+		panic(err)
+	}
+	opts = append(opts, server.WithServiceAddr(addr))
+	return
+}
+`
+	assert.NoError(t, os.WriteFile(consts.Main, []byte(main), 0o644))
+	args := new(kargs.Arguments)
+	extension, err := kx_registry.HandleRegistry(&config.CommonParam{Registry: consts.Etcd, GoMod: "demo", ServerName: "demo"}, args)
+	assert.NoError(t, err)
+	defer kx_registry.RemoveExtension(extension)
+	assert.NoError(t, addServerExtension(args.ExtensionFile))
+
+	content, err := os.ReadFile(consts.Main)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), `etcd "github.com/kitex-contrib/registry-etcd"`)
+	assert.Contains(t, string(content), "func kitexInit() (opts []server.Option) {\n\topts = append(opts, serverExtension()...)")
+	assert.Contains(t, string(content), "func serverExtension() (options []server.Option) {")
+	assert.Contains(t, string(content), "server.WithRegistry(r)")
+	// conf is imported once
+	assert.Equal(t, 1, strings.Count(string(content), `"demo/conf"`))
+
+	// no server extension, main.go is left as is
+	assert.NoError(t, os.WriteFile(consts.Main, []byte(main), 0o644))
+	assert.NoError(t, addServerExtension(""))
+	content, err = os.ReadFile(consts.Main)
+	assert.NoError(t, err)
+	assert.Equal(t, main, string(content))
+}
+
+func TestStreamingTemplates(t *testing.T) {
+	assert.NoError(t, tpl.Init())
+	defer tpl.Cleanup()
+
+	echo := kgenerator.PkgInfo{PkgName: "echo", PkgRefName: "echo", ImportPath: "demo/kitex_gen/echo"}
+	req := &kgenerator.Parameter{Deps: []kgenerator.PkgInfo{echo}, Name: "Req", RawName: "req", Type: "*echo.Request"}
+	resp := &kgenerator.Parameter{Deps: []kgenerator.PkgInfo{echo}, Type: "*echo.Response"}
+	method := func(name string, clientStreaming, serverStreaming bool) *kgenerator.MethodInfo {
+		return &kgenerator.MethodInfo{
+			PkgInfo: echo, ServiceName: "Echo", Name: name, RawName: name, Args: []*kgenerator.Parameter{req}, Resp: resp,
+			ClientStreaming: clientStreaming, ServerStreaming: serverStreaming,
+		}
+	}
+	pkg := &kgenerator.PackageInfo{ServiceInfo: &kgenerator.ServiceInfo{
+		PkgInfo: echo, ServiceName: "Echo", HasStreaming: true,
+		Methods: []*kgenerator.MethodInfo{method("Chat", true, true), method("Upload", true, false), method("Watch", false, true)},
+	}}
+	g := kgenerator.NewGenerator(&kgenerator.Config{
+		TemplateDir: path.Join(tpl.KitexDir, consts.Server, consts.Standard), OutputPath: t.TempDir(),
+		IDLType: "protobuf", ModuleName: "demo", ServiceName: "echo",
+	}, nil)
+	fs, err := g.GenerateCustomPackage(pkg)
+	assert.NoError(t, err)
+
+	files := map[string]string{}
+	for _, f := range fs {
+		if filepath.Ext(f.Name) != ".go" {
+			continue
+		}
+		formatted, err := format.Source([]byte(f.Content))
+		assert.NoError(t, err, f.Content)
+		files[filepath.Base(f.Name)] = string(formatted)
+	}
+
+	// the handlers use the context of the stream, there is no unary method importing context
+	handler := files["handler.go"]
+	assert.NotContains(t, handler, `"context"`)
+	assert.Contains(t, handler, "func (s *EchoImpl) Chat(stream echo.Echo_ChatServer) (err error) {\n\terr = service.NewChatService(stream.Context()).Run(stream)")
+	assert.Contains(t, handler, "func (s *EchoImpl) Watch(req *echo.Request, stream echo.Echo_WatchServer) (err error) {\n\terr = service.NewWatchService(stream.Context()).Run(req, stream)")
+
+	chat := files["chat.go"]
+	assert.Contains(t, chat, `"io"`)
+	assert.Contains(t, chat, "req, err := stream.Recv()")
+	assert.Contains(t, chat, "if err = stream.Send(resp); err != nil {")
+	assert.Contains(t, files["upload.go"], "return stream.SendAndClose(resp)")
+	assert.NotContains(t, files["watch.go"], `"io"`)
+	assert.Contains(t, files["watch.go"], "func (s *WatchService) Run(req *echo.Request, stream echo.Echo_WatchServer) (err error) {")
+
+	// the tests drive the streams with in-process mocks
+	assert.Contains(t, files["chat_test.go"], "func (m *mockChatStream) Recv() (*echo.Request, error) {")
+	assert.Contains(t, files["chat_test.go"], "func (m *mockChatStream) Send(resp *echo.Response) error {")
+	assert.Contains(t, files["upload_test.go"], "func (m *mockUploadStream) SendAndClose(resp *echo.Response) error {")
+	assert.Contains(t, files["upload_test.go"], "if err != context.Canceled {")
+	assert.NotContains(t, files["watch_test.go"], "Recv()")
+	assert.Contains(t, files["watch_test.go"], "err := s.Run(req, stream)")
+}
@@ -24,24 +24,32 @@ import (
 	"strings"
 
 	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/cwgo/pkg/common/config_center"
+	"github.com/cloudwego/cwgo/pkg/common/errs"
+	"github.com/cloudwego/cwgo/pkg/common/hz_registry"
 	"github.com/cloudwego/cwgo/pkg/common/kx_registry"
+	"github.com/cloudwego/cwgo/pkg/common/observability"
 	"github.com/cloudwego/cwgo/pkg/common/utils"
 	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/cloudwego/cwgo/tpl"
 	"github.com/cloudwego/hertz/cmd/hz/app"
 	hzConfig "github.com/cloudwego/hertz/cmd/hz/config"
 	"github.com/cloudwego/hertz/cmd/hz/meta"
 	kargs "github.com/cloudwego/kitex/tool/cmd/kitex/args"
 	"github.com/cloudwego/kitex/tool/internal_pkg/log"
 	"github.com/cloudwego/kitex/tool/internal_pkg/pluginmode/thriftgo"
-	"github.com/urfave/cli/v2"
 )
 
-func Server(c *config.ServerArgument) error {
-	var err error
+func Server(c *config.ServerArgument) (err error) {
 	err = check(c)
 	if err != nil {
 		return err
 	}
+	restore, err := tpl.ApplyOverlay(c.TemplateOverlay)
+	if err != nil {
+		return errs.Wrap(errs.InvalidArgs, err)
+	}
+	defer restore()
 
 	switch c.Type {
 	case consts.RPC:
@@ -51,29 +59,72 @@ func Server(c *config.ServerArgument) error {
 		if err != nil {
 			return err
 		}
-		kx_registry.HandleRegistry(c.CommonParam, args.TemplateDir)
-		defer kx_registry.RemoveExtension()
+		// the registry extension carries code snippets, it is written last to keep them as is
+		extension, err := config_center.HandleKitex(c.ConfigCenter, &args)
+		if err != nil {
+			return err
+		}
+		defer config_center.RemoveExtension(extension)
+		extension, err = observability.HandleKitex(c.Observability, &args)
+		if err != nil {
+			return err
+		}
+		defer observability.RemoveExtension(extension)
+		extension, err = handleMultiService(&args)
+		if err != nil {
+			return err
+		}
+		if extension != "" {
+			defer os.Remove(extension)
+		}
+		extension, err = kx_registry.HandleRegistry(c.CommonParam, &args)
+		if err != nil {
+			return err
+		}
+		defer kx_registry.RemoveExtension(extension)
+
+		// main.go is skipped by the updates, the server extension is added when it is generated
+		_, statErr := os.Stat(consts.Main)
+		newMain := os.IsNotExist(statErr)
 
 		out := new(bytes.Buffer)
 		cmd := args.BuildCmd(out)
+		// the template variables reach the kitex plugin process through its environment only
+		if cmd.Env, err = tpl.CommandEnv(cmd.Env, c.TemplateVars); err != nil {
+			return err
+		}
 		err = cmd.Run()
-		if err != nil {
-			if args.Use != "" {
-				out := strings.TrimSpace(out.String())
-				if strings.HasSuffix(out, thriftgo.TheUseOptionMessage) {
-					utils.ReplaceThriftVersion()
+		// kitex_gen is not generated because of the -use option, it is not a failure
+		// and the generated code is post processed all the same
+		if err != nil && (args.Use == "" || !strings.HasSuffix(strings.TrimSpace(out.String()), thriftgo.TheUseOptionMessage)) {
+			return errs.New(errs.IDLParse, "kitex generation failed: %s\n%s", err, strings.TrimSpace(out.String()))
+		}
+		if args.CombineService {
+			if err = pruneHandlerImports(); err != nil {
+				log.Warnf("remove the unused imports of the handlers failed: %s", err)
+			}
+			if newMain {
+				if err = addServerExtension(args.ExtensionFile); err != nil {
+					return errs.New(errs.PostProcess, "add the server extension to %s failed: %s", consts.Main, err)
 				}
 			}
-			os.Exit(1)
 		}
 		if c.Hex { // add http listen for kitex
 			hzArgs, err := hzArgsForHex(c)
 			if err != nil {
 				return err
 			}
+			if hzArgs.CustomizePackage != "" {
+				pkg, err := renderHexTemplate(c, &args, hzArgs.CustomizePackage)
+				if err != nil {
+					return err
+				}
+				defer hz_registry.RemoveTemplate(pkg)
+				hzArgs.CustomizePackage = pkg
+			}
 			err = app.TriggerPlugin(hzArgs)
 			if err != nil {
-				return err
+				return errs.Wrap(errs.IDLParse, err)
 			}
 			err = generateHexFile(c)
 			if err != nil {
@@ -94,33 +145,40 @@ func Server(c *config.ServerArgument) error {
 		if err != nil {
 			return err
 		}
-
 		if utils.IsHzNew(c.OutDir) {
 			args.CmdType = meta.CmdNew
 			if c.GoMod == "" {
-				return fmt.Errorf("output directory %s is not under GOPATH/src. Please specify a module name with the '-module' flag", c.Cwd)
+				return errs.New(errs.InvalidArgs, "output directory %s is not under GOPATH/src. Please specify a module name with the '-module' flag", c.Cwd)
 			}
 			module, path, ok := utils.SearchGoMod(consts.CurrentDir, false)
 			if ok {
 				// go.mod exists
 				if module != c.GoMod {
-					return fmt.Errorf("module name given by the '-module' option ('%s') is not consist with the name defined in go.mod ('%s' from %s)", c.GoMod, module, path)
+					return errs.New(errs.Conflict, "module name given by the '-module' option ('%s') is not consist with the name defined in go.mod ('%s' from %s)", c.GoMod, module, path)
 				}
 				c.GoMod = module
 			} else {
 				args.NeedGoMod = true
 			}
+			// assign err of the named result, it is checked by the deferred manifest persisting
+			var layout string
+			layout, err = renderTemplate(c, args.CustomizeLayout)
+			if err != nil {
+				return err
+			}
+			defer hz_registry.RemoveTemplate(layout)
+			args.CustomizeLayout = layout
+
 			err = app.GenerateLayout(args)
 			if err != nil {
-				return cli.Exit(err, meta.GenerateLayoutError)
+				return err
 			}
 			defer func() {
 				// ".hz" file converges to the hz tool
 				manifest := new(meta.Manifest)
 				args.InitManifest(manifest)
-				err = manifest.Persist(args.OutDir)
-				if err != nil {
-					err = cli.Exit(fmt.Errorf("persist manifest failed: %v", err), meta.PersistError)
+				if perr := manifest.Persist(args.OutDir); perr != nil && err == nil {
+					err = errs.New(errs.PostProcess, "persist manifest failed: %v", perr)
 				}
 				if !args.NeedGoMod && args.IsNew() {
 					log.Warn(meta.AddThriftReplace)
@@ -131,14 +189,14 @@ func Server(c *config.ServerArgument) error {
 			manifest := new(meta.Manifest)
 			err = manifest.InitAndValidate(args.OutDir)
 			if err != nil {
-				return cli.Exit(err, meta.LoadError)
+				return errs.Wrap(errs.Conflict, err)
 			}
 
 			module, path, ok := utils.SearchGoMod(consts.CurrentDir, false)
 			if ok {
 				// go.mod exists
 				if c.GoMod != "" && module != c.GoMod {
-					return fmt.Errorf("module name given by the '-module' option ('%s') is not consist with the name defined in go.mod ('%s' from %s)", c.GoMod, module, path)
+					return errs.New(errs.Conflict, "module name given by the '-module' option ('%s') is not consist with the name defined in go.mod ('%s' from %s)", c.GoMod, module, path)
 				}
 				args.Gomod = module
 			} else {
@@ -146,7 +204,7 @@ func Server(c *config.ServerArgument) error {
 				if err != nil {
 					return fmt.Errorf(err.Error())
 				}
-				return fmt.Errorf("go.mod not found in %s", workPath)
+				return errs.New(errs.Conflict, "go.mod not found in %s", workPath)
 			}
 
 			// update argument by ".hz", can automatically get "handler_dir"/"model_dir"/"router_dir"
@@ -155,16 +213,24 @@ func Server(c *config.ServerArgument) error {
 			defer func() {
 				// If the "handler_dir"/"model_dir" is updated, write it back to ".hz"
 				args.UpdateManifest(manifest)
-				err = manifest.Persist(args.OutDir)
-				if err != nil {
-					err = cli.Exit(fmt.Errorf("persist manifest failed: %v", err), meta.PersistError)
+				if perr := manifest.Persist(args.OutDir); perr != nil && err == nil {
+					err = errs.New(errs.PostProcess, "persist manifest failed: %v", perr)
 				}
 			}()
 		}
 
+		// the package template is rendered for the directives reading the template variables
+		var pkg string
+		pkg, err = renderTemplate(c, args.CustomizePackage)
+		if err != nil {
+			return err
+		}
+		defer hz_registry.RemoveTemplate(pkg)
+		args.CustomizePackage = pkg
+
 		err = app.TriggerPlugin(args)
 		if err != nil {
-			return cli.Exit(err, meta.PluginError)
+			return errs.Wrap(errs.IDLParse, err)
 		}
 		utils.ReplaceThriftVersion()
 	}
@@ -0,0 +1,323 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package tpl_validate
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// checker follows the type of dot and the variables through a parsed template, the fields
+// referenced on a known type must exist. A nil type is unknown, e.g. an interface{} or
+// the result of a function, and nothing is checked on it.
+type checker struct {
+	tree   *parse.Tree
+	funcs  template.FuncMap
+	root   reflect.Type
+	vars   []variable
+	issues []issue
+}
+
+type variable struct {
+	name string
+	typ  reflect.Type
+}
+
+// issue is a problem found at a line of a template text, counted from 1.
+type issue struct {
+	line int
+	msg  string
+}
+
+// checkTemplate checks the template and the ones it defines, the main one is rendered with data.
+func checkTemplate(t *template.Template, funcs template.FuncMap, data reflect.Type) []issue {
+	var issues []issue
+	for _, tt := range t.Templates() {
+		if tt.Tree == nil || tt.Tree.Root == nil {
+			continue
+		}
+		root := data
+		if tt.Name() != t.Name() {
+			// the data of a defined template is given by its callers
+			root = nil
+		}
+		c := &checker{tree: tt.Tree, funcs: funcs, root: root, vars: []variable{{"$", root}}}
+		c.walk(tt.Tree.Root, root)
+		issues = append(issues, c.issues...)
+	}
+	return issues
+}
+
+func (c *checker) report(n parse.Node, format string, args ...interface{}) {
+	loc, _ := c.tree.ErrorContext(n)
+	line := 0
+	// loc is name:line:col
+	if parts := strings.Split(loc, ":"); len(parts) >= 3 {
+		line, _ = strconv.Atoi(parts[len(parts)-2])
+	}
+	c.issues = append(c.issues, issue{line: line, msg: fmt.Sprintf(format, args...)})
+}
+
+func (c *checker) walk(n parse.Node, dot reflect.Type) {
+	switch n := n.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, item := range n.Nodes {
+			c.walk(item, dot)
+		}
+	case *parse.ActionNode:
+		c.pipe(n.Pipe, dot)
+	case *parse.IfNode:
+		c.branch(&n.BranchNode, dot, false)
+	case *parse.WithNode:
+		c.branch(&n.BranchNode, dot, true)
+	case *parse.RangeNode:
+		mark := len(c.vars)
+		typ := c.pipeType(n.Pipe, dot)
+		key, elem := rangeTypes(typ)
+		switch len(n.Pipe.Decl) {
+		case 1:
+			c.vars[len(c.vars)-1].typ = elem
+		case 2:
+			c.vars[len(c.vars)-2].typ = key
+			c.vars[len(c.vars)-1].typ = elem
+		}
+		c.walk(n.List, elem)
+		c.vars = c.vars[:mark]
+		c.walk(n.ElseList, dot)
+	case *parse.TemplateNode:
+		if n.Pipe != nil {
+			c.pipe(n.Pipe, dot)
+		}
+	}
+}
+
+// branch walks an if or with, the variables declared by its pipeline are visible in both lists.
+func (c *checker) branch(n *parse.BranchNode, dot reflect.Type, with bool) {
+	mark := len(c.vars)
+	typ := c.pipeType(n.Pipe, dot)
+	inner := dot
+	if with {
+		inner = typ
+	}
+	c.walk(n.List, inner)
+	c.walk(n.ElseList, dot)
+	c.vars = c.vars[:mark]
+}
+
+// pipe checks a pipeline of an action, the variables it declares stay until the end of the enclosing block.
+func (c *checker) pipe(p *parse.PipeNode, dot reflect.Type) {
+	c.pipeType(p, dot)
+}
+
+func (c *checker) pipeType(p *parse.PipeNode, dot reflect.Type) reflect.Type {
+	if p == nil {
+		return nil
+	}
+	var typ reflect.Type
+	for _, cmd := range p.Cmds {
+		typ = c.cmdType(cmd, dot)
+	}
+	if p.IsAssign {
+		for _, v := range p.Decl {
+			c.assign(v.Ident[0], typ)
+		}
+		return typ
+	}
+	for _, v := range p.Decl {
+		c.vars = append(c.vars, variable{v.Ident[0], typ})
+	}
+	return typ
+}
+
+func (c *checker) assign(name string, typ reflect.Type) {
+	for i := len(c.vars) - 1; i >= 0; i-- {
+		if c.vars[i].name == name {
+			// the type may change on assignment, it is not followed further
+			if c.vars[i].typ != typ {
+				c.vars[i].typ = nil
+			}
+			return
+		}
+	}
+}
+
+func (c *checker) lookup(name string) reflect.Type {
+	for i := len(c.vars) - 1; i >= 0; i-- {
+		if c.vars[i].name == name {
+			return c.vars[i].typ
+		}
+	}
+	return nil
+}
+
+func (c *checker) cmdType(cmd *parse.CommandNode, dot reflect.Type) reflect.Type {
+	for _, arg := range cmd.Args[1:] {
+		c.argType(arg, dot)
+	}
+	switch first := cmd.Args[0].(type) {
+	case *parse.IdentifierNode:
+		f, ok := c.funcs[first.Ident]
+		if !ok {
+			// the built-in functions such as len, index and printf
+			return builtinType(first.Ident)
+		}
+		ft := reflect.TypeOf(f)
+		if ft == nil || ft.Kind() != reflect.Func || ft.NumOut() == 0 {
+			return nil
+		}
+		return known(ft.Out(0))
+	default:
+		return c.argType(first, dot)
+	}
+}
+
+func (c *checker) argType(arg parse.Node, dot reflect.Type) reflect.Type {
+	switch arg := arg.(type) {
+	case *parse.DotNode:
+		return dot
+	case *parse.FieldNode:
+		return c.fields(arg, dot, arg.Ident)
+	case *parse.VariableNode:
+		typ := c.lookup(arg.Ident[0])
+		return c.fields(arg, typ, arg.Ident[1:])
+	case *parse.ChainNode:
+		var typ reflect.Type
+		switch node := arg.Node.(type) {
+		case *parse.PipeNode:
+			typ = c.pipeType(node, dot)
+		default:
+			typ = c.argType(node, dot)
+		}
+		return c.fields(arg, typ, arg.Field)
+	case *parse.PipeNode:
+		return c.pipeType(arg, dot)
+	case *parse.StringNode:
+		return reflect.TypeOf("")
+	case *parse.BoolNode:
+		return reflect.TypeOf(true)
+	case *parse.NumberNode:
+		if arg.IsInt {
+			return reflect.TypeOf(0)
+		}
+		return nil
+	}
+	return nil
+}
+
+// fields resolves the chain of fields or methods on typ.
+func (c *checker) fields(n parse.Node, typ reflect.Type, names []string) reflect.Type {
+	for _, name := range names {
+		if typ == nil {
+			return nil
+		}
+		next, ok := field(typ, name)
+		if !ok {
+			c.report(n, "can't evaluate field %s in type %s", name, typeName(typ))
+			return nil
+		}
+		typ = next
+	}
+	return typ
+}
+
+// field returns the type of the field or the result of the method name of typ, ok is false if it has none.
+func field(typ reflect.Type, name string) (reflect.Type, bool) {
+	mt := typ
+	if mt.Kind() != reflect.Ptr {
+		// the data is addressable, the methods of the pointer are reachable
+		mt = reflect.PtrTo(typ)
+	}
+	if m, ok := mt.MethodByName(name); ok {
+		if m.Type.NumOut() == 0 {
+			return nil, true
+		}
+		return known(m.Type.Out(0)), true
+	}
+	base := typ
+	for base.Kind() == reflect.Ptr {
+		base = base.Elem()
+	}
+	switch base.Kind() {
+	case reflect.Struct:
+		f, ok := base.FieldByName(name)
+		if !ok || !f.IsExported() {
+			return nil, false
+		}
+		return known(f.Type), true
+	case reflect.Map:
+		// any key may be present in a map
+		return known(base.Elem()), true
+	case reflect.Interface:
+		return nil, true
+	}
+	return nil, false
+}
+
+func typeName(typ reflect.Type) string {
+	if name, ok := dataNames[typ]; ok {
+		return name
+	}
+	return typ.String()
+}
+
+// known returns nil for the types whose dynamic value is unknown.
+func known(typ reflect.Type) reflect.Type {
+	if typ.Kind() == reflect.Interface {
+		return nil
+	}
+	return typ
+}
+
+func rangeTypes(typ reflect.Type) (key, elem reflect.Type) {
+	if typ == nil {
+		return nil, nil
+	}
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	switch typ.Kind() {
+	case reflect.Slice, reflect.Array:
+		return reflect.TypeOf(0), known(typ.Elem())
+	case reflect.Map:
+		return known(typ.Key()), known(typ.Elem())
+	case reflect.Chan:
+		return known(typ.Elem()), nil
+	case reflect.Int:
+		return typ, typ
+	}
+	return nil, nil
+}
+
+func builtinType(name string) reflect.Type {
+	switch name {
+	case "len":
+		return reflect.TypeOf(0)
+	case "and", "or", "index", "slice", "call":
+		return nil
+	case "not", "eq", "ne", "lt", "le", "gt", "ge":
+		return reflect.TypeOf(true)
+	case "print", "printf", "println", "html", "js", "urlquery":
+		return reflect.TypeOf("")
+	}
+	return nil
+}
@@ -0,0 +1,487 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package tpl_validate
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/sprig/v3"
+	"github.com/cloudwego/cwgo/pkg/common/errs"
+	"github.com/cloudwego/cwgo/pkg/common/hz_registry"
+	"github.com/cloudwego/cwgo/pkg/common/utils"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/cloudwego/cwgo/tpl"
+	hzgen "github.com/cloudwego/hertz/cmd/hz/generator"
+	hzutil "github.com/cloudwego/hertz/cmd/hz/util"
+	"github.com/cloudwego/kitex/tool/internal_pkg/generator"
+	"github.com/cloudwego/kitex/tool/internal_pkg/util"
+	"gopkg.in/yaml.v3"
+)
+
+// Problem is a problem found in a template file, Line is 0 when it is about the whole file.
+type Problem struct {
+	File string
+	Line int
+	Msg  string
+}
+
+func (p Problem) String() string {
+	if p.Line == 0 {
+		return fmt.Sprintf("%s: %s", p.File, p.Msg)
+	}
+	return fmt.Sprintf("%s:%d: %s", p.File, p.Line, p.Msg)
+}
+
+var (
+	// parseError matches the errors of text/template, e.g. template: body:3: function "Foo" not defined.
+	parseError = regexp.MustCompile(`(?s)^template: [^:]*:(\d+):(?:\d+:)?\s*(.*)$`)
+	// yamlError matches the lines of the errors of yaml, e.g. line 3: field foo not found in type.
+	yamlError = regexp.MustCompile(`line (\d+): (.*)`)
+	// directive matches the cwgo directives of the hertz templates, see hz_registry.Delims.
+	directive = regexp.MustCompile(`(?s)` + regexp.QuoteMeta(hz_registry.Delims[0]) + `.*?` + regexp.QuoteMeta(hz_registry.Delims[1]))
+)
+
+var updateTypes = []string{"", "skip", "cover", "append"}
+
+// the render data of the hertz layouts, hz renders them with maps of these keys
+type (
+	layoutData struct {
+		GoModule        string
+		ServiceName     string
+		UseApacheThrift bool
+		HandlerPkg      string
+		RouterPkg       string
+	}
+	routerData struct {
+		Registers      []string
+		RouterPkgPath  string
+		HandlerPkgPath string
+	}
+	middlewareData struct {
+		MiddleWare string
+	}
+)
+
+// dataNames are the names of the render data given as maps by hz.
+var dataNames = map[reflect.Type]string{
+	reflect.TypeOf(layoutData{}):     "layout data",
+	reflect.TypeOf(routerData{}):     "router data",
+	reflect.TypeOf(middlewareData{}): "middleware data",
+}
+
+// hzPackageData are the render data of the default hertz package templates.
+var hzPackageData = map[string]reflect.Type{
+	"handler.go":           reflect.TypeOf(hzgen.Handler{}),
+	"handler_single.go":    reflect.TypeOf(hzgen.SingleHandler{}),
+	"router.go":            reflect.TypeOf(hzgen.Router{}),
+	"middleware.go":        reflect.TypeOf(hzgen.Router{}),
+	"middleware_single.go": reflect.TypeOf(middlewareData{}),
+	"register.go":          reflect.TypeOf(hzgen.RegisterInfo{}),
+	"client.go":            reflect.TypeOf(hzgen.Client{}),
+	"hertz_client.go":      reflect.TypeOf(hzgen.ClientFile{}),
+	"idl_client.go":        reflect.TypeOf(hzgen.ClientFile{}),
+}
+
+// Validate validates the templates of a directory or a git repository checked out at branch.
+func Validate(target, branch string) ([]Problem, error) {
+	if !strings.HasSuffix(target, consts.SuffixGit) {
+		return ValidateDir(target)
+	}
+	tmp, err := os.MkdirTemp("", "cwgo-validate-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+	if err = utils.GitClone(target, tmp); err != nil {
+		return nil, fmt.Errorf("git clone %s failed: %w", target, err)
+	}
+	repo, err := utils.GitPath(target)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(tmp, repo)
+	if err = utils.GitCheckout(branch, dir); err != nil {
+		return nil, fmt.Errorf("git checkout %s failed: %w", branch, err)
+	}
+	return ValidateDir(dir)
+}
+
+// ValidateDir validates the yaml templates under dir, the other yaml files are skipped.
+// The paths of the problems are relative to dir, the files are walked in lexical order.
+func ValidateDir(dir string) ([]Problem, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, errs.New(errs.InvalidArgs, "read templates failed: %s", err)
+	}
+	if !info.IsDir() {
+		return nil, errs.New(errs.InvalidArgs, "%s is not a directory", dir)
+	}
+
+	var problems []Problem
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == consts.SuffixGit {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		ext := filepath.Ext(path)
+		if (ext != ".yaml" && ext != ".yml") || d.Name() == generator.ExtensionFilename {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		problems = append(problems, ValidateFile(filepath.ToSlash(rel), content)...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return problems, nil
+}
+
+// ValidateFile validates a kitex template or a hertz layout or package template named name,
+// the content is not a template if it has neither the layouts of hertz nor the path or body of kitex.
+func ValidateFile(name string, content []byte) []Problem {
+	v := &validator{file: name}
+
+	if bytes.Contains(content, []byte("layouts:")) && directive.Match(content) {
+		v.directives(content)
+		// the directives are blanked out keeping the lines, the bodies of all the branches are validated
+		content = directive.ReplaceAllFunc(content, func(d []byte) []byte {
+			return bytes.Map(func(r rune) rune {
+				if r == '\n' {
+					return r
+				}
+				return ' '
+			}, d)
+		})
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		v.yamlError(err)
+		return v.problems
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return v.problems
+	}
+	root := doc.Content[0]
+	switch {
+	case lookup(root, "layouts") != nil:
+		v.hertz(content, root, filepath.Base(name) == consts.PackageLayoutFile)
+	case lookup(root, "path") != nil || lookup(root, "body") != nil:
+		v.kitex(content, root)
+	}
+	sort.SliceStable(v.problems, func(i, j int) bool {
+		return v.problems[i].Line < v.problems[j].Line
+	})
+	return v.problems
+}
+
+type validator struct {
+	file     string
+	problems []Problem
+}
+
+func (v *validator) report(line int, format string, args ...interface{}) {
+	v.problems = append(v.problems, Problem{File: v.file, Line: line, Msg: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) yamlError(err error) {
+	found := false
+	for _, line := range strings.Split(err.Error(), consts.LineBreak) {
+		if m := yamlError.FindStringSubmatch(line); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			v.report(n, "%s", m[2])
+			found = true
+		}
+	}
+	if !found {
+		v.report(0, "%s", err)
+	}
+}
+
+// directives validates the cwgo directives of a hertz template against the registry data.
+func (v *validator) directives(content []byte) {
+	t, err := template.New(v.file).Delims(hz_registry.Delims[0], hz_registry.Delims[1]).Funcs(hz_registry.FuncMap).Parse(string(content))
+	if err != nil {
+		v.templateError(1, "directives", err)
+		return
+	}
+	for _, is := range checkTemplate(t, hz_registry.FuncMap, reflect.TypeOf(&hz_registry.Data{})) {
+		v.report(is.line, "directives: %s", is.msg)
+	}
+}
+
+func (v *validator) templateError(start int, field string, err error) {
+	if m := parseError.FindStringSubmatch(err.Error()); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		v.report(start+n-1, "%s: %s", field, m[2])
+		return
+	}
+	v.report(start, "%s: %s", field, err)
+}
+
+// text validates a template text of the field whose value is node, it is rendered with data.
+func (v *validator) text(field string, node *yaml.Node, delims [2]string, funcs template.FuncMap, data reflect.Type) {
+	if node == nil || node.Value == "" {
+		return
+	}
+	start := node.Line
+	if node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		// the text of a block starts on the line after its indicator
+		start++
+	}
+	t := template.New(field).Funcs(funcs)
+	if delims[0] != "" && delims[1] != "" {
+		t = t.Delims(delims[0], delims[1])
+	}
+	t, err := t.Parse(node.Value)
+	if err != nil {
+		v.templateError(start, field, err)
+		return
+	}
+	for _, is := range checkTemplate(t, funcs, data) {
+		v.report(start+is.line-1, "%s: %s", field, is.msg)
+	}
+}
+
+func (v *validator) updateType(node *yaml.Node) string {
+	typ := lookup(node, "type")
+	if typ == nil {
+		return ""
+	}
+	if !contains(updateTypes, typ.Value) {
+		v.report(typ.Line, "unsupported update_behavior type %s (support skip, cover, append)", typ.Value)
+	}
+	return typ.Value
+}
+
+// kitex validates a kitex template, it is rendered with the package info.
+func (v *validator) kitex(content []byte, root *yaml.Node) {
+	dec := yaml.NewDecoder(bytes.NewReader(content))
+	dec.KnownFields(true)
+	if err := dec.Decode(new(generator.Template)); err != nil {
+		v.yamlError(err)
+	}
+
+	funcs := kitexFuncs()
+	data := reflect.TypeOf(&generator.PackageInfo{})
+	delims := generator.DefaultDelimiters
+	path := lookup(root, "path")
+	if path == nil || path.Value == "" {
+		v.report(root.Line, "path is empty")
+	} else {
+		v.text("path", path, delims, funcs, data)
+	}
+	v.text("body", lookup(root, "body"), delims, funcs, data)
+
+	update := lookup(root, "update_behavior")
+	if update == nil {
+		return
+	}
+	if v.updateType(update) == "append" {
+		if lookup(update, "key") == nil {
+			v.report(update.Line, "update_behavior key is required by append")
+		}
+		if lookup(update, "append_tpl") == nil {
+			v.report(update.Line, "update_behavior append_tpl is required by append")
+		}
+	}
+	v.text("update_behavior.key", lookup(update, "key"), delims, funcs, data)
+	v.text("update_behavior.append_tpl", lookup(update, "append_tpl"), delims, funcs, data)
+	if imports := lookup(update, "import_tpl"); imports != nil {
+		for _, n := range imports.Content {
+			v.text("update_behavior.import_tpl", n, delims, funcs, data)
+		}
+	}
+}
+
+// hertz validates the templates of a hertz layout or package file.
+func (v *validator) hertz(content []byte, root *yaml.Node, pkg bool) {
+	dec := yaml.NewDecoder(bytes.NewReader(content))
+	dec.KnownFields(true)
+	if err := dec.Decode(new(hzgen.TemplateConfig)); err != nil {
+		v.yamlError(err)
+	}
+
+	funcs := hzFuncs()
+	layouts := lookup(root, "layouts")
+	if layouts.Kind != yaml.SequenceNode {
+		v.report(layouts.Line, "layouts is not a list")
+		return
+	}
+	for _, l := range layouts.Content {
+		if l.Kind != yaml.MappingNode {
+			continue
+		}
+		var delims [2]string
+		if d := lookup(l, "delims"); d != nil && len(d.Content) == 2 {
+			delims = [2]string{d.Content[0].Value, d.Content[1].Value}
+			if (delims[0] == "") != (delims[1] == "") {
+				v.report(d.Line, "both delims are required")
+			}
+		}
+		path := lookup(l, "path")
+		if path == nil || path.Value == "" {
+			v.report(l.Line, "path is empty")
+			continue
+		}
+		if filepath.IsAbs(path.Value) {
+			v.report(path.Line, "absolute path %s is not allowed", path.Value)
+		}
+
+		update := lookup(l, "update_behavior")
+		appendKey := ""
+		if update != nil {
+			v.updateType(update)
+			if k := lookup(update, "append_key"); k != nil {
+				appendKey = k.Value
+				if appendKey != "" && appendKey != "method" && appendKey != "service" {
+					v.report(k.Line, "unsupported append_key %s (support method, service)", appendKey)
+				}
+			}
+		}
+
+		data, appendData := v.hertzData(path.Value, l, pkg, appendKey)
+		if pkg && !hzgen.IsDefaultPackageTpl(path.Value) {
+			v.text("path", path, delims, funcs, reflect.TypeOf(hzgen.FilePathRenderInfo{}))
+		}
+		v.text("body", lookup(l, "body"), delims, funcs, data)
+		if update != nil {
+			v.text("update_behavior.insert_key", lookup(update, "insert_key"), delims, funcs, appendData)
+			v.text("update_behavior.append_content_tpl", lookup(update, "append_content_tpl"), delims, funcs, appendData)
+			if imports := lookup(update, "import_tpl"); imports != nil {
+				for _, n := range imports.Content {
+					v.text("update_behavior.import_tpl", n, delims, funcs, appendData)
+				}
+			}
+		}
+	}
+}
+
+// hertzData returns the render data of the body and of the appended content of a hertz template.
+func (v *validator) hertzData(path string, l *yaml.Node, pkg bool, appendKey string) (body, appended reflect.Type) {
+	if !pkg {
+		if path == "router.go" || path == hzgen.RegisterFile {
+			return reflect.TypeOf(routerData{}), nil
+		}
+		return reflect.TypeOf(layoutData{}), nil
+	}
+	if hzgen.IsDefaultPackageTpl(path) {
+		return hzPackageData[path], nil
+	}
+
+	method := reflect.TypeOf(hzgen.CustomizedFileForMethod{})
+	service := reflect.TypeOf(hzgen.CustomizedFileForService{})
+	switch {
+	case isTrue(lookup(l, "loop_method")):
+		body = method
+	case isTrue(lookup(l, "loop_service")):
+		body = service
+	default:
+		body = reflect.TypeOf(hzgen.CustomizedFileForIDL{})
+	}
+	switch appendKey {
+	case "method":
+		return body, method
+	case "service":
+		return body, service
+	}
+	return body, body
+}
+
+func kitexFuncs() template.FuncMap {
+	funcs := template.FuncMap{
+		"ToLower":       strings.ToLower,
+		"LowerFirst":    util.LowerFirst,
+		"UpperFirst":    util.UpperFirst,
+		"NotPtr":        util.NotPtr,
+		"ReplaceString": util.ReplaceString,
+		"SnakeString":   util.SnakeString,
+		"HasFeature":    generator.HasFeature,
+		"FilterImports": generator.FilterImports,
+		"backquoted":    generator.BackQuoted,
+	}
+	for k, f := range tpl.KitexFuncs() {
+		funcs[k] = f
+	}
+	return funcs
+}
+
+func hzFuncs() template.FuncMap {
+	funcs := template.FuncMap{
+		"GetUniqueHandlerOutDir": func([]*hzgen.HttpMethod) []string { return nil },
+		"ToSnakeCase":            hzutil.ToSnakeCase,
+		"Split":                  strings.Split,
+		"Trim":                   strings.Trim,
+		"EqualFold":              strings.EqualFold,
+	}
+	for k, f := range sprig.TxtFuncMap() {
+		funcs[k] = f
+	}
+	return funcs
+}
+
+// lookup returns the value of key in the mapping node, nil if it is not set.
+func lookup(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func isTrue(node *yaml.Node) bool {
+	if node == nil {
+		return false
+	}
+	b, _ := strconv.ParseBool(node.Value)
+	return b
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package tpl_validate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func messages(problems []Problem) []string {
+	var res []string
+	for _, p := range problems {
+		res = append(res, p.String())
+	}
+	return res
+}
+
+func TestEmbedded(t *testing.T) {
+	problems, err := ValidateDir("../../tpl")
+	assert.NoError(t, err)
+	assert.Empty(t, messages(problems))
+
+	_, err = ValidateDir("missing")
+	assert.Error(t, err)
+}
+
+func TestKitex(t *testing.T) {
+	content := `path: biz/{{.ServiceName}}/{{.Nope}}.go
+update_behavior:
+  type: append
+  append_tpl: |-
+    {{range .Methods}}{{.Name}}{{.Request}}{{end}}
+body: |-
+  package main
+  {{- range $i, $m := .AllMethods}}
+  // {{$i}} {{$m.Name}} {{$m.Missing}}
+  {{- with $m.Resp}}{{.Type}}{{.Bad}}{{end}}
+  {{- end}}
+  {{$.Features}} {{$x := .PkgName}}{{$x.Len}}
+  {{ToCamel .ServiceName | printf "%s"}}
+`
+	assert.Equal(t, []string{
+		"main.go:1: path: can't evaluate field Nope in type *generator.PackageInfo",
+		"main.go:3: update_behavior key is required by append",
+		"main.go:5: update_behavior.append_tpl: can't evaluate field Request in type *generator.MethodInfo",
+		"main.go:9: body: can't evaluate field Missing in type *generator.MethodInfo",
+		"main.go:10: body: can't evaluate field Bad in type *generator.Parameter",
+		"main.go:12: body: can't evaluate field Len in type string",
+	}, messages(ValidateFile("main.go", []byte(content))))
+
+	assert.Equal(t, []string{
+		"main.go:2: field update_behaviour not found in type generator.Template",
+		"main.go:4: body: function \"Nope\" not defined",
+	}, messages(ValidateFile("main.go", []byte("path: main.go\nupdate_behaviour:\nbody: |-\n  {{Nope .}}\n"))))
+	assert.Equal(t, []string{
+		"main.go:1: unsupported update_behavior type merge (support skip, cover, append)",
+	}, messages(ValidateFile("main.go", []byte("update_behavior: {type: merge}\npath: main.go\n"))))
+
+	// other yaml files are not templates
+	assert.Empty(t, ValidateFile("docker-compose.yaml", []byte("services:\n  mysql:\n    image: mysql\n")))
+	assert.NotEmpty(t, ValidateFile("broken.yaml", []byte("path: [\n")))
+}
+
+func TestHertz(t *testing.T) {
+	layout := `[[- $etcd := eq .Registry "etcd"]]
+layouts:
+  - path: main.go
+    delims:
+      - ""
+      - ""
+    body: |-
+      package main
+      [[- if $etcd]]
+      // {{.GoModule}} {{.GoModul}} [[.Nope]]
+      [[- end]]
+  - path: router.go
+    body: |-
+      {{range .Registers}}{{.}}{{end}} {{.GoModule}}
+`
+	assert.Equal(t, []string{
+		"layout.yaml:10: directives: can't evaluate field Nope in type *hz_registry.Data",
+		"layout.yaml:10: body: can't evaluate field GoModul in type layout data",
+		"layout.yaml:14: body: can't evaluate field GoModule in type router data",
+	}, messages(ValidateFile("layout.yaml", []byte(layout))))
+
+	pkg := `layouts:
+  - path: handler.go
+    body: |-
+      {{range .Methods}}{{.Name}}{{.Nope}}{{end}}
+  - path: "biz/service/{{.HandlerGenPath}}/{{ToSnakeCase .MethodNam}}.go"
+    loop_method: true
+    update_behavior:
+      type: append
+      append_key: service
+      insert_key: "{{.Name}}"
+      append_content_tpl: "{{.ServiceInfo}}"
+    body: |-
+      {{.Name}} {{.ServiceInfo.Name}} {{.IDLPackageInfo.GoModule}}
+  - path: custom.go
+    delims: ["<<", ""]
+    body: "<< .Wrong"
+`
+	assert.Equal(t, []string{
+		"package.yaml:4: body: can't evaluate field Nope in type *generator.HttpMethod",
+		"package.yaml:5: path: can't evaluate field MethodNam in type generator.FilePathRenderInfo",
+		"package.yaml:11: update_behavior.append_content_tpl: can't evaluate field ServiceInfo in type generator.CustomizedFileForService",
+		"package.yaml:15: both delims are required",
+	}, messages(ValidateFile("package.yaml", []byte(pkg))))
+
+	assert.Equal(t, []string{
+		"layout.yaml:4: directives: unexpected EOF",
+	}, messages(ValidateFile("layout.yaml", []byte("[[if .Otel]]\nlayouts:\n  - path: main.go\n"))))
+}
@@ -0,0 +1,220 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/cloudwego/cwgo/pkg/common/parser"
+	"github.com/cloudwego/cwgo/pkg/common/utils"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/cloudwego/hertz/cmd/hz/util/logs"
+)
+
+const (
+	DefaultInterval = 300 * time.Millisecond
+	DefaultDebounce = 500 * time.Millisecond
+)
+
+// Target is a generator together with the files it is generated from.
+type Target struct {
+	Name string
+	// Files lists the files to watch, it is called again after every run
+	// because the includes of an IDL may have changed.
+	Files func() ([]string, error)
+	Run   func() error
+
+	files   []string
+	stamps  map[string]stamp
+	pending bool
+	changed time.Time
+}
+
+type stamp struct {
+	modTime time.Time
+	size    int64
+	exist   bool
+}
+
+type Watcher struct {
+	// Interval is how often the files are polled.
+	Interval time.Duration
+	// Debounce is how long the files of a target have to stay unchanged
+	// before the target runs again, so a burst of saves triggers one run.
+	Debounce time.Duration
+}
+
+func New() *Watcher {
+	return &Watcher{
+		Interval: DefaultInterval,
+		Debounce: DefaultDebounce,
+	}
+}
+
+// Watch runs every target once, then reruns a target whenever one of its files
+// changes, until ctx is done. Errors of a run are reported and watching goes on.
+func (w *Watcher) Watch(ctx context.Context, targets ...*Target) error {
+	for _, t := range targets {
+		w.run(t)
+	}
+	logs.Infof("watching for changes, press Ctrl+C to stop")
+
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			for _, t := range targets {
+				if t.poll() {
+					t.pending, t.changed = true, now
+				}
+				if t.pending && now.Sub(t.changed) >= w.Debounce {
+					w.run(t)
+				}
+			}
+		}
+	}
+}
+
+func (w *Watcher) run(t *Target) {
+	t.pending = false
+	// the files are stamped before the run, so a save made while
+	// generating is compared against them and triggers another run
+	if t.files == nil {
+		t.files, _ = t.Files()
+	}
+	before := make(map[string]stamp, len(t.files))
+	for _, f := range t.files {
+		before[f] = stat(f)
+	}
+
+	if err := t.Run(); err != nil {
+		logs.Errorf("%s generation failed: %v", t.Name, err)
+	} else {
+		logs.Infof("%s generated", t.Name)
+	}
+
+	files, err := t.Files()
+	if err != nil {
+		// keep watching what is known, the next save will be picked up anyway
+		logs.Warnf("%s: resolve watched files failed: %v", t.Name, err)
+		files = append(files, t.files...)
+	}
+	t.files = dedup(files)
+	t.stamps = make(map[string]stamp, len(t.files))
+	for _, f := range t.files {
+		if s, ok := before[f]; ok {
+			t.stamps[f] = s
+		}
+	}
+	if t.poll() {
+		t.pending, t.changed = true, time.Now()
+	}
+}
+
+// poll stats the files of the target and reports whether any of them changed since the last poll.
+func (t *Target) poll() bool {
+	changed := false
+	for _, f := range t.files {
+		s := stat(f)
+		if old, ok := t.stamps[f]; ok && old != s {
+			changed = true
+		}
+		t.stamps[f] = s
+	}
+	return changed
+}
+
+func stat(path string) stamp {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return stamp{}
+	}
+	return stamp{modTime: fi.ModTime(), size: fi.Size(), exist: true}
+}
+
+func dedup(files []string) []string {
+	sort.Strings(files)
+	res := files[:0]
+	for i, f := range files {
+		if i == 0 || f != files[i-1] {
+			res = append(res, f)
+		}
+	}
+	return res
+}
+
+// IDLFiles returns the IDL and all the IDLs it includes or imports.
+// The IDL itself is returned even if resolving its includes fails.
+func IDLFiles(idl string, searchPaths []string) ([]string, error) {
+	idl, err := filepath.Abs(idl)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{idl}
+
+	idlType, err := utils.GetIdlType(idl, consts.Protobuf)
+	if err != nil {
+		return files, err
+	}
+	switch idlType {
+	case consts.Thrift:
+		deps, err := parser.NewThriftParser().GetDependentFilePaths(idl, searchPaths)
+		if err != nil {
+			return files, err
+		}
+		files = append(files, deps...)
+	case consts.Protobuf:
+		baseDir := filepath.Dir(idl)
+		rel, deps, err := parser.NewProtoParser().GetDependentFilePaths(baseDir, filepath.Base(idl))
+		if err != nil {
+			return files, err
+		}
+		for _, dep := range deps {
+			files = append(files, filepath.Join(baseDir, rel, filepath.FromSlash(dep)))
+		}
+	}
+	return files, nil
+}
+
+// SQLFiles returns the sql file, or every file under the sql dir the same way
+// the sql is read for generation. The dirs are returned as well, so added and
+// removed files are noticed.
+func SQLFiles(path string) ([]string, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	err = filepath.Walk(path, func(p string, _ os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		files = append(files, p)
+		return nil
+	})
+	if len(files) == 0 {
+		files = append(files, path)
+	}
+	return files, err
+}
@@ -0,0 +1,143 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func write(t *testing.T, path, content string) {
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func TestIDLFiles(t *testing.T) {
+	dir := t.TempDir()
+	write(t, filepath.Join(dir, "idl", "base", "base.thrift"), "namespace go base\nstruct Base {}\n")
+	write(t, filepath.Join(dir, "idl", "common.thrift"), "include \"base/base.thrift\"\nnamespace go common\nstruct Common { 1: base.Base b }\n")
+	write(t, filepath.Join(dir, "idl", "hello.thrift"), "include \"common.thrift\"\ninclude \"base/base.thrift\"\nnamespace go hello\nservice Hello { common.Common Echo(1: base.Base req) }\n")
+
+	files, err := IDLFiles(filepath.Join(dir, "idl", "hello.thrift"), nil)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		filepath.Join(dir, "idl", "hello.thrift"),
+		filepath.Join(dir, "idl", "common.thrift"),
+		filepath.Join(dir, "idl", "base", "base.thrift"),
+	}, files)
+
+	write(t, filepath.Join(dir, "proto", "api", "api.proto"), "syntax = \"proto3\";\npackage api;\n")
+	write(t, filepath.Join(dir, "proto", "hello", "hello.proto"), "syntax = \"proto3\";\npackage hello;\nimport \"api/api.proto\";\n")
+
+	files, err = IDLFiles(filepath.Join(dir, "proto", "hello", "hello.proto"), nil)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		filepath.Join(dir, "proto", "hello", "hello.proto"),
+		filepath.Join(dir, "proto", "api", "api.proto"),
+	}, files)
+
+	// without imports only the IDL itself is watched
+	files, err = IDLFiles(filepath.Join(dir, "proto", "api", "api.proto"), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "proto", "api", "api.proto")}, files)
+
+	// a broken IDL is still watched, so fixing it triggers a run
+	write(t, filepath.Join(dir, "idl", "broken.thrift"), "struct {")
+	files, err = IDLFiles(filepath.Join(dir, "idl", "broken.thrift"), nil)
+	assert.Error(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "idl", "broken.thrift")}, files)
+}
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a.sql"), filepath.Join(dir, "b.sql")
+	write(t, a, "CREATE TABLE a (id int);")
+	write(t, b, "CREATE TABLE b (id int);")
+
+	var runsA, runsB int32
+	targetA := &Target{
+		Name:  "a",
+		Files: func() ([]string, error) { return []string{a}, nil },
+		Run:   func() error { atomic.AddInt32(&runsA, 1); return nil },
+	}
+	targetB := &Target{
+		Name:  "b",
+		Files: func() ([]string, error) { return []string{b}, nil },
+		Run:   func() error { atomic.AddInt32(&runsB, 1); return os.ErrInvalid },
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	w := &Watcher{Interval: 10 * time.Millisecond, Debounce: 100 * time.Millisecond}
+	go func() { done <- w.Watch(ctx, targetA, targetB) }()
+
+	// both run once on start, a failing run does not stop watching
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&runsA) == 1 && atomic.LoadInt32(&runsB) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	// a burst of saves reruns only the affected target, and only once
+	for i := 0; i < 3; i++ {
+		write(t, a, "CREATE TABLE a (id int, name text"+strings.Repeat(" ", i)+");")
+		time.Sleep(20 * time.Millisecond)
+	}
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&runsA) == 2 }, time.Second, 10*time.Millisecond)
+	time.Sleep(200 * time.Millisecond)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&runsA))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&runsB))
+
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestWatchSaveDuringRun(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.sql")
+	write(t, a, "CREATE TABLE a (id int);")
+
+	var runs int32
+	target := &Target{
+		Name:  "a",
+		Files: func() ([]string, error) { return []string{a}, nil },
+		Run: func() error {
+			// the file is saved while the first generation is running
+			if atomic.AddInt32(&runs, 1) == 1 {
+				write(t, a, "CREATE TABLE a (id int, name text);")
+			}
+			return nil
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	w := &Watcher{Interval: 10 * time.Millisecond, Debounce: 50 * time.Millisecond}
+	go func() { done <- w.Watch(ctx, target) }()
+
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&runs) == 2 }, time.Second, 10*time.Millisecond)
+	time.Sleep(150 * time.Millisecond)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&runs))
+
+	cancel()
+	assert.NoError(t, <-done)
+}
@@ -0,0 +1,492 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package wizard asks for the arguments of a generator interactively.
+package wizard
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/AlecAivazis/survey/v2/core"
+	"github.com/cloudwego/cwgo/config"
+	"github.com/cloudwego/cwgo/pkg/client"
+	"github.com/cloudwego/cwgo/pkg/common/config_center"
+	"github.com/cloudwego/cwgo/pkg/common/kx_registry"
+	"github.com/cloudwego/cwgo/pkg/common/observability"
+	"github.com/cloudwego/cwgo/pkg/common/utils"
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/cloudwego/cwgo/pkg/curd/doc"
+	"github.com/cloudwego/cwgo/pkg/job"
+	"github.com/cloudwego/cwgo/pkg/model"
+	"github.com/cloudwego/cwgo/pkg/server"
+)
+
+// the commands of cmd/static the wizard builds a command line for
+const (
+	serverCommand = "server"
+	clientCommand = "client"
+	modelCommand  = "model"
+	docCommand    = "doc"
+	jobCommand    = "job"
+)
+
+const (
+	none           = "none"
+	defaultTpl     = "default"
+	customTpl      = "custom (local path or git URL)"
+	dsnSource      = "DSN"
+	sqlFilesSource = "SQL files"
+)
+
+// Result is the outcome of the wizard.
+type Result struct {
+	// Args is the equivalent non-interactive command line, without the program name.
+	Args []string
+	// DSN is kept out of Args, it should be passed with the CWGO_DSN environment variable.
+	DSN string
+	// Generate tells whether the user wants to generate now.
+	Generate bool
+}
+
+type wizard struct {
+	opts []survey.AskOpt
+}
+
+// Run asks what to generate and the arguments of the generator, every answer is validated
+// with the Check function of the generator. opts are passed to survey, e.g. survey.WithStdio.
+func Run(opts ...survey.AskOpt) (*Result, error) {
+	w := &wizard{opts: opts}
+	var command string
+	err := w.ask(&survey.Select{
+		Message: "What do you want to generate?",
+		Options: []string{serverCommand, clientCommand, modelCommand, docCommand, jobCommand},
+	}, &command, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Result{}
+	var args []string
+	switch command {
+	case serverCommand:
+		args, err = w.server()
+	case clientCommand:
+		args, err = w.client()
+	case modelCommand:
+		args, r.DSN, err = w.model()
+	case docCommand:
+		args, err = w.doc()
+	case jobCommand:
+		args, err = w.job()
+	}
+	if err != nil {
+		return nil, err
+	}
+	r.Args = append([]string{command}, args...)
+
+	if err = w.ask(&survey.Confirm{Message: "Generate now?", Default: true}, &r.Generate, nil); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (w *wizard) ask(p survey.Prompt, response interface{}, v survey.Validator) error {
+	opts := w.opts
+	if v != nil {
+		opts = append(opts[:len(opts):len(opts)], survey.WithValidator(v))
+	}
+	return survey.AskOne(p, response, opts...)
+}
+
+func (w *wizard) server() ([]string, error) {
+	sa := config.NewServerArgument()
+	sa.Type = consts.RPC
+	validate := func(set func(sa *config.ServerArgument, ans string)) survey.Validator {
+		return func(ans interface{}) error {
+			cp := sa.Clone()
+			set(cp, answer(ans))
+			return server.Check(cp)
+		}
+	}
+
+	if err := w.ask(&survey.Input{Message: "Server name:"}, &sa.ServerName, validate(func(sa *config.ServerArgument, ans string) {
+		sa.ServerName = ans
+	})); err != nil {
+		return nil, err
+	}
+	if err := w.ask(&survey.Select{Message: "Service type:", Options: []string{consts.RPC, consts.HTTP}}, &sa.Type, validate(func(sa *config.ServerArgument, ans string) {
+		sa.Type = ans
+	})); err != nil {
+		return nil, err
+	}
+	// hz creates an HTTP server without IDL
+	if err := w.idl(&sa.IdlPath, sa.SliceParam, strings.EqualFold(sa.Type, consts.HTTP)); err != nil {
+		return nil, err
+	}
+	registry, err := w.registry(validate(func(sa *config.ServerArgument, ans string) {
+		sa.Registry = noneValue(ans)
+	}))
+	if err != nil {
+		return nil, err
+	}
+	sa.Registry = registry
+	center, err := w.configCenter(validate(func(sa *config.ServerArgument, ans string) {
+		sa.ConfigCenter = noneValue(ans)
+	}))
+	if err != nil {
+		return nil, err
+	}
+	sa.ConfigCenter = center
+	if sa.Observability, err = w.observability(observability.ServerBackends); err != nil {
+		return nil, err
+	}
+	if sa.Template, err = w.template(); err != nil {
+		return nil, err
+	}
+	if sa.GoMod, err = w.module(validate(func(sa *config.ServerArgument, ans string) {
+		sa.GoMod = ans
+	})); err != nil {
+		return nil, err
+	}
+
+	args := []string{"--" + consts.ServiceType, sa.Type, "--" + consts.ServerName, sa.ServerName}
+	args = appendFlag(args, consts.IDLPath, sa.IdlPath)
+	args = appendFlag(args, consts.Module, sa.GoMod)
+	args = appendFlag(args, consts.Registry, sa.Registry)
+	args = appendFlag(args, consts.ConfigCenter, sa.ConfigCenter)
+	args = appendSlice(args, consts.Observability, sa.Observability)
+	args = appendFlag(args, consts.Template, sa.Template)
+	args = appendSlice(args, consts.ProtoSearchPath, sa.SliceParam.ProtoSearchPath)
+	return appendSlice(args, consts.Pass, sa.SliceParam.Pass), nil
+}
+
+func (w *wizard) client() ([]string, error) {
+	ca := config.NewClientArgument()
+	ca.Type = consts.RPC
+	validate := func(set func(ca *config.ClientArgument, ans string)) survey.Validator {
+		return func(ans interface{}) error {
+			cp := ca.Clone()
+			set(cp, answer(ans))
+			return client.Check(cp)
+		}
+	}
+
+	if err := w.ask(&survey.Input{Message: "Name of the server to call:"}, &ca.ServerName, validate(func(ca *config.ClientArgument, ans string) {
+		ca.ServerName = ans
+	})); err != nil {
+		return nil, err
+	}
+	if err := w.ask(&survey.Select{Message: "Service type:", Options: []string{consts.RPC, consts.HTTP}}, &ca.Type, validate(func(ca *config.ClientArgument, ans string) {
+		ca.Type = ans
+	})); err != nil {
+		return nil, err
+	}
+	if err := w.idl(&ca.IdlPath, ca.SliceParam, false); err != nil {
+		return nil, err
+	}
+	registry, err := w.registry(validate(func(ca *config.ClientArgument, ans string) {
+		ca.Registry = noneValue(ans)
+	}))
+	if err != nil {
+		return nil, err
+	}
+	ca.Registry = registry
+	if ca.Observability, err = w.observability(observability.ClientBackends); err != nil {
+		return nil, err
+	}
+	if ca.Template, err = w.template(); err != nil {
+		return nil, err
+	}
+	if ca.GoMod, err = w.module(validate(func(ca *config.ClientArgument, ans string) {
+		ca.GoMod = ans
+	})); err != nil {
+		return nil, err
+	}
+
+	args := []string{"--" + consts.ServiceType, ca.Type, "--" + consts.ServerName, ca.ServerName, "--" + consts.IDLPath, ca.IdlPath}
+	args = appendFlag(args, consts.Module, ca.GoMod)
+	args = appendFlag(args, consts.Registry, ca.Registry)
+	args = appendSlice(args, consts.Observability, ca.Observability)
+	args = appendFlag(args, consts.Template, ca.Template)
+	args = appendSlice(args, consts.ProtoSearchPath, ca.SliceParam.ProtoSearchPath)
+	return appendSlice(args, consts.Pass, ca.SliceParam.Pass), nil
+}
+
+func (w *wizard) model() ([]string, string, error) {
+	ma := config.NewModelArgument()
+	validate := func(set func(ma *config.ModelArgument, ans string)) survey.Validator {
+		return func(ans interface{}) error {
+			cp := *ma
+			set(&cp, answer(ans))
+			return model.Check(&cp)
+		}
+	}
+
+	var dbTypes []string
+	for t := range config.OpenTypeFuncMap {
+		dbTypes = append(dbTypes, string(t))
+	}
+	sort.Strings(dbTypes)
+	if err := w.ask(&survey.Select{Message: "Database type:", Options: dbTypes, Default: string(consts.MySQL)}, &ma.Type, nil); err != nil {
+		return nil, "", err
+	}
+
+	var source string
+	if err := w.ask(&survey.Select{Message: "Generate from:", Options: []string{dsnSource, sqlFilesSource}}, &source, nil); err != nil {
+		return nil, "", err
+	}
+	if source == dsnSource {
+		err := w.ask(&survey.Password{Message: "DSN:", Help: "https://gorm.io/docs/connecting_to_the_database.html"}, &ma.DSN, validate(func(ma *config.ModelArgument, ans string) {
+			ma.DSN = ans
+		}))
+		if err != nil {
+			return nil, "", err
+		}
+	} else {
+		err := w.ask(&survey.Input{Message: "SQL file or directory:", Suggest: suggestFiles(".sql")}, &ma.SQLDir, validate(func(ma *config.ModelArgument, ans string) {
+			ma.SQLDir = ans
+		}))
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	if err := w.ask(&survey.Input{Message: "Output directory:", Default: ma.OutPath}, &ma.OutPath, nil); err != nil {
+		return nil, "", err
+	}
+	if err := w.ask(&survey.Confirm{Message: "Only generate the models, without the query code?"}, &ma.OnlyModel, nil); err != nil {
+		return nil, "", err
+	}
+
+	args := []string{"--" + consts.DBType, ma.Type}
+	args = appendFlag(args, consts.SQLDir, ma.SQLDir)
+	if ma.OutPath != consts.DefaultDbOutDir {
+		args = appendFlag(args, consts.OutDir, ma.OutPath)
+	}
+	if ma.OnlyModel {
+		args = append(args, "--"+consts.OnlyModel)
+	}
+	return args, ma.DSN, nil
+}
+
+func (w *wizard) doc() ([]string, error) {
+	da := config.NewDocArgument()
+	if err := w.ask(&survey.Select{Message: "Document database:", Options: []string{consts.MongoDb}}, &da.Name, nil); err != nil {
+		return nil, err
+	}
+	if err := w.ask(&survey.Input{Message: "IDL file:", Suggest: suggestFiles(".thrift", ".proto")}, &da.IdlPath, validateIDL); err != nil {
+		return nil, err
+	}
+	module, err := w.module(func(ans interface{}) error {
+		cp := *da
+		cp.GoMod = answer(ans)
+		return doc.Check(&cp)
+	})
+	if err != nil {
+		return nil, err
+	}
+	da.GoMod = module
+
+	args := []string{"--" + consts.Name, da.Name, "--" + consts.IDLPath, da.IdlPath}
+	return appendFlag(args, consts.Module, da.GoMod), nil
+}
+
+func (w *wizard) job() ([]string, error) {
+	ja := config.NewJobArgument()
+	var names string
+	if err := w.ask(&survey.Input{Message: "Job names (space separated):"}, &names, survey.Required); err != nil {
+		return nil, err
+	}
+	ja.JobName = strings.Fields(names)
+	var err error
+	if ja.GoMod, err = w.module(func(ans interface{}) error {
+		cp := *ja
+		cp.GoMod = answer(ans)
+		return job.Check(&cp)
+	}); err != nil {
+		return nil, err
+	}
+
+	var args []string
+	for _, name := range ja.JobName {
+		args = append(args, "--"+consts.JobName, name)
+	}
+	return appendFlag(args, consts.Module, ja.GoMod), nil
+}
+
+// idl asks for the IDL file, and for the include paths of protobuf IDL.
+// An optional IDL may be left empty.
+func (w *wizard) idl(path *string, sp *config.SliceParam, optional bool) error {
+	message, validate := "IDL file:", validateIDL
+	if optional {
+		message = "IDL file (optional):"
+		validate = func(ans interface{}) error {
+			if answer(ans) == "" {
+				return nil
+			}
+			return validateIDL(ans)
+		}
+	}
+	if err := w.ask(&survey.Input{Message: message, Suggest: suggestFiles(".thrift", ".proto")}, path, validate); err != nil {
+		return err
+	}
+	qs := []*survey.Question{{
+		Name:   consts.Pass,
+		Prompt: &survey.Input{Message: "Extra parameters passed to kitex or hz (space separated, optional):"},
+	}}
+	if idlType, _ := utils.GetIdlType(*path); idlType == consts.Proto {
+		qs = append([]*survey.Question{{
+			Name:   consts.ProtoSearchPath,
+			Prompt: &survey.Input{Message: "IDL search paths for includes (space separated, optional):"},
+		}}, qs...)
+	}
+	return survey.Ask(qs, sp, w.opts...)
+}
+
+func (w *wizard) registry(v survey.Validator) (string, error) {
+	var registry string
+	err := w.ask(&survey.Select{Message: "Registry:", Options: append([]string{none}, kx_registry.Registries...)}, &registry, v)
+	return noneValue(registry), err
+}
+
+func (w *wizard) configCenter(v survey.Validator) (string, error) {
+	var center string
+	err := w.ask(&survey.Select{Message: "Config center:", Options: append([]string{none}, config_center.Centers...)}, &center, v)
+	return noneValue(center), err
+}
+
+func (w *wizard) observability(backends []string) ([]string, error) {
+	var selected []string
+	err := w.ask(&survey.MultiSelect{Message: "Observability (optional):", Options: backends}, &selected, nil)
+	return selected, err
+}
+
+func (w *wizard) template() (string, error) {
+	var choice string
+	if err := w.ask(&survey.Select{Message: "Template:", Options: []string{defaultTpl, customTpl}}, &choice, nil); err != nil {
+		return "", err
+	}
+	if choice == defaultTpl {
+		return "", nil
+	}
+	var template string
+	err := w.ask(&survey.Input{Message: "Template path or git URL:", Suggest: suggestFiles()}, &template, survey.Required)
+	return template, err
+}
+
+func (w *wizard) module(v survey.Validator) (string, error) {
+	module := defaultModule()
+	err := w.ask(&survey.Input{Message: "Go module:", Default: module}, &module, v)
+	return module, err
+}
+
+// defaultModule is the module of the go.mod the output belongs to.
+func defaultModule() string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	if module, _, ok := utils.SearchGoMod(cwd, true); ok {
+		return module
+	}
+	return ""
+}
+
+func validateIDL(ans interface{}) error {
+	path := answer(ans)
+	if _, err := utils.GetIdlType(path); err != nil {
+		return err
+	}
+	if isExist, _ := utils.PathExist(path); !isExist {
+		return fmt.Errorf("idl %s not found", path)
+	}
+	return nil
+}
+
+// suggestFiles completes paths, only directories and files with one of the extensions are suggested.
+func suggestFiles(exts ...string) func(string) []string {
+	return func(toComplete string) []string {
+		matches, _ := filepath.Glob(toComplete + "*")
+		var files []string
+		for _, m := range matches {
+			if info, err := os.Stat(m); err == nil && info.IsDir() {
+				files = append(files, m+string(filepath.Separator))
+				continue
+			}
+			if len(exts) == 0 {
+				files = append(files, m)
+			}
+			for _, ext := range exts {
+				if filepath.Ext(m) == ext {
+					files = append(files, m)
+				}
+			}
+		}
+		return files
+	}
+}
+
+func answer(ans interface{}) string {
+	switch ans := ans.(type) {
+	case core.OptionAnswer:
+		return ans.Value
+	case string:
+		return ans
+	}
+	return ""
+}
+
+// noneValue maps the none option of a select to an empty value.
+func noneValue(ans string) string {
+	if ans == none {
+		return ""
+	}
+	return ans
+}
+
+func appendFlag(args []string, name, value string) []string {
+	if value == "" {
+		return args
+	}
+	return append(args, "--"+name, value)
+}
+
+func appendSlice(args []string, name string, values []string) []string {
+	for _, v := range values {
+		args = append(args, "--"+name, v)
+	}
+	return args
+}
+
+var safeArg = regexp.MustCompile(`^[\w@%+=:,./-]+$`)
+
+// CommandLine quotes the arguments for a POSIX shell.
+func CommandLine(args []string) string {
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		if safeArg.MatchString(arg) {
+			quoted = append(quoted, arg)
+		} else {
+			quoted = append(quoted, "'"+strings.ReplaceAll(arg, "'", `'\''`)+"'")
+		}
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,153 @@
+//go:build !windows
+
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package wizard
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AlecAivazis/survey/v2"
+	expect "github.com/Netflix/go-expect"
+	pseudotty "github.com/creack/pty"
+	"github.com/hinshun/vt10x"
+	"github.com/stretchr/testify/assert"
+)
+
+// prompt is an expected output of the wizard and the keys answering it.
+type prompt struct {
+	expect string
+	send   string
+}
+
+const (
+	enter     = "\r"
+	down      = "\x1b[B"
+	interrupt = "\x03"
+)
+
+// runScript runs the wizard on a pseudo terminal in dir and answers the prompts in order.
+func runScript(t *testing.T, dir string, prompts []prompt) (*Result, error) {
+	cwd, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.NoError(t, os.Chdir(dir))
+	defer os.Chdir(cwd)
+
+	// survey queries the cursor position, the emulator behind the console answers it
+	pty, tty, err := pseudotty.Open()
+	assert.NoError(t, err)
+	term := vt10x.New(vt10x.WithWriter(tty))
+	c, err := expect.NewConsole(expect.WithStdin(pty), expect.WithStdout(term), expect.WithCloser(pty, tty),
+		expect.WithDefaultTimeout(5*time.Second))
+	assert.NoError(t, err)
+	defer c.Close()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// keeps answering the terminal queries until the wizard is done
+		defer c.ExpectEOF()
+		for _, p := range prompts {
+			if _, err := c.ExpectString(p.expect); err != nil {
+				t.Errorf("expect %q: %s", p.expect, err)
+				c.Send(interrupt)
+				return
+			}
+			if _, err := c.Send(p.send); err != nil {
+				t.Errorf("answer %q: %s", p.expect, err)
+				return
+			}
+		}
+	}()
+	r, err := Run(survey.WithStdio(c.Tty(), c.Tty(), c.Tty()))
+	c.Tty().Close()
+	<-done
+	return r, err
+}
+
+func project(t *testing.T) string {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/demo\n"), 0o644))
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "idl"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "idl", "demo.thrift"), []byte("service Demo {}\n"), 0o644))
+	return dir
+}
+
+func TestRunServer(t *testing.T) {
+	r, err := runScript(t, project(t), []prompt{
+		{"What do you want to generate?", enter},
+		{"Server name:", "demo" + enter},
+		{"Service type:", enter},
+		// the answers are validated and asked again
+		{"IDL file:", "idl/demo.txt" + enter},
+		{"not supported", "\x15idl/missing.thrift" + enter},
+		{"not found", "\x15idl/demo.thrift" + enter},
+		{"Extra parameters", enter},
+		{"Registry:", down + down + down + enter},
+		{"Config center:", enter},
+		{"Observability", enter},
+		{"Template:", enter},
+		{"Go module:", enter},
+		{"Generate now?", "n" + enter},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []string{
+		"server", "--type", "RPC", "--server_name", "demo", "--idl", "idl/demo.thrift",
+		"--module", "example.com/demo", "--registry", "ETCD",
+	}, r.Args)
+	assert.False(t, r.Generate)
+}
+
+func TestRunHTTPServerWithoutIDL(t *testing.T) {
+	r, err := runScript(t, project(t), []prompt{
+		{"What do you want to generate?", enter},
+		{"Server name:", "demo" + enter},
+		{"Service type:", down + enter},
+		{"IDL file (optional):", enter},
+		{"Extra parameters", enter},
+		{"Registry:", enter},
+		{"Config center:", enter},
+		{"Observability", enter},
+		{"Template:", enter},
+		{"Go module:", enter},
+		{"Generate now?", enter},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []string{"server", "--type", "HTTP", "--server_name", "demo", "--module", "example.com/demo"}, r.Args)
+	assert.True(t, r.Generate)
+}
+
+func TestRunJob(t *testing.T) {
+	r, err := runScript(t, project(t), []prompt{
+		{"What do you want to generate?", down + down + down + down + enter},
+		{"Job names", enter},
+		{"required", "email clean" + enter},
+		{"Go module:", enter},
+		{"Generate now?", "y" + enter},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []string{"job", "--job_name", "email", "--job_name", "clean", "--module", "example.com/demo"}, r.Args)
+	assert.True(t, r.Generate)
+}
@@ -0,0 +1,35 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package wizard
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommandLine(t *testing.T) {
+	args := []string{"cwgo", "server", "--type", "RPC", "--idl", "idl/my user.thrift", "--pass", "-use", "--pass", "it's"}
+	assert.Equal(t, `cwgo server --type RPC --idl 'idl/my user.thrift' --pass -use --pass 'it'\''s'`, CommandLine(args))
+}
+
+func TestAppendFlags(t *testing.T) {
+	args := appendFlag(nil, "module", "")
+	args = appendFlag(args, "registry", "NACOS")
+	args = appendSlice(args, "proto_search_path", []string{"a", "b"})
+	assert.Equal(t, []string{"--registry", "NACOS", "--proto_search_path", "a", "--proto_search_path", "b"}, args)
+}
@@ -33,56 +33,117 @@ var kitexTpl embed.FS
 //go:embed hertz
 var hertzTpl embed.FS
 
+// KitexDir and HertzDir point to the template root of the current process, they are set by Init.
 var (
-	KitexDir = path.Join(os.TempDir(), consts.Kitex)
-	HertzDir = path.Join(os.TempDir(), consts.Hertz)
+	KitexDir string
+	HertzDir string
+
+	root *Root
 )
 
-func Init() {
-	os.RemoveAll(KitexDir)
-	os.RemoveAll(HertzDir)
-	os.Mkdir(KitexDir, 0o755)
-	os.Mkdir(HertzDir, 0o755)
-	initDir(kitexTpl, consts.Kitex, KitexDir)
-	initDir(hertzTpl, consts.Hertz, HertzDir)
+// Root is a private copy of the embedded templates, every cwgo process (or library call)
+// renders from its own root, so parallel runs never overwrite the templates of each other.
+type Root struct {
+	Dir      string
+	KitexDir string
+	HertzDir string
+}
+
+// NewRoot extracts the embedded templates into a new private directory.
+func NewRoot() (*Root, error) {
+	dir, err := os.MkdirTemp("", "cwgo-tpl-")
+	if err != nil {
+		return nil, err
+	}
+	r := &Root{
+		Dir:      dir,
+		KitexDir: path.Join(dir, consts.Kitex),
+		HertzDir: path.Join(dir, consts.Hertz),
+	}
+	if err = initDir(kitexTpl, consts.Kitex, r.KitexDir); err != nil {
+		r.Close()
+		return nil, err
+	}
+	if err = initDir(hertzTpl, consts.Hertz, r.HertzDir); err != nil {
+		r.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// Close removes the root and everything generated in it, such as cloned git templates.
+func (r *Root) Close() error {
+	return os.RemoveAll(r.Dir)
+}
+
+// Init prepares the template root of the current process, Cleanup must be called before exit.
+func Init() error {
+	r, err := NewRoot()
+	if err != nil {
+		return err
+	}
+	root = r
+	KitexDir = r.KitexDir
+	HertzDir = r.HertzDir
+	return nil
 }
 
-func initDir(fs embed.FS, srcDir, dstDir string) {
+// Cleanup removes the template root created by Init.
+func Cleanup() {
+	if root != nil {
+		root.Close()
+	}
+}
+
+func initDir(fs embed.FS, srcDir, dstDir string) error {
+	if err := os.MkdirAll(dstDir, 0o755); err != nil {
+		return err
+	}
 	files, err := fs.ReadDir(srcDir)
 	if err != nil {
-		panic(err)
+		return err
 	}
 	for _, f := range files {
-
 		newDstPath := path.Join(dstDir, f.Name())
 		newSrcPath := path.Join(srcDir, f.Name())
 
 		if f.IsDir() {
-			os.Mkdir(newDstPath, 0o755)
-			initDir(fs, newSrcPath, newDstPath)
+			if err = initDir(fs, newSrcPath, newDstPath); err != nil {
+				return err
+			}
 			continue
 		}
 
 		content, err := fs.ReadFile(newSrcPath)
 		if err != nil {
-			panic(err)
+			return err
 		}
-		file, err := os.OpenFile(newDstPath, os.O_WRONLY|os.O_TRUNC|os.O_CREATE, 0o666)
-		if err != nil {
-			panic(err)
+		if err = os.WriteFile(newDstPath, content, 0o666); err != nil {
+			return err
 		}
-		file.Write(content)
-		file.Close()
 	}
+	return nil
 }
 
 func RegisterTemplateFunc() {
-	for k, f := range sprig.FuncMap() {
+	for k, f := range KitexFuncs() {
 		generator.AddTemplateFunc(k, f)
 	}
-	generator.AddTemplateFunc("ToCamel", func(name string) string {
+}
+
+// KitexFuncs returns the functions added to the kitex templates by cwgo besides the kitex built-in ones.
+func KitexFuncs() map[string]interface{} {
+	funcs := make(map[string]interface{})
+	for k, f := range sprig.FuncMap() {
+		funcs[k] = f
+	}
+	funcs["ToCamel"] = func(name string) string {
 		name = strings.Replace(name, "_", " ", -1)
 		name = strings.Title(name)
 		return strings.Replace(name, " ", "", -1)
-	})
+	}
+	for k, f := range PluginVarFuncs() {
+		funcs[k] = f
+	}
+	return funcs
 }
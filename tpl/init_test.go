@@ -0,0 +1,99 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tpl
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"sync"
+	"testing"
+
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRootConcurrently(t *testing.T) {
+	const n = 8
+	roots := make([]*Root, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			r, err := NewRoot()
+			assert.NoError(t, err)
+			roots[i] = r
+		}(i)
+	}
+	wg.Wait()
+
+	dirs := make(map[string]bool)
+	for _, r := range roots {
+		assert.False(t, dirs[r.Dir], "template root %s is shared", r.Dir)
+		dirs[r.Dir] = true
+	}
+
+	// closing half of the roots while the others are read must not affect them
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i%2 == 0 {
+				assert.NoError(t, roots[i].Close())
+				return
+			}
+			checkRoot(t, roots[i])
+		}(i)
+	}
+	wg.Wait()
+
+	for i, r := range roots {
+		_, err := os.Stat(r.Dir)
+		if i%2 == 0 {
+			assert.True(t, os.IsNotExist(err))
+			continue
+		}
+		assert.NoError(t, err)
+		assert.NoError(t, r.Close())
+	}
+}
+
+func checkRoot(t *testing.T, r *Root) {
+	for src, dst := range map[string]string{consts.Kitex: r.KitexDir, consts.Hertz: r.HertzDir} {
+		embedded := kitexTpl
+		if src == consts.Hertz {
+			embedded = hertzTpl
+		}
+		err := fs.WalkDir(embedded, src, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			expected, err := embedded.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			actual, err := os.ReadFile(path.Join(dst, p[len(src):]))
+			if err != nil {
+				return err
+			}
+			assert.Equal(t, string(expected), string(actual), p)
+			return nil
+		})
+		assert.NoError(t, err)
+	}
+}
@@ -0,0 +1,302 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package tpl
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/cloudwego/cwgo/pkg/consts"
+)
+
+// OverlayDir is the overlay of a project, it is looked up from the current directory to the project root.
+const OverlayDir = ".cwgo/templates"
+
+// layoutEntry matches the first line of a file of the hertz layouts, e.g. "  - path: main.go".
+var layoutEntry = regexp.MustCompile(`^(\s*)- path:\s*(.*?)\s*$`)
+
+// FindOverlay looks for the overlay directory from dir up to the project root, which is the first
+// directory holding go.mod or .git, the overlays out of the project are never used.
+func FindOverlay(dir string) (string, bool) {
+	for {
+		p := filepath.Join(dir, filepath.FromSlash(OverlayDir))
+		if info, err := os.Stat(p); err == nil && info.IsDir() {
+			return p, true
+		}
+		if isProjectRoot(dir) {
+			return "", false
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+func isProjectRoot(dir string) bool {
+	for _, name := range []string{consts.GoMod, ".git"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyOverlay overlays the templates of dir on a new template root and points KitexDir and HertzDir
+// to it until restore is called, so the overlay of a generation never leaks into the next ones.
+// The overlay of the project found by FindOverlay is used when dir is empty, restore is a no-op
+// when there is no overlay.
+func ApplyOverlay(dir string) (restore func(), err error) {
+	restore = func() {}
+	if dir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return restore, err
+		}
+		var ok bool
+		if dir, ok = FindOverlay(cwd); !ok {
+			return restore, nil
+		}
+	}
+	r, err := NewRoot()
+	if err != nil {
+		return restore, err
+	}
+	if err = r.Overlay(dir); err != nil {
+		r.Close()
+		return restore, err
+	}
+	kitexDir, hertzDir := KitexDir, HertzDir
+	KitexDir, HertzDir = r.KitexDir, r.HertzDir
+	return func() {
+		KitexDir, HertzDir = kitexDir, hertzDir
+		r.Close()
+	}, nil
+}
+
+// Overlay overlays the templates of dir on the root.
+//
+// The overlay mirrors the embedded templates, e.g. kitex/server/standard/main_tpl.yaml replaces
+// the main.go template of the kitex standard set, and any other file is added to the set.
+// The files of the hertz layout.yaml and package.yaml are merged by their path instead,
+// only the listed files are replaced or added.
+func (r *Root) Overlay(dir string) error {
+	return overlay(dir, r.KitexDir, r.HertzDir)
+}
+
+func overlay(dir, kitexDir, hertzDir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("read template overlay failed: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("template overlay %s is not a directory", dir)
+	}
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		set, name, _ := strings.Cut(filepath.ToSlash(rel), consts.Slash)
+		var dst string
+		switch set {
+		case consts.Kitex:
+			dst = filepath.Join(kitexDir, filepath.FromSlash(name))
+		case consts.Hertz:
+			dst = filepath.Join(hertzDir, filepath.FromSlash(name))
+		default:
+			return fmt.Errorf("template overlay %s is neither under %s/ nor %s/", p, consts.Kitex, consts.Hertz)
+		}
+
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		if set == consts.Hertz && (d.Name() == consts.LayoutFile || d.Name() == consts.PackageLayoutFile) {
+			if base, err := os.ReadFile(dst); err == nil {
+				if content, err = mergeLayouts(base, content); err != nil {
+					return fmt.Errorf("merge template overlay %s failed: %w", p, err)
+				}
+			}
+		}
+		if err = os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			return err
+		}
+		return os.WriteFile(dst, content, 0o666)
+	})
+}
+
+// layouts is a hertz layout file split into the lines before its files and the files keyed by path.
+type layouts struct {
+	head   []string
+	indent string
+	paths  []string
+	files  map[string][]string
+}
+
+func splitLayouts(content []byte) (*layouts, error) {
+	l := &layouts{files: map[string][]string{}}
+	lines := strings.SplitAfter(string(content), consts.LineBreak)
+	var cur string
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		m := layoutEntry.FindStringSubmatch(strings.TrimRight(line, "\r\n"))
+		if m != nil && (len(l.paths) == 0 || m[1] == l.indent) {
+			l.indent = m[1]
+			cur = strings.Trim(m[2], `"'`)
+			if _, ok := l.files[cur]; !ok {
+				l.paths = append(l.paths, cur)
+			}
+			l.files[cur] = nil
+		}
+		if len(l.paths) == 0 {
+			l.head = append(l.head, line)
+			continue
+		}
+		l.files[cur] = append(l.files[cur], line)
+	}
+	if len(l.paths) == 0 {
+		return nil, fmt.Errorf("no file is found in the layouts")
+	}
+	return l, nil
+}
+
+// mergeLayouts replaces the files of base by the files of the same path in overlay and adds the others.
+// The lines before the files of overlay, such as the [[ ]] variables, are added to base if missing.
+func mergeLayouts(base, overlay []byte) ([]byte, error) {
+	b, err := splitLayouts(base)
+	if err != nil {
+		return nil, err
+	}
+	o, err := splitLayouts(overlay)
+	if err != nil {
+		return nil, err
+	}
+
+	head := make(map[string]bool, len(b.head))
+	for _, line := range b.head {
+		head[strings.TrimSpace(line)] = true
+	}
+	var buf bytes.Buffer
+	for _, line := range o.head {
+		if !head[strings.TrimSpace(line)] {
+			buf.WriteString(line)
+		}
+	}
+	for _, line := range b.head {
+		buf.WriteString(line)
+	}
+
+	for _, p := range o.paths {
+		if _, ok := b.files[p]; !ok {
+			b.paths = append(b.paths, p)
+		}
+		b.files[p] = reindent(o.files[p], o.indent, b.indent)
+	}
+	for _, p := range b.paths {
+		lines := b.files[p]
+		for _, line := range lines {
+			buf.WriteString(line)
+		}
+		if last := lines[len(lines)-1]; !strings.HasSuffix(last, consts.LineBreak) {
+			buf.WriteString(consts.LineBreak)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// reindent moves the lines of a file from the indent of its layouts to another one.
+func reindent(lines []string, from, to string) []string {
+	if from == to {
+		return lines
+	}
+	res := make([]string, len(lines))
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "" && strings.HasPrefix(line, from) {
+			line = to + line[len(from):]
+		}
+		res[i] = line
+	}
+	return res
+}
+
+// Export writes the embedded templates into dir as a starting point of an overlay or a template,
+// only the ones under the given paths are written if any, e.g. kitex/server/standard.
+// The existing files are skipped, the paths of the written files are returned.
+func Export(dir string, paths ...string) (written []string, err error) {
+	found := make([]bool, len(paths))
+	for _, set := range []struct {
+		fs   embed.FS
+		name string
+	}{{kitexTpl, consts.Kitex}, {hertzTpl, consts.Hertz}} {
+		err = fs.WalkDir(set.fs, set.name, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			if len(paths) > 0 {
+				matched := false
+				for i, prefix := range paths {
+					prefix = strings.Trim(path.Clean(filepath.ToSlash(prefix)), consts.Slash)
+					if p == prefix || strings.HasPrefix(p, prefix+consts.Slash) {
+						matched, found[i] = true, true
+					}
+				}
+				if !matched {
+					return nil
+				}
+			}
+
+			dst := filepath.Join(dir, filepath.FromSlash(p))
+			if _, err = os.Stat(dst); err == nil {
+				return nil
+			}
+			content, err := set.fs.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			if err = os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+				return err
+			}
+			if err = os.WriteFile(dst, content, 0o644); err != nil {
+				return err
+			}
+			written = append(written, dst)
+			return nil
+		})
+		if err != nil {
+			return written, err
+		}
+	}
+	for i, ok := range found {
+		if !ok {
+			return written, fmt.Errorf("no embedded template is found under %s", paths[i])
+		}
+	}
+	return written, nil
+}
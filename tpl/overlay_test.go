@@ -0,0 +1,192 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package tpl
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/stretchr/testify/assert"
+)
+
+const baseLayouts = `[[- $file := eq .ConfigCenter "file"]]
+layouts:
+  - path: main.go
+    delims:
+      - ""
+      - ""
+    body: |-
+      package main
+  - path: go.mod
+    body: |-
+      module {{.GoModule}}
+`
+
+const overlayLayouts = `[[- $otel := .Otel]]
+layouts:
+- path: "main.go"
+  body: |-
+    package main
+
+    // overlaid
+- path: extra.go
+  body: |-
+    package main
+`
+
+func TestMergeLayouts(t *testing.T) {
+	merged, err := mergeLayouts([]byte(baseLayouts), []byte(overlayLayouts))
+	assert.NoError(t, err)
+	assert.Equal(t, `[[- $otel := .Otel]]
+[[- $file := eq .ConfigCenter "file"]]
+layouts:
+  - path: "main.go"
+    body: |-
+      package main
+
+      // overlaid
+  - path: go.mod
+    body: |-
+      module {{.GoModule}}
+  - path: extra.go
+    body: |-
+      package main
+`, string(merged))
+
+	_, err = mergeLayouts([]byte(baseLayouts), []byte("layouts: []\n"))
+	assert.Error(t, err)
+}
+
+func TestOverlay(t *testing.T) {
+	r, err := NewRoot()
+	assert.NoError(t, err)
+	defer r.Close()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"kitex/server/standard/main_tpl.yaml":  "path: main.go\nbody: overlaid\n",
+		"kitex/server/standard/extra_tpl.yaml": "path: extra.go\nbody: added\n",
+		"hertz/server/standard/layout.yaml":    overlayLayouts,
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		assert.NoError(t, os.WriteFile(p, []byte(content), 0o644))
+	}
+	assert.NoError(t, r.Overlay(dir))
+
+	for name, content := range files {
+		if strings.HasPrefix(name, consts.Kitex) {
+			got, err := os.ReadFile(filepath.Join(r.KitexDir, filepath.FromSlash(strings.TrimPrefix(name, consts.Kitex))))
+			assert.NoError(t, err)
+			assert.Equal(t, content, string(got))
+		}
+	}
+	layout, err := os.ReadFile(filepath.Join(r.HertzDir, consts.Server, consts.Standard, consts.LayoutFile))
+	assert.NoError(t, err)
+	assert.Contains(t, string(layout), "// overlaid")
+	assert.Contains(t, string(layout), "  - path: biz/health/health.go")
+	assert.Contains(t, string(layout), "  - path: extra.go")
+	// the other sets are left untouched
+	conf, err := os.ReadFile(filepath.Join(r.KitexDir, consts.Server, consts.Standard, "conf_tpl.yaml"))
+	assert.NoError(t, err)
+	embedded, err := kitexTpl.ReadFile("kitex/server/standard/conf_tpl.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, embedded, conf)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "main_tpl.yaml"), nil, 0o644))
+	assert.Error(t, r.Overlay(dir))
+	assert.Error(t, r.Overlay(filepath.Join(dir, "missing")))
+}
+
+func TestFindOverlay(t *testing.T) {
+	dir := t.TempDir()
+	_, ok := FindOverlay(dir)
+	assert.False(t, ok)
+
+	overlay := filepath.Join(dir, filepath.FromSlash(OverlayDir))
+	sub := filepath.Join(dir, "a", "b")
+	assert.NoError(t, os.MkdirAll(overlay, 0o755))
+	assert.NoError(t, os.MkdirAll(sub, 0o755))
+	found, ok := FindOverlay(sub)
+	assert.True(t, ok)
+	assert.Equal(t, overlay, found)
+
+	// the walk stops at the project root
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "a", consts.GoMod), []byte("module a\n"), 0o644))
+	_, ok = FindOverlay(sub)
+	assert.False(t, ok)
+}
+
+func TestApplyOverlay(t *testing.T) {
+	assert.NoError(t, Init())
+	defer Cleanup()
+	kitexDir, hertzDir := KitexDir, HertzDir
+
+	dir := t.TempDir()
+	p := filepath.Join(dir, "kitex", "server", "standard", "main_tpl.yaml")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+	assert.NoError(t, os.WriteFile(p, []byte("path: main.go\nbody: overlaid\n"), 0o644))
+
+	restore, err := ApplyOverlay(dir)
+	assert.NoError(t, err)
+	assert.NotEqual(t, kitexDir, KitexDir)
+	got, err := os.ReadFile(filepath.Join(KitexDir, consts.Server, consts.Standard, "main_tpl.yaml"))
+	assert.NoError(t, err)
+	assert.Equal(t, "path: main.go\nbody: overlaid\n", string(got))
+	overlaid := filepath.Dir(KitexDir)
+
+	restore()
+	assert.Equal(t, kitexDir, KitexDir)
+	assert.Equal(t, hertzDir, HertzDir)
+	_, err = os.Stat(overlaid)
+	assert.True(t, os.IsNotExist(err))
+	// the template root of the process is left untouched
+	got, err = os.ReadFile(filepath.Join(KitexDir, consts.Server, consts.Standard, "main_tpl.yaml"))
+	assert.NoError(t, err)
+	embedded, err := kitexTpl.ReadFile("kitex/server/standard/main_tpl.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, embedded, got)
+
+	_, err = ApplyOverlay(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+	assert.Equal(t, kitexDir, KitexDir)
+}
+
+func TestExport(t *testing.T) {
+	dir := t.TempDir()
+	written, err := Export(dir, "kitex/server/standard/main_tpl.yaml", "hertz/client/")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "kitex", "server", "standard", "main_tpl.yaml"),
+		filepath.Join(dir, "hertz", "client", "standard", "package.yaml"),
+	}, written)
+
+	// existing files are kept
+	assert.NoError(t, os.WriteFile(written[0], []byte("custom"), 0o644))
+	written, err = Export(dir)
+	assert.NoError(t, err)
+	assert.NotContains(t, written, filepath.Join(dir, "kitex", "server", "standard", "main_tpl.yaml"))
+	content, err := os.ReadFile(filepath.Join(dir, "kitex", "server", "standard", "main_tpl.yaml"))
+	assert.NoError(t, err)
+	assert.Equal(t, "custom", string(content))
+
+	_, err = Export(dir, "kitex/server/missing")
+	assert.Error(t, err)
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package tpl
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cloudwego/cwgo/pkg/consts"
+)
+
+// CommandEnv returns the environment of a plugin command passing vars to the plugin process,
+// env is the environment of the command and nil stands for the one of cwgo as in exec.Cmd.
+// The variables are scoped to the command, the ones inherited by cwgo are dropped.
+func CommandEnv(env []string, vars map[string]string) ([]string, error) {
+	if env == nil {
+		env = os.Environ()
+	}
+	res := make([]string, 0, len(env)+1)
+	for _, e := range env {
+		if !strings.HasPrefix(e, consts.TemplateVarsEnv+"=") {
+			res = append(res, e)
+		}
+	}
+	if len(vars) == 0 {
+		return res, nil
+	}
+	data, err := json.Marshal(vars)
+	if err != nil {
+		return nil, err
+	}
+	return append(res, consts.TemplateVarsEnv+"="+string(data)), nil
+}
+
+// LoadVars returns the template variables passed to the plugin process by CommandEnv.
+func LoadVars() (map[string]string, error) {
+	vars := make(map[string]string)
+	if data := os.Getenv(consts.TemplateVarsEnv); data != "" {
+		if err := json.Unmarshal([]byte(data), &vars); err != nil {
+			return nil, fmt.Errorf("invalid template variables in %s: %s", consts.TemplateVarsEnv, err)
+		}
+	}
+	return vars, nil
+}
+
+// VarFuncs returns the functions reading the template variables vars. The templates of the job generator
+// call them with {{TplVar "owner"}}, hz templates in the registry directives with [[TplVar "owner"]]
+// as the functions of hz can't be extended. TplVar is empty when the variable is not set,
+// templates give a default with {{TplVar "port" | default "8888"}}.
+func VarFuncs(vars map[string]string) map[string]interface{} {
+	return varFuncs(func() (map[string]string, error) {
+		return vars, nil
+	})
+}
+
+// PluginVarFuncs returns the functions reading the template variables of the plugin process,
+// see LoadVars. Kitex templates and the templates of the doc generator are rendered by plugins.
+func PluginVarFuncs() map[string]interface{} {
+	return varFuncs(LoadVars)
+}
+
+func varFuncs(load func() (map[string]string, error)) map[string]interface{} {
+	return map[string]interface{}{
+		"TplVar": func(key string) (string, error) {
+			vars, err := load()
+			if err != nil {
+				return "", err
+			}
+			return vars[key], nil
+		},
+		"TplVars": load,
+	}
+}
@@ -0,0 +1,61 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tpl
+
+import (
+	"bytes"
+	"testing"
+	"text/template"
+
+	"github.com/cloudwego/cwgo/pkg/consts"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommandEnv(t *testing.T) {
+	t.Setenv(consts.TemplateVarsEnv, `{"owner":"inherited"}`)
+
+	env, err := CommandEnv([]string{"A=1", consts.TemplateVarsEnv + `={"owner":"inherited"}`}, map[string]string{"owner": "team-a"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"A=1", consts.TemplateVarsEnv + `={"owner":"team-a"}`}, env)
+
+	env, err = CommandEnv(nil, nil)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, env)
+	for _, e := range env {
+		assert.NotContains(t, e, consts.TemplateVarsEnv)
+	}
+}
+
+func TestLoadVars(t *testing.T) {
+	t.Setenv(consts.TemplateVarsEnv, `{"owner":"team-a"}`)
+	vars, err := LoadVars()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"owner": "team-a"}, vars)
+
+	t.Setenv(consts.TemplateVarsEnv, `{"owner":`)
+	_, err = LoadVars()
+	assert.Error(t, err)
+	tmpl := template.Must(template.New("t").Funcs(PluginVarFuncs()).Parse(`{{TplVar "owner"}}`))
+	assert.Error(t, tmpl.Execute(new(bytes.Buffer), nil))
+}
+
+func TestVarFuncs(t *testing.T) {
+	buf := new(bytes.Buffer)
+	tmpl := template.Must(template.New("t").Funcs(VarFuncs(map[string]string{"owner": "team-a"})).Parse(`{{TplVar "owner"}} {{TplVar "psm"}} {{len TplVars}}`))
+	assert.NoError(t, tmpl.Execute(buf, nil))
+	assert.Equal(t, "team-a  1", buf.String())
+}